@@ -0,0 +1,308 @@
+// Package admin provides an HTTP API for changing the membership of a
+// rendezvous.Hash at runtime, so routers can be operated without
+// redeploying them.
+//
+// The Handler serves:
+//
+//	GET    /nodes               list nodes with their weights and drain state
+//	POST   /nodes               add a node, from a JSON body such as
+//	                            {"id": "cache-3", "address": "10.0.0.3:6379", "weight": 2}
+//	DELETE /nodes/{id}          remove a node
+//	PUT    /nodes/{id}/weight   set a node's weight, from a body such as {"weight": 2}
+//	POST   /nodes/{id}/drain    drain a node, setting its weight to 0
+//	DELETE /nodes/{id}/drain    undrain a node, restoring its weight
+//
+// Every change is applied with Hash.Apply, attributed to "admin", so it
+// advances the epoch and reaches the audit log like a programmatic change.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// Handler is an http.Handler exposing a Hash's membership.
+type Handler[N any] struct {
+	hash      *rendezvous.Hash[N]
+	newNode   func(rendezvous.ConfigNode) (N, error)
+	authorize func(*http.Request) bool
+	locker    sync.Locker
+	mux       *http.ServeMux
+
+	mu      sync.Mutex
+	drained map[string]float64
+}
+
+// Options configures a Handler.
+type Options[N any] struct {
+	// Authorize reports whether a request may be served. It is required;
+	// see BearerToken.
+	Authorize func(*http.Request) bool
+	// NewNode builds the node to add from a POST /nodes body. It is
+	// required unless N is rendezvous.ConfigNode.
+	NewNode func(rendezvous.ConfigNode) (N, error)
+	// Locker, if set, is held while the Hash is read or modified, for
+	// Hashes shared with other goroutines.
+	Locker sync.Locker
+}
+
+// BearerToken returns an authorizer accepting requests that carry token in
+// an "Authorization: Bearer" header.
+func BearerToken(token string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+	}
+}
+
+// NewHandler returns a Handler for hash. It panics if opts has no
+// Authorize, or no NewNode for a node type other than
+// rendezvous.ConfigNode.
+func NewHandler[N any](hash *rendezvous.Hash[N], opts Options[N]) *Handler[N] {
+	if opts.Authorize == nil {
+		panic("admin: Authorize is required")
+	}
+	if opts.NewNode == nil {
+		if _, ok := any(rendezvous.ConfigNode{}).(N); !ok {
+			panic("admin: NewNode is required")
+		}
+		opts.NewNode = func(node rendezvous.ConfigNode) (N, error) {
+			return any(node).(N), nil
+		}
+	}
+
+	h := &Handler[N]{
+		hash:      hash,
+		newNode:   opts.NewNode,
+		authorize: opts.Authorize,
+		locker:    opts.Locker,
+		mux:       http.NewServeMux(),
+		drained:   make(map[string]float64),
+	}
+	h.mux.HandleFunc("GET /nodes", h.list)
+	h.mux.HandleFunc("POST /nodes", h.add)
+	h.mux.HandleFunc("DELETE /nodes/{id}", h.remove)
+	h.mux.HandleFunc("PUT /nodes/{id}/weight", h.setWeight)
+	h.mux.HandleFunc("POST /nodes/{id}/drain", h.drain)
+	h.mux.HandleFunc("DELETE /nodes/{id}/drain", h.undrain)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler[N]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(r) {
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// nodeStatus is the JSON representation of a node in GET /nodes.
+type nodeStatus struct {
+	ID      string  `json:"id"`
+	Weight  float64 `json:"weight"`
+	Drained bool    `json:"drained,omitempty"`
+}
+
+func (h *Handler[N]) list(w http.ResponseWriter, r *http.Request) {
+	h.lock()
+	defer h.unlock()
+
+	nodes := h.hash.Nodes()
+	statuses := make([]nodeStatus, len(nodes))
+	for i, node := range nodes {
+		id := string(h.hash.Identity(node))
+		weight, _ := h.hash.Weight(node)
+		_, drained := h.drained[id]
+		statuses[i] = nodeStatus{ID: id, Weight: weight, Drained: drained}
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (h *Handler[N]) add(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ID      string            `json:"id"`
+		Address string            `json:"address"`
+		Weight  *float64          `json:"weight"`
+		Zone    string            `json:"zone"`
+		Labels  map[string]string `json:"labels"`
+	}
+	if err := decodeBody(w, r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if body.ID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing id"))
+		return
+	}
+	weight := 1.0
+	if body.Weight != nil {
+		weight = *body.Weight
+	}
+	if err := checkWeight(weight); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	config := rendezvous.NewConfigNode(body.ID, weight, body.Zone)
+	config.Address, config.Labels = body.Address, body.Labels
+	node, err := h.newNode(config)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.lock()
+	defer h.unlock()
+	if _, exists := h.hash.Find(h.hash.Identity(node)); exists {
+		writeError(w, http.StatusConflict, fmt.Errorf("node %q already exists", body.ID))
+		return
+	}
+	h.apply(w, r, rendezvous.Changeset[N]{Add: []N{node}}, http.StatusCreated)
+}
+
+func (h *Handler[N]) remove(w http.ResponseWriter, r *http.Request) {
+	h.lock()
+	defer h.unlock()
+	node, ok := h.node(w, r)
+	if !ok {
+		return
+	}
+	delete(h.drained, r.PathValue("id"))
+	h.apply(w, r, rendezvous.Changeset[N]{Remove: []N{node}}, http.StatusNoContent)
+}
+
+func (h *Handler[N]) setWeight(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Weight *float64 `json:"weight"`
+	}
+	if err := decodeBody(w, r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if body.Weight == nil {
+		writeError(w, http.StatusBadRequest, errors.New("missing weight"))
+		return
+	}
+	if err := checkWeight(*body.Weight); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	h.lock()
+	defer h.unlock()
+	node, ok := h.node(w, r)
+	if !ok {
+		return
+	}
+	delete(h.drained, r.PathValue("id"))
+	h.apply(w, r, weightChange(node, *body.Weight), http.StatusNoContent)
+}
+
+func (h *Handler[N]) drain(w http.ResponseWriter, r *http.Request) {
+	h.lock()
+	defer h.unlock()
+	node, ok := h.node(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	if _, drained := h.drained[id]; drained {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	weight, _ := h.hash.Weight(node)
+	h.drained[id] = weight
+	h.apply(w, r, weightChange(node, 0), http.StatusNoContent)
+}
+
+func (h *Handler[N]) undrain(w http.ResponseWriter, r *http.Request) {
+	h.lock()
+	defer h.unlock()
+	node, ok := h.node(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	weight, drained := h.drained[id]
+	if !drained {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	delete(h.drained, id)
+	h.apply(w, r, weightChange(node, weight), http.StatusNoContent)
+}
+
+// maxBodyBytes bounds the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+// decodeBody decodes the request's JSON body into v, reading no more than
+// maxBodyBytes.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	return json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(v)
+}
+
+// checkWeight returns an error if weight is not a finite number no less
+// than 0.
+func checkWeight(weight float64) error {
+	if !(weight >= 0) || math.IsInf(weight, 1) {
+		return errors.New("weight must be a finite number no less than 0")
+	}
+	return nil
+}
+
+// node returns the node named by the request's id, writing a 404 if there
+// is none.
+func (h *Handler[N]) node(w http.ResponseWriter, r *http.Request) (N, bool) {
+	node, ok := h.hash.Find([]byte(r.PathValue("id")))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("node %q not found", r.PathValue("id")))
+	}
+	return node, ok
+}
+
+// apply applies changes to the Hash, attributed to the admin API, and
+// writes status on success.
+func (h *Handler[N]) apply(w http.ResponseWriter, r *http.Request, changes rendezvous.Changeset[N], status int) {
+	changes.Actor = "admin"
+	if err := h.hash.Apply(changes); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(status)
+}
+
+func (h *Handler[N]) lock() {
+	h.mu.Lock()
+	if h.locker != nil {
+		h.locker.Lock()
+	}
+}
+
+func (h *Handler[N]) unlock() {
+	if h.locker != nil {
+		h.locker.Unlock()
+	}
+	h.mu.Unlock()
+}
+
+func weightChange[N any](node N, weight float64) rendezvous.Changeset[N] {
+	return rendezvous.Changeset[N]{Weights: []rendezvous.WeightChange[N]{{Node: node, Weight: weight}}}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
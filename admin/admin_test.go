@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+func TestHandler(t *testing.T) {
+	log := rendezvous.NewMemoryAuditLog(0)
+	hash := rendezvous.NewWithOptions([]rendezvous.ConfigNode{rendezvous.NewConfigNode("a", 2, "")}, rendezvous.WithAuditLog(log))
+	handler := NewHandler(hash, Options[rendezvous.ConfigNode]{Authorize: BearerToken("secret")})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	weight := func(id string) float64 {
+		weight, _ := hash.Weight(rendezvous.NewConfigNode(id, 0, ""))
+		return weight
+	}
+
+	unauthorized := httptest.NewRecorder()
+	handler.ServeHTTP(unauthorized, httptest.NewRequest("GET", "/nodes", nil))
+	if unauthorized.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, expected 401 without a token", unauthorized.Code)
+	}
+
+	if w := do("POST", "/nodes", `{"id": "b", "address": "10.0.0.2:80", "weight": 3}`); w.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	if node, _ := hash.Find([]byte("b")); node.Address != "10.0.0.2:80" || weight("b") != 3 {
+		t.Errorf("got node %+v, expected b at 10.0.0.2:80 with weight 3", node)
+	}
+	if w := do("POST", "/nodes", `{"id": "b"}`); w.Code != http.StatusConflict {
+		t.Errorf("got status %d, expected 409 for a duplicate node", w.Code)
+	}
+	for _, body := range []string{`{"id": "c", "weight": -1}`, `{"id": "c", "labels": {"x": "` + strings.Repeat("x", maxBodyBytes) + `"}}`} {
+		if w := do("POST", "/nodes", body); w.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, expected 400 for a negative weight or an oversized body", w.Code)
+		}
+	}
+	for _, body := range []string{`{}`, `{"weight": -1}`, `{"weight": ` + strings.Repeat("1", maxBodyBytes) + `}`} {
+		if w := do("PUT", "/nodes/a/weight", body); w.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, expected 400 for a missing or negative weight or an oversized body", w.Code)
+		}
+	}
+
+	if w := do("PUT", "/nodes/a/weight", `{"weight": 5}`); w.Code != http.StatusNoContent || weight("a") != 5 {
+		t.Errorf("got status %d and weight %v, expected a weight of 5", w.Code, weight("a"))
+	}
+	if w := do("POST", "/nodes/a/drain", ""); w.Code != http.StatusNoContent || weight("a") != 0 {
+		t.Errorf("got status %d and weight %v, expected a drained node", w.Code, weight("a"))
+	}
+
+	var statuses []nodeStatus
+	json.NewDecoder(do("GET", "/nodes", "").Body).Decode(&statuses)
+	if len(statuses) != 2 || !statuses[0].Drained || statuses[1].Drained {
+		t.Errorf("got %+v, expected a drained and b not", statuses)
+	}
+
+	if w := do("DELETE", "/nodes/a/drain", ""); w.Code != http.StatusNoContent || weight("a") != 5 {
+		t.Errorf("got status %d and weight %v, expected the weight restored to 5", w.Code, weight("a"))
+	}
+	if w := do("DELETE", "/nodes/b", ""); w.Code != http.StatusNoContent || len(hash.Nodes()) != 1 {
+		t.Errorf("got status %d, expected b removed", w.Code)
+	}
+	if w := do("DELETE", "/nodes/z", ""); w.Code != http.StatusNotFound {
+		t.Errorf("got status %d, expected 404 for a missing node", w.Code)
+	}
+
+	history := log.History()
+	if len(history) != 6 || history[len(history)-1].Actor != "admin" {
+		t.Errorf("got %d changes, expected every admin change in the audit log", len(history))
+	}
+}
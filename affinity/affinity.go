@@ -0,0 +1,127 @@
+// Package affinity provides HTTP middleware for sticky sessions routed by a
+// rendezvous.Hash, without a load balancer that supports them.
+//
+// Each session carries an affinity cookie holding a random session key and
+// the identity of the backend the session is pinned to. New sessions are
+// pinned to the backend the Hash places their key on, and stay on it while
+// it remains in the Hash, even once membership changes would place the key
+// elsewhere, so adding a backend does not move existing sessions. Draining a
+// backend by setting its weight to zero keeps its sessions on it while new
+// sessions go elsewhere. When a backend is removed, its sessions are
+// re-pinned to wherever the Hash now places their keys, and the cookie is
+// updated.
+package affinity
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// DefaultCookieName is the name of the affinity cookie if Options.Cookie
+// has none.
+const DefaultCookieName = "rendezvous-affinity"
+
+// Options configures the middleware.
+type Options[N any] struct {
+	// Cookie is the template of the affinity cookies issued; its Value is
+	// ignored. If it has no Name, it defaults to an HttpOnly, SameSite=Lax
+	// cookie named DefaultCookieName with a Path of "/".
+	Cookie http.Cookie
+	// NewKey returns the key of a new session. It defaults to 16 random
+	// bytes in hex. Keys must not contain a '.'.
+	NewKey func() string
+	// OnRepin, if set, is called when the backend a session was pinned to
+	// has left the Hash, with the session's key and its new backend.
+	OnRepin func(key string, node N)
+	// Locker, if set, is held while the Hash is read, for Hashes shared with
+	// other goroutines.
+	Locker sync.Locker
+}
+
+type contextKey struct{}
+
+// Node returns the backend the middleware routed the request with ctx to.
+func Node[N any](ctx context.Context) (N, bool) {
+	node, ok := ctx.Value(contextKey{}).(N)
+	return node, ok
+}
+
+// Middleware returns middleware routing each request to a backend of hash
+// by its affinity cookie, issuing or updating the cookie as needed. The
+// backend is available to the next handler from Node. Requests are answered
+// with 503 Service Unavailable if the Hash is empty.
+func Middleware[N any](hash *rendezvous.Hash[N], opts Options[N]) func(http.Handler) http.Handler {
+	if opts.Cookie.Name == "" {
+		opts.Cookie = http.Cookie{Name: DefaultCookieName, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+	}
+	if opts.NewKey == nil {
+		opts.NewKey = func() string {
+			var key [16]byte
+			rand.Read(key[:])
+			return hex.EncodeToString(key[:])
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, pinned, ok := parseCookie(r, opts.Cookie.Name)
+			if !ok {
+				key, pinned = opts.NewKey(), nil
+			}
+
+			if opts.Locker != nil {
+				opts.Locker.Lock()
+			}
+			node, found := hash.Find(pinned)
+			repinned := false
+			if !found {
+				node, found = hash.Get(key)
+				repinned = found && pinned != nil
+				pinned = hash.Identity(node)
+			}
+			if opts.Locker != nil {
+				opts.Locker.Unlock()
+			}
+
+			if !found {
+				http.Error(w, "no backend available", http.StatusServiceUnavailable)
+				return
+			}
+			if repinned && opts.OnRepin != nil {
+				opts.OnRepin(key, node)
+			}
+			if !ok || repinned {
+				cookie := opts.Cookie
+				cookie.Value = key + "." + base64.RawURLEncoding.EncodeToString(pinned)
+				http.SetCookie(w, &cookie)
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, node)))
+		})
+	}
+}
+
+// parseCookie returns the session key and pinned backend identity of the
+// request's affinity cookie named name, or false if it has none or it is
+// malformed.
+func parseCookie(r *http.Request, name string) (key string, pinned []byte, ok bool) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", nil, false
+	}
+	i := strings.LastIndexByte(cookie.Value, '.')
+	if i <= 0 {
+		return "", nil, false
+	}
+	pinned, err = base64.RawURLEncoding.DecodeString(cookie.Value[i+1:])
+	if err != nil {
+		return "", nil, false
+	}
+	return cookie.Value[:i], pinned, true
+}
@@ -0,0 +1,75 @@
+package affinity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+func TestMiddleware(t *testing.T) {
+	a := rendezvous.NewConfigNode("a", 1, "")
+	b := rendezvous.NewConfigNode("b", 1, "")
+	hash := rendezvous.New(a, b)
+	var repins int
+	handler := Middleware(hash, Options[rendezvous.ConfigNode]{
+		OnRepin: func(string, rendezvous.ConfigNode) { repins++ },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node, _ := Node[rendezvous.ConfigNode](r.Context())
+		w.Write([]byte(node.ID))
+	}))
+
+	do := func(cookie *http.Cookie) (string, *http.Cookie) {
+		r := httptest.NewRequest("GET", "/", nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		cookies := w.Result().Cookies()
+		if len(cookies) == 0 {
+			return w.Body.String(), nil
+		}
+		return w.Body.String(), cookies[0]
+	}
+
+	backend, cookie := do(nil)
+	if cookie == nil || cookie.Name != DefaultCookieName || !cookie.HttpOnly {
+		t.Fatalf("got cookie %v, expected an affinity cookie for a new session", cookie)
+	}
+	if again, reissued := do(cookie); again != backend || reissued != nil {
+		t.Errorf("got backend %q and cookie %v, expected %q with no new cookie", again, reissued, backend)
+	}
+
+	// Sessions stay on their backend as backends join.
+	for _, id := range []string{"c", "d", "e", "f"} {
+		hash.Add(rendezvous.NewConfigNode(id, 1, ""))
+	}
+	if again, _ := do(cookie); again != backend {
+		t.Errorf("got backend %q, expected the session to stay on %q", again, backend)
+	}
+
+	hash.Remove(rendezvous.NewConfigNode(backend, 0, ""))
+	repinned, updated := do(cookie)
+	if repinned == backend || updated == nil || repins != 1 {
+		t.Fatalf("got backend %q, cookie %v and %d repins, expected a repin away from %q", repinned, updated, repins, backend)
+	}
+	if again, _ := do(updated); again != repinned {
+		t.Errorf("got backend %q, expected the session to stay on %q", again, repinned)
+	}
+
+	if _, reissued := do(&http.Cookie{Name: DefaultCookieName, Value: "malformed"}); reissued == nil {
+		t.Errorf("got no cookie, expected a malformed cookie to be replaced")
+	}
+
+	for _, node := range hash.Nodes() {
+		hash.Remove(node)
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, expected 503 with no backends", w.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package rendezvous
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Change describes a single topology change of a Hash. Nodes are
+// identified by their identity bytes, as strings.
+type Change struct {
+	Time time.Time
+	// Actor is the tag passed to AddAs or RemoveAs, or empty for Add and
+	// Remove.
+	Actor string
+	// Epoch is the Hash epoch after the change was applied.
+	Epoch   uint64
+	Added   []string
+	Removed []string
+	// Reweighted lists nodes whose weight or zone weight changed.
+	Reweighted []string
+}
+
+// AuditLog receives the membership changes of a Hash. Implementations may
+// forward changes to external storage; History returns whatever changes
+// the log has retained.
+type AuditLog interface {
+	Record(change Change)
+	History() []Change
+}
+
+// MemoryAuditLog is an AuditLog that retains the most recent changes in
+// memory. It is safe for concurrent use.
+type MemoryAuditLog struct {
+	mu      sync.Mutex
+	limit   int
+	changes []Change
+}
+
+// NewMemoryAuditLog returns a MemoryAuditLog retaining up to limit changes.
+// A limit of zero or less retains every change.
+func NewMemoryAuditLog(limit int) *MemoryAuditLog {
+	return &MemoryAuditLog{limit: limit}
+}
+
+// Record implements AuditLog.
+func (l *MemoryAuditLog) Record(change Change) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, change)
+	if l.limit > 0 && len(l.changes) > l.limit {
+		l.changes = slices.Delete(l.changes, 0, len(l.changes)-l.limit)
+	}
+}
+
+// History implements AuditLog. Changes are returned oldest first.
+func (l *MemoryAuditLog) History() []Change {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.changes)
+}
+
+// History returns the changes retained by the Hash's audit log, oldest
+// first, or nil if no audit log is configured.
+func (h *Hash[N]) History() []Change {
+	if h.audit == nil {
+		return nil
+	}
+	return h.audit.History()
+}
+
+// identities returns the identity of each node as a string.
+func (h *Hash[N]) identities(nodes []N) []string {
+	if len(nodes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = string(h.nodeID(node))
+	}
+	return ids
+}
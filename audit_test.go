@@ -0,0 +1,33 @@
+package rendezvous
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHashHistory(t *testing.T) {
+	if history := New[hashableString]("a").History(); history != nil {
+		t.Errorf("got: %v, expected: nil", history)
+	}
+
+	hash := NewWithOptions([]hashableString{"a", "b"}, WithAuditLog(NewMemoryAuditLog(2)))
+	hash.AddAs("operator", "c")
+	hash.Remove("missing")
+	hash.RemoveAs("autoscaler", "a")
+
+	history := hash.History()
+	if len(history) != 2 {
+		t.Fatalf("got %d changes, expected 2: %+v", len(history), history)
+	}
+
+	added, removed := history[0], history[1]
+	if added.Actor != "operator" || added.Epoch != 2 || !slices.Equal(added.Added, []string{"c"}) || added.Time.IsZero() {
+		t.Errorf("got: %+v, expected operator adding c at epoch 2", added)
+	}
+	if removed.Actor != "autoscaler" || removed.Epoch != 3 || !slices.Equal(removed.Removed, []string{"a"}) {
+		t.Errorf("got: %+v, expected autoscaler removing a at epoch 3", removed)
+	}
+	if hash.Epoch() != 3 {
+		t.Errorf("got epoch %d, expected 3", hash.Epoch())
+	}
+}
@@ -0,0 +1,97 @@
+package rendezvous
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseNodeList parses a compact node list of the form
+// "host1=2,host2=1,host3", where each entry is a node ID optionally
+// followed by "=" and its weight. Entries without a weight have a weight of
+// 1. Whitespace around entries is ignored, as are empty entries.
+func ParseNodeList(list string) ([]ConfigNode, error) {
+	var nodes []ConfigNode
+	seen := make(map[string]bool)
+	var errs []error
+	for i, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, weightText, hasWeight := strings.Cut(entry, "=")
+		id = strings.TrimSpace(id)
+		weight := 1.0
+		if hasWeight {
+			var err error
+			weight, err = strconv.ParseFloat(strings.TrimSpace(weightText), 64)
+			if err != nil || !(weight >= 0) || math.IsInf(weight, 0) {
+				errs = append(errs, &ConfigError{Index: i, ID: id, Err: fmt.Errorf("invalid weight %q", weightText)})
+				continue
+			}
+		}
+		switch {
+		case id == "":
+			errs = append(errs, &ConfigError{Index: i, Err: errors.New("missing id")})
+		case seen[id]:
+			errs = append(errs, &ConfigError{Index: i, ID: id, Err: errors.New("duplicate id")})
+		default:
+			seen[id] = true
+			nodes = append(nodes, NewConfigNode(id, weight, ""))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nodes, nil
+}
+
+// NewFromEnv returns a new Hash with the nodes listed in the environment
+// variable name, in the format accepted by ParseNodeList, configured by
+// opts. It returns an error if the variable is unset.
+func NewFromEnv(name string, opts ...Option) (*Hash[ConfigNode], error) {
+	list, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("rendezvous: environment variable %s is not set", name)
+	}
+	nodes, err := ParseNodeList(list)
+	if err != nil {
+		return nil, fmt.Errorf("rendezvous: %s: %w", name, err)
+	}
+	return NewWithOptions(nodes, opts...), nil
+}
+
+// NodeListFlag is a flag.Value holding nodes in the format accepted by
+// ParseNodeList:
+//
+//	var nodes rendezvous.NodeListFlag
+//	flag.Var(&nodes, "nodes", "nodes as host=weight,...")
+//	flag.Parse()
+//	hash := rendezvous.New(nodes...)
+type NodeListFlag []ConfigNode
+
+// String formats the nodes in the format accepted by ParseNodeList.
+func (f *NodeListFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	entries := make([]string, len(*f))
+	for i, node := range *f {
+		entries[i] = node.ID + "=" + strconv.FormatFloat(node.Weight(), 'g', -1, 64)
+	}
+	return strings.Join(entries, ",")
+}
+
+// Set replaces the nodes with those parsed from list.
+func (f *NodeListFlag) Set(list string) error {
+	nodes, err := ParseNodeList(list)
+	if err != nil {
+		return err
+	}
+	*f = nodes
+	return nil
+}
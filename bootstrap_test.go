@@ -0,0 +1,56 @@
+package rendezvous
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseNodeList(t *testing.T) {
+	nodes, err := ParseNodeList(" host1=2, host2=0.5 ,host3,, ")
+	if err != nil {
+		t.Fatalf("got error %v, expected none", err)
+	}
+	expected := []ConfigNode{NewConfigNode("host1", 2, ""), NewConfigNode("host2", 0.5, ""), NewConfigNode("host3", 1, "")}
+	if !New(nodes...).Equal(New(expected...)) {
+		t.Errorf("got: %v, expected: %v", nodes, expected)
+	}
+
+	_, err = ParseNodeList("a=x,=2,a,a,b=-1,c=NaN,d=Inf")
+	for _, want := range []string{`nodes[0] ("a"): invalid weight "x"`, `nodes[1]: missing id`, `nodes[3] ("a"): duplicate id`, `nodes[4] ("b"): invalid weight "-1"`, `nodes[5] ("c"): invalid weight "NaN"`, `nodes[6] ("d"): invalid weight "Inf"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %v, expected it to contain %q", err, want)
+		}
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("RENDEZVOUS_TEST_NODES", "a=2,b")
+	hash, err := NewFromEnv("RENDEZVOUS_TEST_NODES")
+	if err != nil {
+		t.Fatalf("got error %v, expected none", err)
+	}
+	if weight, _ := hash.Weight(NewConfigNode("a", 0, "")); weight != 2 || len(hash.Nodes()) != 2 {
+		t.Errorf("got %v, expected a=2 and b", hash)
+	}
+	if _, err := NewFromEnv("RENDEZVOUS_TEST_UNSET"); err == nil {
+		t.Errorf("got no error, expected one for an unset variable")
+	}
+}
+
+func TestNodeListFlag(t *testing.T) {
+	var nodes NodeListFlag
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Var(&nodes, "nodes", "")
+	if err := flags.Parse([]string{"-nodes", "a=2,b"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := nodes.String(); got != "a=2,b=1" {
+		t.Errorf("got: %s, expected: a=2,b=1", got)
+	}
+	if err := flags.Parse([]string{"-nodes", "a=?"}); err == nil {
+		t.Errorf("got no error, expected one for an invalid weight")
+	}
+}
@@ -0,0 +1,171 @@
+package rendezvous
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a node's circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed nodes are healthy and receive traffic normally.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen nodes are considered down and are skipped by lookups.
+	BreakerOpen
+	// BreakerHalfOpen nodes have cooled down and are admitted for a single
+	// probe request, whose outcome closes or reopens the breaker.
+	BreakerHalfOpen
+)
+
+// Breaker routes lookups around nodes that callers report as failing. Each
+// node has a circuit breaker that opens once at least MinRequests requests
+// have been reported and FailureRate of them failed. Open nodes are excluded
+// from Get and demoted to the end of GetN. After Cooldown, the breaker
+// half-opens and the next lookup that would have chosen the node is sent to
+// it as a probe: success closes the breaker, failure opens it again.
+//
+// A Breaker is safe for concurrent use, but uses its Hash for lookups, so
+// the Hash must not be used concurrently with it.
+type Breaker[N any] struct {
+	// FailureRate is the fraction of failed requests, in (0, 1], at which a
+	// breaker opens.
+	FailureRate float64
+	// MinRequests is the number of requests a breaker must see before it
+	// may open.
+	MinRequests int
+	// Cooldown is how long a breaker stays open before probing the node.
+	// It is also how long a probe may go unreported before another is sent.
+	Cooldown time.Duration
+
+	hash *Hash[N]
+	now  func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*breakerNode
+}
+
+// breakerNode is the circuit breaker state of a node.
+type breakerNode struct {
+	state              BreakerState
+	requests, failures int
+	// since is when the breaker opened, or when its last probe was sent.
+	since time.Time
+}
+
+// NewBreaker returns a Breaker for hash's nodes that opens once half of at
+// least 10 requests fail, and probes after 10 seconds.
+func NewBreaker[N any](hash *Hash[N]) *Breaker[N] {
+	return &Breaker[N]{
+		FailureRate: 0.5,
+		MinRequests: 10,
+		Cooldown:    10 * time.Second,
+		hash:        hash,
+		now:         time.Now,
+		states:      make(map[string]*breakerNode),
+	}
+}
+
+// Report records the outcome of a request sent to node: a failure if err is
+// not nil, and a success otherwise.
+func (b *Breaker[N]) Report(node N, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := string(b.hash.nodeID(node))
+	s, ok := b.states[id]
+	if !ok {
+		s = &breakerNode{}
+		b.states[id] = s
+	}
+
+	switch s.state {
+	case BreakerHalfOpen:
+		if err != nil {
+			*s = breakerNode{state: BreakerOpen, since: b.now()}
+		} else {
+			*s = breakerNode{}
+		}
+	case BreakerClosed:
+		s.requests++
+		if err != nil {
+			s.failures++
+		}
+		if s.requests >= b.MinRequests && float64(s.failures) >= b.FailureRate*float64(s.requests) {
+			*s = breakerNode{state: BreakerOpen, since: b.now()}
+		}
+	}
+}
+
+// State returns the state of node's circuit breaker.
+func (b *Breaker[N]) State(node N) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.states[string(b.hash.nodeID(node))]; ok {
+		return s.state
+	}
+	return BreakerClosed
+}
+
+// admit reports whether a lookup may return the node with identity id,
+// half-opening its breaker and claiming its probe if it has cooled down.
+func (b *Breaker[N]) admit(id []byte) bool {
+	s, ok := b.states[string(id)]
+	if !ok || s.state == BreakerClosed {
+		return true
+	}
+	if b.now().Sub(s.since) < b.Cooldown {
+		return false
+	}
+	s.state, s.since = BreakerHalfOpen, b.now()
+	return true
+}
+
+// Get returns the highest scoring node for key whose breaker admits it, and
+// false if every node is down or the Hash is empty. If the Hash has a
+// standby cache, lookups whose primary is down are served from it.
+func (b *Breaker[N]) Get(key string) (N, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h := b.hash
+	if h.standby != nil {
+		return h.failover(key, func(ns *nodeScore[N]) bool { return !b.admit(ns.id) })
+	}
+	h.rank(h.keyBytes(key))
+	for _, i := range h.order {
+		if b.admit(h.nodes[i].id) {
+			return h.nodes[i].node, true
+		}
+	}
+	var zero N
+	return zero, false
+}
+
+// GetN returns no more than n nodes for the given key, ordered by
+// descending score except that nodes whose breakers don't admit them follow
+// all the nodes that are admitted.
+func (b *Breaker[N]) GetN(n int, key string) []N {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h := b.hash
+	if len(h.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	h.rank(h.keyBytes(key))
+
+	nodes := make([]N, 0, min(n, len(h.order)))
+	var down []N
+	for _, i := range h.order {
+		if len(nodes) == n {
+			break
+		}
+		if b.admit(h.nodes[i].id) {
+			nodes = append(nodes, h.nodes[i].node)
+		} else {
+			down = append(down, h.nodes[i].node)
+		}
+	}
+	return append(nodes, down[:min(len(down), n-len(nodes))]...)
+}
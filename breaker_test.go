@@ -0,0 +1,57 @@
+package rendezvous
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	breaker := NewBreaker(hash)
+	now := time.Unix(0, 0)
+	breaker.now = func() time.Time { return now }
+
+	key := sampleKeys[0]
+	ranked := hash.GetN(3, key)
+	first := ranked[0]
+	failure := errors.New("unavailable")
+
+	for i := 0; i < breaker.MinRequests-1; i++ {
+		breaker.Report(first, failure)
+	}
+	if breaker.State(first) != BreakerClosed {
+		t.Fatalf("breaker opened before MinRequests requests")
+	}
+	breaker.Report(first, nil)
+	if breaker.State(first) != BreakerOpen {
+		t.Fatalf("got state %v, expected the breaker to open", breaker.State(first))
+	}
+
+	if got, _ := breaker.Get(key); got != ranked[1] {
+		t.Errorf("key=%q - got: %v, expected: %v while %v is down", key, got, ranked[1], first)
+	}
+	if got, expected := breaker.GetN(3, key), append(slices.Clone(ranked[1:]), first); !slices.Equal(got, expected) {
+		t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+	}
+
+	now = now.Add(breaker.Cooldown)
+	if got, _ := breaker.Get(key); got != first || breaker.State(first) != BreakerHalfOpen {
+		t.Errorf("key=%q - got: %v, expected a probe to %v", key, got, first)
+	}
+	if got, _ := breaker.Get(key); got != ranked[1] {
+		t.Errorf("key=%q - got: %v, expected a single probe in flight", key, got)
+	}
+	breaker.Report(first, failure)
+	if breaker.State(first) != BreakerOpen {
+		t.Errorf("got state %v, expected a failed probe to reopen the breaker", breaker.State(first))
+	}
+
+	now = now.Add(breaker.Cooldown)
+	breaker.Get(key)
+	breaker.Report(first, nil)
+	if got, _ := breaker.Get(key); got != first || breaker.State(first) != BreakerClosed {
+		t.Errorf("key=%q - got: %v, expected a successful probe to close the breaker", key, got)
+	}
+}
@@ -0,0 +1,92 @@
+package rendezvous
+
+// ObjectKeying selects which part of an object's location places it.
+type ObjectKeying int
+
+const (
+	// KeyByPath places objects by bucket and object key together, spreading
+	// a bucket's objects across nodes.
+	KeyByPath ObjectKeying = iota
+	// KeyByBucket places objects by bucket alone, keeping every object of a
+	// bucket on the same nodes, for backends that store a bucket as a unit.
+	KeyByBucket
+)
+
+// bucketPolicy overrides the placement of a bucket's objects. Zero fields
+// are not overridden.
+type bucketPolicy struct {
+	keying   *ObjectKeying
+	replicas int
+}
+
+// Buckets places the objects of S3-style object storage, addressed by
+// bucket and object key, on a Hash's nodes, such as the backend stores of
+// an object storage gateway. Keying and replica counts can be overridden
+// per bucket.
+//
+// Buckets is not safe for concurrent use, nor for use concurrently with the
+// Hash.
+type Buckets[N any] struct {
+	// Keying selects how objects are placed in buckets without an override.
+	// It defaults to KeyByPath.
+	Keying ObjectKeying
+	// Replicas is the number of nodes objects are placed on in buckets
+	// without an override.
+	Replicas int
+
+	hash     *Hash[N]
+	policies map[string]bucketPolicy
+}
+
+// NewBuckets returns Buckets placing objects on replicas of hash's nodes.
+func NewBuckets[N any](hash *Hash[N], replicas int) *Buckets[N] {
+	return &Buckets[N]{Replicas: replicas, hash: hash, policies: make(map[string]bucketPolicy)}
+}
+
+// SetKeying overrides how the objects of bucket are placed.
+func (b *Buckets[N]) SetKeying(bucket string, keying ObjectKeying) {
+	policy := b.policies[bucket]
+	policy.keying = &keying
+	b.policies[bucket] = policy
+}
+
+// SetReplicas overrides the number of nodes the objects of bucket are
+// placed on.
+func (b *Buckets[N]) SetReplicas(bucket string, replicas int) {
+	policy := b.policies[bucket]
+	policy.replicas = replicas
+	b.policies[bucket] = policy
+}
+
+// Reset removes the overrides of bucket.
+func (b *Buckets[N]) Reset(bucket string) {
+	delete(b.policies, bucket)
+}
+
+// Key returns the key object of bucket is placed by, which is also the key
+// to pass to the Hash's other lookups, such as ReplicatedBy, to find the
+// objects a node holds.
+func (b *Buckets[N]) Key(bucket, object string) string {
+	keying := b.Keying
+	if policy, ok := b.policies[bucket]; ok && policy.keying != nil {
+		keying = *policy.keying
+	}
+	if keying == KeyByBucket {
+		return PartsKey([]byte(bucket))
+	}
+	return PartsKey([]byte(bucket), []byte(object))
+}
+
+// ReplicasOf returns the number of nodes the objects of bucket are placed on.
+func (b *Buckets[N]) ReplicasOf(bucket string) int {
+	if policy := b.policies[bucket]; policy.replicas > 0 {
+		return policy.replicas
+	}
+	return b.Replicas
+}
+
+// Get returns the nodes object of bucket is placed on, in descending score
+// order.
+func (b *Buckets[N]) Get(bucket, object string) []N {
+	return b.hash.GetN(b.ReplicasOf(bucket), b.Key(bucket, object))
+}
@@ -0,0 +1,43 @@
+package rendezvous
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuckets(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	buckets := NewBuckets(hash, 2)
+	buckets.SetKeying("archive", KeyByBucket)
+	buckets.SetReplicas("critical", 3)
+
+	objects := []string{"photos/1.jpg", "photos/2.jpg", "logs/2024-01-01", "index.html", ""}
+	archived := buckets.Get("archive", objects[0])
+	spread := make(map[hashableString]bool)
+	for _, object := range objects {
+		got := buckets.Get("media", object)
+		if expected := hash.GetN(2, PartsKey([]byte("media"), []byte(object))); !reflect.DeepEqual(got, expected) {
+			t.Errorf("object=%q - got: %v, expected: %v", object, got, expected)
+		}
+		spread[got[0]] = true
+
+		if got := buckets.Get("archive", object); !reflect.DeepEqual(got, archived) {
+			t.Errorf("object=%q - got: %v, expected the bucket's nodes %v", object, got, archived)
+		}
+		if got := buckets.Get("critical", object); len(got) != 3 {
+			t.Errorf("object=%q - got: %v, expected 3 replicas", object, got)
+		}
+	}
+	if len(spread) < 2 {
+		t.Errorf("got every object of a bucket keyed by path on %v", spread)
+	}
+
+	buckets.Reset("archive")
+	if got, expected := buckets.Key("archive", "x"), PartsKey([]byte("archive"), []byte("x")); got != expected {
+		t.Errorf("got key %q, expected %q once the override is reset", got, expected)
+	}
+	buckets.Keying = KeyByBucket
+	if got, expected := buckets.Key("critical", "x"), PartsKey([]byte("critical")); got != expected || buckets.ReplicasOf("critical") != 3 {
+		t.Errorf("got key %q, expected %q with the replica override kept", got, expected)
+	}
+}
@@ -0,0 +1,109 @@
+package rendezvous
+
+import "container/list"
+
+// CacheStats reports the effectiveness of a Hash's lookup cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Invalidations counts how many times the cache was cleared because
+	// the topology changed.
+	Invalidations uint64
+	// Size is the number of keys currently cached.
+	Size int
+}
+
+// WithLookupCache memoizes the results of Get for up to size keys, evicting
+// the least recently used key when full. The cache is cleared whenever the
+// Hash's epoch changes, so it never returns a stale placement. It pays off
+// for workloads that look up the same keys repeatedly.
+func WithLookupCache(size int) Option {
+	return func(c *config) {
+		c.cacheSize = size
+	}
+}
+
+// lookupCache is a bounded LRU cache from keys to node indexes. Indexes are
+// only meaningful for the epoch they were computed in.
+type lookupCache struct {
+	size    int
+	epoch   uint64
+	entries map[string]*list.Element
+	lru     *list.List
+	stats   CacheStats
+}
+
+// cacheEntry is the value stored in each lookupCache list element. Standby
+// is the index of the node ranked second, for the standby cache, or -1.
+type cacheEntry struct {
+	key     string
+	index   int
+	standby int
+}
+
+func newLookupCache(size int) *lookupCache {
+	return &lookupCache{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+// get returns the cached node index for key at epoch.
+func (c *lookupCache) get(key string, epoch uint64) (int, bool) {
+	index, _, ok := c.getPair(key, epoch)
+	return index, ok
+}
+
+// getPair returns the cached node index and standby index for key at epoch.
+func (c *lookupCache) getPair(key string, epoch uint64) (int, int, bool) {
+	if epoch != c.epoch {
+		c.invalidate()
+		c.epoch = epoch
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(element)
+		c.stats.Hits++
+		entry := element.Value.(*cacheEntry)
+		return entry.index, entry.standby, true
+	}
+	c.stats.Misses++
+	return 0, 0, false
+}
+
+// invalidate empties the cache, for changes that don't advance the epoch.
+func (c *lookupCache) invalidate() {
+	if len(c.entries) > 0 {
+		clear(c.entries)
+		c.lru.Init()
+		c.stats.Invalidations++
+	}
+}
+
+// put caches index for key, evicting the least recently used key if full.
+func (c *lookupCache) put(key string, index int) {
+	c.putPair(key, index, -1)
+}
+
+// putPair caches index and standby for key, evicting the least recently
+// used key if full.
+func (c *lookupCache) putPair(key string, index, standby int) {
+	if c.lru.Len() >= c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, index: index, standby: standby})
+}
+
+// CacheStats returns the lookup cache's statistics, or the zero value if
+// the Hash has no lookup cache.
+func (h *Hash[N]) CacheStats() CacheStats {
+	if h.cache == nil {
+		return CacheStats{}
+	}
+	stats := h.cache.stats
+	stats.Size = len(h.cache.entries)
+	return stats
+}
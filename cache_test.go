@@ -0,0 +1,47 @@
+package rendezvous
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHashLookupCache(t *testing.T) {
+	hash := NewWithOptions([]hashableString{"a", "b", "c"}, WithLookupCache(2))
+	plain := New[hashableString]("a", "b", "c")
+
+	for _, key := range []string{"foo", "foo", "bar", "foo", "baz", "bar"} {
+		got, _ := hash.Get(key)
+		expected, _ := plain.Get(key)
+		if got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+	// "baz" evicted "bar", the least recently used key.
+	expected := CacheStats{Hits: 2, Misses: 4, Size: 2}
+	if got := hash.CacheStats(); got != expected {
+		t.Errorf("got: %+v, expected: %+v", got, expected)
+	}
+
+	// Removing a node must not leave stale placements behind.
+	var keyForB string
+	for i := 0; keyForB == ""; i++ {
+		if node, _ := hash.Get(fmt.Sprintf("key-%d", i)); node == "b" {
+			keyForB = fmt.Sprintf("key-%d", i)
+		}
+	}
+	hash.Remove("b")
+	if node, _ := hash.Get(keyForB); node == "b" {
+		t.Errorf("key=%q - got removed node b from the cache", keyForB)
+	}
+	if got := hash.CacheStats().Invalidations; got != 1 {
+		t.Errorf("got %d invalidations, expected 1", got)
+	}
+}
+
+func BenchmarkHashGet_10nodes_cached(b *testing.B) {
+	hash := NewWithOptions([]hashableString{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}, WithLookupCache(len(sampleKeys)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash.Get(sampleKeys[i%len(sampleKeys)])
+	}
+}
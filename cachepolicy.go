@@ -0,0 +1,140 @@
+package rendezvous
+
+import (
+	"bytes"
+	"sync"
+)
+
+// CachePolicy selects the peers of a distributed cache for each key: the
+// owner that fills it on a miss, the replicas that hold copies of it, and
+// the order to read them in, along with the peers to repair when a read
+// finds that a replica lacks the key.
+type CachePolicy[N any] struct {
+	// Replication is the number of peers that hold each key, its owner
+	// included.
+	Replication int
+	// OnRepair, if set, is called by Read with the replicas of key that
+	// missed it, to copy the value to them.
+	OnRepair func(key string, nodes []N)
+	// Locker, if set, is held while the Hash is read. It is not held while
+	// Read's lookup or OnRepair is called.
+	Locker sync.Locker
+
+	hash  *Hash[N]
+	local []byte
+}
+
+// NewCachePolicy returns a CachePolicy for the cache peers of hash,
+// replicating each key on replication peers.
+func NewCachePolicy[N any](hash *Hash[N], replication int) *CachePolicy[N] {
+	return &CachePolicy[N]{Replication: replication, hash: hash}
+}
+
+// SetLocal sets the peer the CachePolicy runs on, which ReadOrder reads
+// first, as reading from it costs no network round trip. The peer is matched
+// by identity, and is only read while it is in the Hash.
+func (p *CachePolicy[N]) SetLocal(node N) {
+	p.local = p.hash.Identity(node)
+}
+
+// Owner returns the peer that owns key, or false if there are no peers.
+func (p *CachePolicy[N]) Owner(key string) (N, bool) {
+	p.lock()
+	defer p.unlock()
+	return p.hash.Get(key)
+}
+
+// Replicas returns the rf peers holding key, its owner first.
+func (p *CachePolicy[N]) Replicas(key string, rf int) []N {
+	p.lock()
+	defer p.unlock()
+	return p.hash.GetN(rf, key)
+}
+
+// ReadOrder returns the peers to read key from, in order: the local peer,
+// then the owner, then the other replicas.
+func (p *CachePolicy[N]) ReadOrder(key string) []N {
+	p.lock()
+	defer p.unlock()
+	order, _ := p.readOrder(key)
+	return order
+}
+
+// readOrder returns the read order and the replicas of key.
+func (p *CachePolicy[N]) readOrder(key string) (order, replicas []N) {
+	replicas = p.hash.GetN(p.Replication, key)
+	if p.local == nil {
+		return replicas, replicas
+	}
+	local, ok := p.hash.Find(p.local)
+	if !ok {
+		return replicas, replicas
+	}
+
+	order = append(make([]N, 0, len(replicas)+1), local)
+	for _, node := range replicas {
+		if !bytes.Equal(p.hash.Identity(node), p.local) {
+			order = append(order, node)
+		}
+	}
+	return order, replicas
+}
+
+// Repairs returns the replicas of key to repair after a read in ReadOrder
+// found it on source, or on no peer if found is false: the replicas read
+// before source, which missed it, or every replica if no peer had it. The
+// local peer is only repaired if it is a replica.
+func (p *CachePolicy[N]) Repairs(key string, source N, found bool) []N {
+	p.lock()
+	defer p.unlock()
+	order, replicas := p.readOrder(key)
+	isReplica := p.hash.identitySet(replicas)
+
+	var sourceID []byte
+	if found {
+		sourceID = p.hash.Identity(source)
+	}
+	var repairs []N
+	for _, node := range order {
+		id := p.hash.Identity(node)
+		if found && bytes.Equal(id, sourceID) {
+			break
+		}
+		if _, ok := isReplica[string(id)]; ok {
+			repairs = append(repairs, node)
+		}
+	}
+	return repairs
+}
+
+// Read calls lookup for the peers of key in ReadOrder until one reports a
+// hit, and returns that peer, or false if every peer missed. If any replica
+// needs repair, it then calls OnRepair.
+func (p *CachePolicy[N]) Read(key string, lookup func(N) bool) (N, bool) {
+	var source N
+	found := false
+	for _, node := range p.ReadOrder(key) {
+		if lookup(node) {
+			source, found = node, true
+			break
+		}
+	}
+	if p.OnRepair != nil {
+		if repairs := p.Repairs(key, source, found); len(repairs) > 0 {
+			p.OnRepair(key, repairs)
+		}
+	}
+	return source, found
+}
+
+func (p *CachePolicy[N]) lock() {
+	if p.Locker != nil {
+		p.Locker.Lock()
+	}
+}
+
+func (p *CachePolicy[N]) unlock() {
+	if p.Locker != nil {
+		p.Locker.Unlock()
+	}
+}
@@ -0,0 +1,72 @@
+package rendezvous
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestCachePolicy(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	policy := NewCachePolicy(hash, 3)
+
+	for _, key := range sampleKeys {
+		replicas := hash.GetN(3, key)
+		if owner, _ := policy.Owner(key); owner != replicas[0] {
+			t.Errorf("key=%q - got owner: %v, expected: %v", key, owner, replicas[0])
+		}
+		if got := policy.Replicas(key, 2); !reflect.DeepEqual(got, replicas[:2]) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, replicas[:2])
+		}
+		if got := policy.ReadOrder(key); !reflect.DeepEqual(got, replicas) {
+			t.Errorf("key=%q - got: %v, expected: %v without a local peer", key, got, replicas)
+		}
+	}
+
+	policy.SetLocal("e")
+	for _, key := range sampleKeys {
+		replicas := hash.GetN(3, key)
+		order := policy.ReadOrder(key)
+		expected := append([]hashableString{"e"}, slices.DeleteFunc(slices.Clone(replicas), func(node hashableString) bool { return node == "e" })...)
+		if !reflect.DeepEqual(order, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, order, expected)
+		}
+
+		// A hit on the last peer read repairs the replicas read before it.
+		var repairs []hashableString
+		for _, node := range order[:len(order)-1] {
+			if slices.Contains(replicas, node) {
+				repairs = append(repairs, node)
+			}
+		}
+		if got := policy.Repairs(key, order[len(order)-1], true); !reflect.DeepEqual(got, repairs) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, repairs)
+		}
+		if got := policy.Repairs(key, order[0], true); got != nil {
+			t.Errorf("key=%q - got: %v, expected no repairs after a local hit", key, got)
+		}
+		if last := order[len(order)-1]; slices.Contains(replicas, last) {
+			repairs = append(repairs, last)
+		}
+		if got := policy.Repairs(key, "", false); !reflect.DeepEqual(got, repairs) {
+			t.Errorf("key=%q - got: %v, expected every replica %v after a miss", key, got, repairs)
+		}
+	}
+
+	var repaired []hashableString
+	policy.OnRepair = func(key string, nodes []hashableString) { repaired = nodes }
+	key := sampleKeys[0]
+	order := policy.ReadOrder(key)
+	source, ok := policy.Read(key, func(node hashableString) bool { return node == order[len(order)-1] })
+	if !ok || source != order[len(order)-1] {
+		t.Errorf("got %v, expected a hit on %v", source, order[len(order)-1])
+	}
+	if expected := policy.Repairs(key, source, true); !reflect.DeepEqual(repaired, expected) {
+		t.Errorf("got repairs %v, expected: %v", repaired, expected)
+	}
+
+	hash.Remove("e")
+	if got, expected := policy.ReadOrder(key), hash.GetN(3, key); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, expected: %v once the local peer is removed", got, expected)
+	}
+}
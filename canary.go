@@ -0,0 +1,64 @@
+package rendezvous
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCanaryFraction is returned by SetCanary for a fraction outside [0, 1),
+// or one that would bring the canaries' combined fraction to 1 or more.
+var ErrCanaryFraction = errors.New("rendezvous: canary fractions must be in [0, 1) and sum to less than 1")
+
+// SetCanary caps node at fraction of the keyspace, for safely introducing
+// new hardware or software versions. A canary's share is set independently
+// of node weights: its effective weight is derived from the total weight of
+// the other nodes so that it owns exactly fraction of the keys, and is
+// recomputed whenever they change. Raising fraction only moves keys onto the
+// canary, and lowering it only moves keys off.
+//
+// Canaries are matched by identity, and stay canaries when removed and
+// added again until ClearCanary is called. SetCanary returns an error if
+// node is not in the Hash or fraction is out of range.
+func (h *Hash[N]) SetCanary(node N, fraction float64) error {
+	id := h.nodeID(node)
+	if h.find(id) < 0 {
+		return fmt.Errorf("rendezvous: cannot make missing node %q a canary", id)
+	}
+	total := fraction
+	for canary, other := range h.canaries {
+		if canary != string(id) {
+			total += other
+		}
+	}
+	if fraction < 0 || fraction >= 1 || total >= 1 {
+		return ErrCanaryFraction
+	}
+
+	if h.canaries == nil {
+		h.canaries = make(map[string]float64)
+	}
+	h.canaries[string(id)] = fraction
+	h.reweigh()
+	h.commit("", nil, nil, []N{node})
+	return nil
+}
+
+// ClearCanary returns node to normal weighting, and reports whether it was
+// a canary.
+func (h *Hash[N]) ClearCanary(node N) bool {
+	id := h.nodeID(node)
+	if _, ok := h.canaries[string(id)]; !ok {
+		return false
+	}
+	delete(h.canaries, string(id))
+	h.reweigh()
+	h.commit("", nil, nil, []N{node})
+	return true
+}
+
+// Canary returns the fraction node is capped at, and false if it is not a
+// canary.
+func (h *Hash[N]) Canary(node N) (float64, bool) {
+	fraction, ok := h.canaries[string(h.nodeID(node))]
+	return fraction, ok
+}
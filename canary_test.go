@@ -0,0 +1,65 @@
+package rendezvous
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHashSetCanary(t *testing.T) {
+	hash := NewWithOptions([]hashableString{}, WithHasher(Hash128))
+	for i := 0; i < 10; i++ {
+		hash.Add(hashableString(fmt.Sprintf("node-%d", i)))
+	}
+	hash.SetWeight("node-1", 4)
+
+	if err := hash.SetCanary("node-0", 0.05); err != nil {
+		t.Fatal(err)
+	}
+	share := func() float64 {
+		for _, s := range hash.SampleShares(100000) {
+			if s.Node == "node-0" {
+				return s.Fraction
+			}
+		}
+		return 0
+	}
+	if got := share(); math.Abs(got-0.05) > 0.005 {
+		t.Errorf("got share %v, expected 0.05", got)
+	}
+
+	// The canary's share doesn't depend on the other nodes' weights.
+	hash.SetWeight("node-2", 10)
+	hash.Add("node-10", "node-11")
+	if got := share(); math.Abs(got-0.05) > 0.005 {
+		t.Errorf("got share %v after reweighting, expected 0.05", got)
+	}
+
+	// Raising the fraction only moves keys onto the canary.
+	before := make(map[string]hashableString)
+	for _, key := range sampleKeys {
+		before[key], _ = hash.Get(key)
+	}
+	hash.SetCanary("node-0", 0.2)
+	for _, key := range sampleKeys {
+		if after, _ := hash.Get(key); after != before[key] && after != "node-0" {
+			t.Errorf("key=%q - moved from %v to %v, expected only moves to the canary", key, before[key], after)
+		}
+	}
+
+	if err := hash.SetCanary("node-3", 0.8); err != ErrCanaryFraction {
+		t.Errorf("got error %v, expected ErrCanaryFraction for canaries summing to 1", err)
+	}
+	if err := hash.SetCanary("missing", 0.1); err == nil {
+		t.Error("got no error, expected one for a missing node")
+	}
+	if fraction, ok := hash.Canary("node-0"); !ok || fraction != 0.2 {
+		t.Errorf("got %v, %v, expected node-0 capped at 0.2", fraction, ok)
+	}
+	if !hash.ClearCanary("node-0") || hash.ClearCanary("node-0") {
+		t.Error("expected ClearCanary to report node-0 a canary once")
+	}
+	if got := share(); got < 0.03 || got > 0.07 {
+		t.Errorf("got share %v, expected a normal node's share after ClearCanary", got)
+	}
+}
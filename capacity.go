@@ -0,0 +1,92 @@
+package rendezvous
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CapacityFunc reports a node's capacity and how much of it is in use, in
+// any unit, such as bytes of disk or memory.
+type CapacityFunc[N any] func(node N) (capacity, used float64)
+
+// CapacityWeigher derives node weights from the capacity and utilization
+// nodes report, so that placement follows disk fill or memory pressure
+// rather than static configuration. Each Update consults the CapacityFunc
+// for every node and sets the node's weight to Weigh of the result.
+type CapacityWeigher[N any] struct {
+	// Weigh returns the weight of a node with capacity of which used is in
+	// use. It defaults to the free capacity, capacity - used, floored at
+	// zero, so that fuller nodes receive proportionally fewer new keys.
+	Weigh func(capacity, used float64) float64
+	// Locker, if set, is held while the Hash is read and modified, for
+	// Hashes shared with other goroutines. It is not held while the
+	// CapacityFunc is called.
+	Locker sync.Locker
+
+	hash     *Hash[N]
+	capacity CapacityFunc[N]
+}
+
+// NewCapacityWeigher returns a CapacityWeigher setting the weights of
+// hash's nodes from capacity.
+func NewCapacityWeigher[N any](hash *Hash[N], capacity CapacityFunc[N]) *CapacityWeigher[N] {
+	return &CapacityWeigher[N]{
+		Weigh: func(capacity, used float64) float64 {
+			return max(capacity-used, 0)
+		},
+		hash:     hash,
+		capacity: capacity,
+	}
+}
+
+// Update recomputes the weight of every node from its reported capacity,
+// as a single change to the Hash. Nodes removed while their capacity was
+// being consulted are skipped.
+func (w *CapacityWeigher[N]) Update() {
+	w.lock()
+	nodes := w.hash.Nodes()
+	w.unlock()
+
+	weights := make([]float64, len(nodes))
+	for i, node := range nodes {
+		weights[i] = w.Weigh(w.capacity(node))
+	}
+
+	w.lock()
+	defer w.unlock()
+	changes := Changeset[N]{Actor: "capacity"}
+	for i, node := range nodes {
+		if current, ok := w.hash.Weight(node); ok && current != weights[i] {
+			changes.Weights = append(changes.Weights, WeightChange[N]{Node: node, Weight: weights[i]})
+		}
+	}
+	w.hash.Apply(changes)
+}
+
+func (w *CapacityWeigher[N]) lock() {
+	if w.Locker != nil {
+		w.Locker.Lock()
+	}
+}
+
+func (w *CapacityWeigher[N]) unlock() {
+	if w.Locker != nil {
+		w.Locker.Unlock()
+	}
+}
+
+// Run calls Update every interval until ctx is done, and returns ctx's
+// error.
+func (w *CapacityWeigher[N]) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			w.Update()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package rendezvous
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCapacityWeigher(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	used := map[hashableString]float64{"a": 20, "b": 90, "c": 120}
+	var mu sync.Mutex
+	weigher := NewCapacityWeigher(hash, func(node hashableString) (float64, float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		return 100, used[node]
+	})
+
+	epoch := hash.Epoch()
+	weigher.Update()
+	for node, expected := range map[hashableString]float64{"a": 80, "b": 10, "c": 0} {
+		if got, _ := hash.Weight(node); got != expected {
+			t.Errorf("node=%v - got weight: %v, expected: %v", node, got, expected)
+		}
+	}
+	if hash.Epoch() != epoch+1 {
+		t.Errorf("got epoch %d, expected a single change", hash.Epoch())
+	}
+	weigher.Update()
+	if hash.Epoch() != epoch+1 {
+		t.Errorf("got epoch %d, expected no change when capacity is unchanged", hash.Epoch())
+	}
+
+	weigher.Weigh = func(capacity, used float64) float64 { return 1 - used/capacity }
+	var locker sync.Mutex
+	weigher.Locker = &locker
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	weigher.Run(ctx, time.Millisecond)
+	locker.Lock()
+	if got, _ := hash.Weight("a"); got != 0.8 {
+		t.Errorf("got weight %v for a, expected 0.8 from the custom Weigh", got)
+	}
+	locker.Unlock()
+}
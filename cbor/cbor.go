@@ -0,0 +1,419 @@
+// Package cbor encodes and decodes the Concise Binary Object Representation
+// (CBOR, RFC 8949), a compact binary alternative to JSON for topology
+// snapshots and deltas sent over constrained links.
+//
+// Values map to CBOR much as encoding/json maps them to JSON: structs encode
+// as maps keyed by field name, and honor the name, "omitempty" and "-"
+// options of their json struct tags, so that types already prepared for JSON
+// need no further annotations. Integers encode in the fewest bytes that hold
+// them, floats in the narrowest of half, single and double precision that
+// represents them exactly, and map keys in a deterministic order, so equal
+// values have equal encodings.
+//
+// Types that encode to JSON through a MarshalJSON method, typically to
+// include unexported state, should implement Marshaler and Unmarshaler too.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Marshaler is implemented by types that encode themselves to CBOR.
+// MarshalCBOR must return a single well-formed CBOR data item.
+type Marshaler interface {
+	MarshalCBOR() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves from CBOR.
+// UnmarshalCBOR is passed a single data item, which it must copy to retain.
+type Unmarshaler interface {
+	UnmarshalCBOR(data []byte) error
+}
+
+// RawMessage is an encoded CBOR data item. It may be used to delay decoding
+// part of a message, or to encode a precomputed item.
+type RawMessage []byte
+
+// MarshalCBOR returns m.
+func (m RawMessage) MarshalCBOR() ([]byte, error) {
+	if len(m) == 0 {
+		return []byte{simpleNull}, nil
+	}
+	return m, nil
+}
+
+// UnmarshalCBOR sets *m to a copy of data.
+func (m *RawMessage) UnmarshalCBOR(data []byte) error {
+	*m = append((*m)[:0], data...)
+	return nil
+}
+
+// Major types of CBOR data items, in the top three bits of their first byte.
+const (
+	majorUint   = 0 << 5
+	majorNegint = 1 << 5
+	majorBytes  = 2 << 5
+	majorText   = 3 << 5
+	majorArray  = 4 << 5
+	majorMap    = 5 << 5
+	majorTag    = 6 << 5
+	majorSimple = 7 << 5
+)
+
+// Simple values and floats of major type 7.
+const (
+	simpleFalse     = majorSimple | 20
+	simpleTrue      = majorSimple | 21
+	simpleNull      = majorSimple | 22
+	simpleUndefined = majorSimple | 23
+	simpleFloat16   = majorSimple | 25
+	simpleFloat32   = majorSimple | 26
+	simpleFloat64   = majorSimple | 27
+	simpleBreak     = majorSimple | 31
+)
+
+// indefinite is the additional information of an indefinite-length item.
+const indefinite = 31
+
+// maxDepth is the deepest nesting of arrays and maps Unmarshal accepts.
+const maxDepth = 1000
+
+var (
+	marshalerType   = reflect.TypeFor[Marshaler]()
+	unmarshalerType = reflect.TypeFor[Unmarshaler]()
+)
+
+// Marshal returns the CBOR encoding of v.
+func Marshal(v any) ([]byte, error) {
+	var e encoder
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// encoder appends encoded items to buf.
+type encoder struct {
+	buf []byte
+}
+
+// head appends the head of an item of the given major type and argument,
+// in the fewest bytes that hold the argument.
+func (e *encoder) head(major byte, n uint64) {
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, major|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, major|26), uint32(n))
+	default:
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, major|27), n)
+	}
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, simpleNull)
+		return nil
+	}
+	if v.Type().Implements(marshalerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			e.buf = append(e.buf, simpleNull)
+			return nil
+		}
+		data, err := v.Interface().(Marshaler).MarshalCBOR()
+		if err != nil {
+			return err
+		}
+		e.buf = append(e.buf, data...)
+		return nil
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(marshalerType) {
+		return e.encode(v.Addr())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, simpleTrue)
+		} else {
+			e.buf = append(e.buf, simpleFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n >= 0 {
+			e.head(majorUint, uint64(n))
+		} else {
+			e.head(majorNegint, uint64(-1-n))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.head(majorUint, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.float(v.Float())
+	case reflect.String:
+		e.head(majorText, uint64(v.Len()))
+		e.buf = append(e.buf, v.String()...)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, simpleNull)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.head(majorBytes, uint64(v.Len()))
+			e.buf = append(e.buf, v.Bytes()...)
+			return nil
+		}
+		return e.array(v)
+	case reflect.Array:
+		return e.array(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, simpleNull)
+			return nil
+		}
+		return e.mapping(v)
+	case reflect.Struct:
+		return e.structure(v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, simpleNull)
+			return nil
+		}
+		return e.encode(v.Elem())
+	default:
+		return fmt.Errorf("cbor: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// float appends f as the narrowest float that represents it exactly.
+func (e *encoder) float(f float64) {
+	if bits, ok := float16Bits(f); ok {
+		e.buf = binary.BigEndian.AppendUint16(append(e.buf, simpleFloat16), bits)
+	} else if float64(float32(f)) == f {
+		e.buf = binary.BigEndian.AppendUint32(append(e.buf, simpleFloat32), math.Float32bits(float32(f)))
+	} else {
+		e.buf = binary.BigEndian.AppendUint64(append(e.buf, simpleFloat64), math.Float64bits(f))
+	}
+}
+
+func (e *encoder) array(v reflect.Value) error {
+	e.head(majorArray, uint64(v.Len()))
+	for i := range v.Len() {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapping appends v with its entries ordered by their encoded keys, as
+// RFC 8949's core deterministic encoding requires.
+func (e *encoder) mapping(v reflect.Value) error {
+	type entry struct{ key, value []byte }
+	entries := make([]entry, 0, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		var key, value encoder
+		if err := key.encode(iter.Key()); err != nil {
+			return err
+		}
+		if err := value.encode(iter.Value()); err != nil {
+			return err
+		}
+		entries = append(entries, entry{key.buf, value.buf})
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return bytes.Compare(a.key, b.key) })
+
+	e.head(majorMap, uint64(len(entries)))
+	for _, entry := range entries {
+		e.buf = append(append(e.buf, entry.key...), entry.value...)
+	}
+	return nil
+}
+
+func (e *encoder) structure(v reflect.Value) error {
+	fields := cachedFields(v.Type())
+	var present []*field
+	for i := range fields {
+		f := &fields[i]
+		if fv, ok := f.value(v); ok && !(f.omitEmpty && isEmpty(fv)) {
+			present = append(present, f)
+		}
+	}
+	e.head(majorMap, uint64(len(present)))
+	for _, f := range present {
+		e.head(majorText, uint64(len(f.name)))
+		e.buf = append(e.buf, f.name...)
+		fv, _ := f.value(v)
+		if err := e.encode(fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether v is empty under the json "omitempty" option.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// field is an encoded field of a struct.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// value returns the field of struct v, and false if it is reached through
+// a nil embedded pointer.
+func (f *field) value(v reflect.Value) (reflect.Value, bool) {
+	for i, index := range f.index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+	return v, true
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// cachedFields returns the encoded fields of struct type t.
+func cachedFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t, nil))
+	return fields.([]field)
+}
+
+// typeFields returns the encoded fields of struct type t, whose fields are
+// reached from the outermost struct through index. Fields of embedded
+// structs without a name of their own are promoted, and shadowed by fields
+// of the same name at shallower depths.
+func typeFields(t reflect.Type, index []int) []field {
+	var fields []field
+	seen := make(map[string]bool)
+	var embedded []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldIndex := append(slices.Clone(index), i)
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, typeFields(ft, fieldIndex)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		seen[name] = true
+		fields = append(fields, field{name: name, index: fieldIndex, omitEmpty: slices.Contains(strings.Split(options, ","), "omitempty")})
+	}
+	for _, f := range embedded {
+		if !seen[f.name] {
+			seen[f.name] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// float16Bits returns the IEEE 754 half-precision encoding of f, and false
+// if f isn't exactly representable in half precision.
+func float16Bits(f float64) (uint16, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0x7e00, true
+	case math.IsInf(f, 1):
+		return 0x7c00, true
+	case math.IsInf(f, -1):
+		return 0xfc00, true
+	case f == 0:
+		if math.Signbit(f) {
+			return 0x8000, true
+		}
+		return 0, true
+	}
+
+	var sign uint16
+	if f < 0 {
+		sign, f = 0x8000, -f
+	}
+	frac, exp := math.Frexp(f) // f = frac * 2^exp, frac in [0.5, 1)
+	// Half precision normals are 1.m * 2^(e-15) with a 10-bit m and e in
+	// [1, 30]; subnormals are 0.m * 2^-14.
+	e := exp - 1 + 15
+	if e >= 31 {
+		return 0, false
+	}
+	if e <= 0 {
+		// Subnormal: f = m * 2^-24 for an integer m < 1024.
+		m := math.Ldexp(f, 24)
+		if m != math.Trunc(m) || m >= 1024 {
+			return 0, false
+		}
+		return sign | uint16(m), true
+	}
+	m := math.Ldexp(frac*2-1, 10)
+	if m != math.Trunc(m) {
+		return 0, false
+	}
+	return sign | uint16(e)<<10 | uint16(m), true
+}
+
+// float16Value returns the value of the half-precision encoding bits.
+func float16Value(bits uint16) float64 {
+	sign := 1.0
+	if bits&0x8000 != 0 {
+		sign = -1
+	}
+	e, m := int(bits>>10&0x1f), float64(bits&0x3ff)
+	switch e {
+	case 0:
+		return sign * math.Ldexp(m, -24)
+	case 31:
+		if m != 0 {
+			return math.NaN()
+		}
+		return sign * math.Inf(1)
+	}
+	return sign * math.Ldexp(1+m/1024, e-15)
+}
+
+// errTruncated is returned for input that ends within a data item.
+var errTruncated = errors.New("cbor: unexpected end of input")
@@ -0,0 +1,182 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalVectors(t *testing.T) {
+	// Examples from RFC 8949, Appendix A, with floats in their narrowest
+	// exact encoding.
+	testcases := []struct {
+		value    any
+		expected string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{1000, "1903e8"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{1.0, "f93c00"},
+		{1.5, "f93e00"},
+		{65504.0, "f97bff"},
+		{100000.0, "fa47c35000"},
+		{1.1, "fb3ff199999999999a"},
+		{5.960464477539063e-8, "f90001"},
+		{-4.0, "f9c400"},
+		{math.Inf(1), "f97c00"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{[]int{1, 2, 3}, "83010203"},
+		{[]any{1, []int{2, 3}, []int{4, 5}}, "8301820203820405"},
+		{map[string]string{"b": "B", "a": "A"}, "a26161614161626142"},
+	}
+	for _, testcase := range testcases {
+		got, err := Marshal(testcase.value)
+		if err != nil {
+			t.Errorf("value=%v - got error: %v", testcase.value, err)
+			continue
+		}
+		if hex.EncodeToString(got) != testcase.expected {
+			t.Errorf("value=%v - got: %x, expected: %s", testcase.value, got, testcase.expected)
+		}
+	}
+
+	if _, err := Marshal(make(chan int)); err == nil {
+		t.Error("got nil error encoding a channel")
+	}
+}
+
+type inner struct {
+	Zone string `json:"zone,omitempty"`
+}
+
+type record struct {
+	inner
+	ID      string            `json:"id"`
+	Weight  float64           `json:"weight"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Tags    []string          `json:"tags"`
+	Next    *record           `json:"next,omitempty"`
+	Raw     RawMessage        `json:"raw,omitempty"`
+	Ignored int               `json:"-"`
+	Count   uint16
+	private int
+}
+
+func TestRoundTrip(t *testing.T) {
+	raw, _ := Marshal([]string{"x"})
+	original := record{
+		inner:  inner{Zone: "us-east"},
+		ID:     "a",
+		Weight: 0.3,
+		Labels: map[string]string{"rack": "r1"},
+		Tags:   []string{"ssd"},
+		Next:   &record{ID: "b", Weight: -2},
+		Raw:    raw,
+		Count:  7,
+	}
+	data, err := Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded record
+	if err := Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, original) {
+		t.Errorf("got: %+v, expected: %+v", decoded, original)
+	}
+
+	var generic any
+	if err := Unmarshal(data, &generic); err != nil {
+		t.Fatal(err)
+	}
+	fields := generic.(map[string]any)
+	if fields["id"] != "a" || fields["zone"] != "us-east" || fields["Count"] != uint64(7) || fields["weight"] != 0.3 {
+		t.Errorf("got %v, expected the struct's fields by name", fields)
+	}
+	if _, ok := fields["Ignored"]; ok {
+		t.Errorf("got %v, expected fields tagged \"-\" omitted", fields)
+	}
+
+	again, _ := Marshal(decoded)
+	if !bytes.Equal(again, data) {
+		t.Errorf("got %x re-encoding, expected the same encoding %x", again, data)
+	}
+}
+
+func TestUnmarshalIndefinite(t *testing.T) {
+	// {_ "a": [_ 1, 2], "b": (_ "x", "y")}
+	data, _ := hex.DecodeString("bf61619f0102ff61627f61786179ffff")
+	var decoded struct {
+		A []int `json:"a"`
+		B string
+	}
+	if err := Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.A) != 2 || decoded.A[1] != 2 || decoded.B != "xy" {
+		t.Errorf("got %+v, expected a=[1 2] b=xy", decoded)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var s string
+	var n int8
+	var list []int
+	testcases := []struct {
+		data  string
+		value any
+	}{
+		{"", &s},
+		{"63616263", &n},              // a string into an int
+		{"190100", &n},                // 256 overflows an int8
+		{"62", &s},                    // truncated string
+		{"9bffffffffffffffff", &list}, // absurd length
+		{"0000", &n},                  // trailing data
+		{"1c", &n},                    // reserved additional information
+	}
+	for _, testcase := range testcases {
+		data, _ := hex.DecodeString(testcase.data)
+		if err := Unmarshal(data, testcase.value); err == nil {
+			t.Errorf("data=%s - got nil error", testcase.data)
+		}
+	}
+	if err := Unmarshal([]byte{0}, s); err == nil {
+		t.Error("got nil error decoding into a non-pointer")
+	}
+}
+
+func TestUnmarshalNullKey(t *testing.T) {
+	var v any
+	data, _ := hex.DecodeString("a2f60001f7") // {null: 0, 1: undefined}
+	if err := Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[any]any{nil: uint64(0), uint64(1): nil}; !reflect.DeepEqual(v, expected) {
+		t.Errorf("got: %#v, expected: %#v", v, expected)
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	f.Add([]byte("\xa6800080\xf6"))
+	f.Add([]byte("\xa1\x65Value\xa1\xf6\x00")) // {"Value": {null: 0}}
+	f.Add([]byte{0xa1, 0xf6, 0x00})
+	f.Add([]byte{0xa1, 0x80, 0x00})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v struct{ Value any }
+		Unmarshal(data, &v)
+		var r record
+		Unmarshal(data, &r)
+	})
+}
@@ -0,0 +1,532 @@
+package cbor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Unmarshal decodes the single CBOR data item in data into the value
+// pointed to by v. Maps decode into structs by field name, matched exactly
+// and then case-insensitively, and unknown keys are ignored. Into an empty
+// interface, items decode as bool, uint64, int64, float64, string, []byte,
+// []any, map[string]any for maps keyed by text and map[any]any otherwise.
+// Tags are ignored in favor of the items they tag.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("cbor: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+	d := decoder{data: data}
+	if err := d.decode(rv.Elem(), 0); err != nil {
+		return err
+	}
+	if d.off != len(data) {
+		return fmt.Errorf("cbor: %d bytes of trailing data", len(data)-d.off)
+	}
+	return nil
+}
+
+// decoder reads data items from data, starting at off.
+type decoder struct {
+	data []byte
+	off  int
+}
+
+// head reads the head of the next item, returning its major type, its
+// additional information and, unless indefinite, its argument.
+func (d *decoder) head() (major, info byte, arg uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, errTruncated
+	}
+	b := d.data[d.off]
+	d.off++
+	major, info = b&0xe0, b&0x1f
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == indefinite && major != majorUint && major != majorNegint && major != majorTag:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: malformed item head 0x%02x", b)
+	}
+	if len(d.data)-d.off < size {
+		return 0, 0, 0, errTruncated
+	}
+	for _, b := range d.data[d.off : d.off+size] {
+		arg = arg<<8 | uint64(b)
+	}
+	d.off += size
+	return major, info, arg, nil
+}
+
+// length checks that n elements of at least one byte each may follow, so
+// that malformed lengths can't cause huge allocations.
+func (d *decoder) length(n uint64) (int, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return 0, errTruncated
+	}
+	return int(n), nil
+}
+
+// isBreak reports whether the next byte ends an indefinite-length item, and
+// consumes it if so.
+func (d *decoder) isBreak() (bool, error) {
+	if d.off >= len(d.data) {
+		return false, errTruncated
+	}
+	if d.data[d.off] == simpleBreak {
+		d.off++
+		return true, nil
+	}
+	return false, nil
+}
+
+// skip advances past the next item, returning its encoding.
+func (d *decoder) skip(depth int) ([]byte, error) {
+	start := d.off
+	if depth > maxDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorBytes, majorText:
+		if info == indefinite {
+			for {
+				if done, err := d.isBreak(); err != nil || done {
+					return d.data[start:d.off], err
+				}
+				if _, err := d.skip(depth + 1); err != nil {
+					return nil, err
+				}
+			}
+		}
+		n, err := d.length(arg)
+		if err != nil {
+			return nil, err
+		}
+		d.off += n
+	case majorArray, majorMap:
+		items := arg
+		if major == majorMap {
+			items *= 2
+		}
+		if info != indefinite {
+			if _, err := d.length(items); err != nil {
+				return nil, err
+			}
+		}
+		for i := uint64(0); info == indefinite || i < items; i++ {
+			if info == indefinite {
+				if done, err := d.isBreak(); err != nil || done {
+					return d.data[start:d.off], err
+				}
+			}
+			if _, err := d.skip(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+	case majorTag:
+		if _, err := d.skip(depth + 1); err != nil {
+			return nil, err
+		}
+	case majorSimple:
+		if info == indefinite {
+			return nil, errors.New("cbor: unexpected break")
+		}
+	}
+	return d.data[start:d.off], nil
+}
+
+func (d *decoder) decode(v reflect.Value, depth int) error {
+	if depth > maxDepth {
+		return errors.New("cbor: nesting too deep")
+	}
+	if v.CanAddr() && v.Kind() != reflect.Pointer && v.Addr().Type().Implements(unmarshalerType) {
+		item, err := d.skip(depth)
+		if err != nil {
+			return err
+		}
+		return v.Addr().Interface().(Unmarshaler).UnmarshalCBOR(item)
+	}
+	if d.off >= len(d.data) {
+		return errTruncated
+	}
+
+	// Null and undefined zero pointers, maps, slices and interfaces, and
+	// leave other values unchanged.
+	if b := d.data[d.off]; b == simpleNull || b == simpleUndefined {
+		d.off++
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			v.SetZero()
+		}
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem(), depth+1)
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		value, err := d.decodeAny(depth)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(value))
+		}
+		return nil
+	}
+
+	start := d.off
+	major, info, arg, err := d.head()
+	if err != nil {
+		return err
+	}
+	mismatch := func(what string) error {
+		return fmt.Errorf("cbor: cannot decode %s into %s", what, v.Type())
+	}
+
+	switch major {
+	case majorUint, majorNegint:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if arg > math.MaxInt64 {
+				return mismatch("integer")
+			}
+			n := int64(arg)
+			if major == majorNegint {
+				n = -1 - n
+			}
+			if v.OverflowInt(n) {
+				return mismatch("integer")
+			}
+			v.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if major == majorNegint || v.OverflowUint(arg) {
+				return mismatch("integer")
+			}
+			v.SetUint(arg)
+		case reflect.Float32, reflect.Float64:
+			f := float64(arg)
+			if major == majorNegint {
+				f = -1 - f
+			}
+			v.SetFloat(f)
+		default:
+			return mismatch("integer")
+		}
+
+	case majorBytes, majorText:
+		s, err := d.str(major, info, arg, depth)
+		if err != nil {
+			return err
+		}
+		switch {
+		case v.Kind() == reflect.String:
+			v.SetString(string(s))
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(s)
+		default:
+			return mismatch("string")
+		}
+
+	case majorArray:
+		switch v.Kind() {
+		case reflect.Slice:
+			n := 0
+			if info != indefinite {
+				if n, err = d.length(arg); err != nil {
+					return err
+				}
+			}
+			slice := reflect.MakeSlice(v.Type(), 0, n)
+			for i := 0; info == indefinite || i < n; i++ {
+				if info == indefinite {
+					if done, err := d.isBreak(); err != nil {
+						return err
+					} else if done {
+						break
+					}
+				}
+				slice = reflect.Append(slice, reflect.Zero(v.Type().Elem()))
+				if err := d.decode(slice.Index(i), depth+1); err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+		case reflect.Array:
+			if info != indefinite {
+				if _, err := d.length(arg); err != nil {
+					return err
+				}
+			}
+			v.SetZero()
+			for i := 0; info == indefinite || uint64(i) < arg; i++ {
+				if info == indefinite {
+					if done, err := d.isBreak(); err != nil {
+						return err
+					} else if done {
+						break
+					}
+				}
+				if i >= v.Len() {
+					if _, err := d.skip(depth + 1); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := d.decode(v.Index(i), depth+1); err != nil {
+					return err
+				}
+			}
+		default:
+			return mismatch("array")
+		}
+
+	case majorMap:
+		switch v.Kind() {
+		case reflect.Map:
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			return d.entries(info, arg, depth, func() error {
+				key := reflect.New(v.Type().Key()).Elem()
+				if err := d.decode(key, depth+1); err != nil {
+					return err
+				}
+				value := reflect.New(v.Type().Elem()).Elem()
+				if err := d.decode(value, depth+1); err != nil {
+					return err
+				}
+				v.SetMapIndex(key, value)
+				return nil
+			})
+		case reflect.Struct:
+			fields := cachedFields(v.Type())
+			return d.entries(info, arg, depth, func() error {
+				var name string
+				if err := d.decode(reflect.ValueOf(&name).Elem(), depth+1); err != nil {
+					return err
+				}
+				f := findField(fields, name)
+				if f == nil {
+					_, err := d.skip(depth + 1)
+					return err
+				}
+				return d.decode(fieldForWrite(v, f), depth+1)
+			})
+		default:
+			return mismatch("map")
+		}
+
+	case majorTag:
+		return d.decode(v, depth+1)
+
+	case majorSimple:
+		switch d.data[start] {
+		case simpleFalse, simpleTrue:
+			if v.Kind() != reflect.Bool {
+				return mismatch("bool")
+			}
+			v.SetBool(d.data[start] == simpleTrue)
+		case simpleFloat16, simpleFloat32, simpleFloat64:
+			if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+				return mismatch("float")
+			}
+			v.SetFloat(floatValue(info, arg))
+		default:
+			return mismatch("simple value")
+		}
+	}
+	return nil
+}
+
+// entries calls fn for each key-value pair of a map with the given head.
+func (d *decoder) entries(info byte, arg uint64, depth int, fn func() error) error {
+	if info != indefinite {
+		if _, err := d.length(arg); err != nil {
+			return err
+		}
+	}
+	for i := uint64(0); info == indefinite || i < arg; i++ {
+		if info == indefinite {
+			if done, err := d.isBreak(); err != nil || done {
+				return err
+			}
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// str returns the contents of a byte or text string with the given head,
+// concatenating the chunks of an indefinite-length string.
+func (d *decoder) str(major, info byte, arg uint64, depth int) ([]byte, error) {
+	if info != indefinite {
+		n, err := d.length(arg)
+		if err != nil {
+			return nil, err
+		}
+		d.off += n
+		return append([]byte(nil), d.data[d.off-n:d.off]...), nil
+	}
+	var s []byte
+	for {
+		if done, err := d.isBreak(); err != nil || done {
+			return s, err
+		}
+		chunkMajor, chunkInfo, chunkArg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == indefinite {
+			return nil, errors.New("cbor: malformed indefinite-length string")
+		}
+		chunk, err := d.str(chunkMajor, chunkInfo, chunkArg, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+}
+
+// decodeAny decodes the next item into its default Go representation.
+func (d *decoder) decodeAny(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+	start := d.off
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		return arg, nil
+	case majorNegint:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(arg), nil
+	case majorBytes:
+		return d.str(major, info, arg, depth)
+	case majorText:
+		s, err := d.str(major, info, arg, depth)
+		return string(s), err
+	case majorArray:
+		var values []any
+		err := d.entries(info, arg, depth, func() error {
+			value, err := d.decodeAny(depth + 1)
+			values = append(values, value)
+			return err
+		})
+		if values == nil && err == nil {
+			values = []any{}
+		}
+		return values, err
+	case majorMap:
+		values := make(map[any]any)
+		textKeys := true
+		err := d.entries(info, arg, depth, func() error {
+			key, err := d.decodeAny(depth + 1)
+			if err != nil {
+				return err
+			}
+			_, isText := key.(string)
+			textKeys = textKeys && isText
+			// A null or undefined key decodes to nil, which is a valid key.
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				return errors.New("cbor: map key of non-comparable type")
+			}
+			values[key], err = d.decodeAny(depth + 1)
+			return err
+		})
+		if err != nil || !textKeys {
+			return values, err
+		}
+		byText := make(map[string]any, len(values))
+		for key, value := range values {
+			byText[key.(string)] = value
+		}
+		return byText, nil
+	case majorTag:
+		return d.decodeAny(depth + 1)
+	default:
+		switch d.data[start] {
+		case simpleFalse:
+			return false, nil
+		case simpleTrue:
+			return true, nil
+		case simpleNull, simpleUndefined:
+			return nil, nil
+		case simpleFloat16, simpleFloat32, simpleFloat64:
+			return floatValue(info, arg), nil
+		}
+		return nil, fmt.Errorf("cbor: unsupported simple value 0x%02x", d.data[start])
+	}
+}
+
+// floatValue returns the value of a float with the given additional
+// information and bits.
+func floatValue(info byte, bits uint64) float64 {
+	switch info {
+	case 25:
+		return float16Value(uint16(bits))
+	case 26:
+		return float64(math.Float32frombits(uint32(bits)))
+	default:
+		return math.Float64frombits(bits)
+	}
+}
+
+// findField returns the field named name, matched exactly and then
+// case-insensitively, or nil if there is none.
+func findField(fields []field, name string) *field {
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, name) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// fieldForWrite returns the field of struct v, allocating nil embedded
+// pointers on the way.
+func fieldForWrite(v reflect.Value, f *field) reflect.Value {
+	for i, index := range f.index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+	return v
+}
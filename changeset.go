@@ -0,0 +1,165 @@
+package rendezvous
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// WeightChange sets the weight of a node.
+type WeightChange[N any] struct {
+	Node   N
+	Weight float64
+}
+
+// Changeset is a batch of topology changes applied as a single transition.
+type Changeset[N any] struct {
+	// Actor is recorded in the audit log as the author of the change.
+	Actor string
+	// Remove lists nodes to remove, matched by identity. Nodes that aren't
+	// in the Hash are ignored.
+	Remove []N
+	// Add lists nodes to add. Removals are applied first, so a node listed
+	// in both Remove and Add is replaced.
+	Add []N
+	// AddIDs, if set, gives the node at each index of Add the identity at
+	// the same index instead of its own, as AddWithID does. Nodes with a
+	// nil identity, or beyond the end of AddIDs, keep their own.
+	AddIDs [][]byte
+	// Weights lists weight changes, applied after removals and additions.
+	Weights []WeightChange[N]
+}
+
+// Apply applies every change in changes as a single atomic transition: the
+// epoch advances once and one change is recorded in the audit log, so
+// observers never see a partially applied changeset. If any weight change
+// names a node that isn't in the Hash once removals and additions are
+// applied, or sets a NaN or infinite weight, Apply returns an error and
+// leaves the Hash unchanged. A changeset with no effect leaves the epoch
+// unchanged.
+func (h *Hash[N]) Apply(changes Changeset[N]) error {
+	if h.profileCtx != nil && (len(h.nodes) >= profileMinNodes || len(changes.Remove)+len(changes.Add)+len(changes.Weights) >= profileMinChanges) {
+		var err error
+		h.profiled("apply", func() { err = h.apply(changes) })
+		return err
+	}
+	return h.apply(changes)
+}
+
+// apply is Apply without profiling labels.
+func (h *Hash[N]) apply(changes Changeset[N]) error {
+	// Only membership changes copy the nodes and repack their identities;
+	// weight changes are made in place once every one is known to be valid.
+	nodes := h.nodes
+	var removed []N
+
+	// Identities given by AddIDs, keyed by the nodes' own, only replace
+	// the Hash's once the change is committed.
+	var assigned map[string][]byte
+	for i, id := range changes.AddIDs {
+		if i < len(changes.Add) && id != nil {
+			if assigned == nil {
+				assigned = make(map[string][]byte)
+			}
+			assigned[string(h.identity(changes.Add[i]))] = bytes.Clone(id)
+		}
+	}
+	nodeID := func(node N) []byte {
+		if id, ok := assigned[string(h.identity(node))]; ok {
+			return id
+		}
+		return h.nodeID(node)
+	}
+
+	if len(changes.Add) > 0 || slices.ContainsFunc(changes.Remove, func(node N) bool { return h.find(h.nodeID(node)) >= 0 }) {
+		nodes = slices.Clone(h.nodes)
+		for _, node := range changes.Remove {
+			if start := nodes.find(h.nodeID(node)); start >= 0 {
+				nodes = slices.Delete(nodes, start, nodes.span(start))
+				removed = append(removed, node)
+			}
+		}
+
+		for _, node := range changes.Add {
+			nodes = append(nodes, nodeScore[N]{
+				node:      node,
+				id:        nodeID(node),
+				zone:      nodeZone(node),
+				weight:    initialWeight(node),
+				effective: -1,
+			})
+		}
+		if len(changes.Add) > 0 {
+			slices.SortStableFunc(nodes, func(a, b nodeScore[N]) int {
+				return bytes.Compare(a.id, b.id)
+			})
+		}
+		nodes.pack()
+	}
+
+	starts := make([]int, len(changes.Weights))
+	for i, change := range changes.Weights {
+		id := nodeID(change.Node)
+		if starts[i] = nodes.find(id); starts[i] < 0 {
+			return fmt.Errorf("rendezvous: cannot set weight of missing node %q", id)
+		}
+		if math.IsNaN(change.Weight) || math.IsInf(change.Weight, 0) {
+			return fmt.Errorf("rendezvous: cannot set weight of node %q to %v", id, change.Weight)
+		}
+	}
+	var reweighted []N
+	for i, change := range changes.Weights {
+		for j := starts[i]; j < nodes.span(starts[i]); j++ {
+			nodes[j].weight = change.Weight
+		}
+		reweighted = append(reweighted, change.Node)
+	}
+
+	if len(removed) == 0 && len(changes.Add) == 0 && len(reweighted) == 0 {
+		return nil
+	}
+	for natural, id := range assigned {
+		if natural == string(id) {
+			delete(h.assigned, natural)
+			continue
+		}
+		if h.assigned == nil {
+			h.assigned = make(map[string][]byte)
+		}
+		h.assigned[natural] = id
+	}
+	h.nodes = nodes
+	h.reweigh()
+	h.commit(changes.Actor, changes.Add, removed, reweighted)
+	return nil
+}
+
+// Diff returns the changeset that makes h's membership match nodes: nodes
+// missing from h are added, nodes of h missing from nodes are removed, and
+// nodes present in both but with a different value, zone or initial weight
+// are replaced. Nodes are matched by identity, and their values compared
+// with reflect.DeepEqual.
+func (h *Hash[N]) Diff(nodes []N) Changeset[N] {
+	var changes Changeset[N]
+	desired := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		id := h.nodeID(node)
+		desired[string(id)] = true
+
+		i := h.find(id)
+		if i < 0 {
+			changes.Add = append(changes.Add, node)
+		} else if ns := &h.nodes[i]; ns.zone != nodeZone(node) || ns.weight != initialWeight(node) || !reflect.DeepEqual(ns.node, node) {
+			changes.Remove = append(changes.Remove, node)
+			changes.Add = append(changes.Add, node)
+		}
+	}
+	for _, ns := range h.nodes {
+		if !desired[string(ns.id)] {
+			changes.Remove = append(changes.Remove, ns.node)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,80 @@
+package rendezvous
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHashApply(t *testing.T) {
+	log := NewMemoryAuditLog(0)
+	hash := NewWithOptions([]hashableString{"a", "b", "c"}, WithAuditLog(log))
+	epoch := hash.Epoch()
+
+	err := hash.Apply(Changeset[hashableString]{
+		Actor:   "deploy",
+		Remove:  []hashableString{"b", "missing"},
+		Add:     []hashableString{"d", "e"},
+		Weights: []WeightChange[hashableString]{{Node: "d", Weight: 2}},
+	})
+	if err != nil {
+		t.Fatalf("got error %v, expected none", err)
+	}
+	if got, expected := hash.Nodes(), []hashableString{"a", "c", "d", "e"}; !slices.Equal(got, expected) {
+		t.Errorf("got: %v, expected: %v", got, expected)
+	}
+	if hash.Epoch() != epoch+1 {
+		t.Errorf("got epoch %d, expected a single change to %d", hash.Epoch(), epoch+1)
+	}
+	history := log.History()
+	last := history[len(history)-1]
+	if last.Actor != "deploy" || !slices.Equal(last.Added, []string{"d", "e"}) || !slices.Equal(last.Removed, []string{"b"}) || !slices.Equal(last.Reweighted, []string{"d"}) {
+		t.Errorf("got change %+v, expected d and e added, b removed and d reweighted by deploy", last)
+	}
+
+	before := hash.clone()
+	err = hash.Apply(Changeset[hashableString]{
+		Remove:  []hashableString{"a"},
+		Weights: []WeightChange[hashableString]{{Node: "a", Weight: 3}},
+	})
+	if err == nil {
+		t.Errorf("got no error, expected one for reweighting a removed node")
+	}
+	if !hash.Equal(before) || hash.Epoch() != epoch+1 {
+		t.Errorf("failed Apply modified the Hash")
+	}
+
+	if err := hash.Apply(Changeset[hashableString]{Remove: []hashableString{"missing"}}); err != nil || hash.Epoch() != epoch+1 {
+		t.Errorf("changeset with no effect advanced the epoch")
+	}
+}
+
+func TestHashApplyAddIDs(t *testing.T) {
+	hash := New[hashableString]("b")
+	err := hash.Apply(Changeset[hashableString]{
+		Add:     []hashableString{"10.0.0.1:80", "c"},
+		AddIDs:  [][]byte{[]byte("a")},
+		Weights: []WeightChange[hashableString]{{Node: "10.0.0.1:80", Weight: 2}},
+	})
+	if err != nil {
+		t.Fatalf("got error %v, expected none", err)
+	}
+	if got := string(hash.Identity("10.0.0.1:80")); got != "a" {
+		t.Errorf("got identity %q, expected a", got)
+	}
+	if node, ok := hash.Find([]byte("a")); !ok || node != "10.0.0.1:80" {
+		t.Errorf("got %v, %v, expected the node under a", node, ok)
+	}
+	if weight, _ := hash.Weight("10.0.0.1:80"); weight != 2 {
+		t.Errorf("got weight %v, expected 2", weight)
+	}
+
+	// A failed Apply assigns nothing.
+	err = hash.Apply(Changeset[hashableString]{
+		Add:     []hashableString{"10.0.0.2:80"},
+		AddIDs:  [][]byte{[]byte("d")},
+		Weights: []WeightChange[hashableString]{{Node: "missing", Weight: 1}},
+	})
+	if err == nil || string(hash.Identity("10.0.0.2:80")) != "10.0.0.2:80" {
+		t.Errorf("got error %v and identity %q, expected an error and no assignment", err, hash.Identity("10.0.0.2:80"))
+	}
+}
@@ -0,0 +1,37 @@
+package rendezvous
+
+// GetTwoChoices returns whichever of the two highest scoring nodes for key
+// reports the lower load, preferring the highest scoring node on a tie. It
+// trades some key affinity for better balance under hot-key skew: each key
+// is only ever served by one of two nodes, but load spreads across both.
+// load is called at most twice. It returns false if h is empty.
+func (h *Hash[N]) GetTwoChoices(key string, load func(N) float64) (N, bool) {
+	first, second := h.topTwo(h.keyBytes(key))
+	if first < 0 {
+		var zero N
+		return zero, false
+	}
+	if second >= 0 && load(h.nodes[second].node) < load(h.nodes[first].node) {
+		return h.nodes[second].node, true
+	}
+	return h.nodes[first].node, true
+}
+
+// topTwo returns the indexes of the highest and second highest scoring nodes
+// for keyBytes, breaking ties like top. Missing nodes are reported as -1.
+func (h *Hash[N]) topTwo(keyBytes []byte) (int, int) {
+	first, second := -1, -1
+	var firstScore, secondScore float64
+	h.scorer.begin(h.layout, keyBytes)
+	for i := range h.nodes {
+		score := h.scoreWith(&h.scorer, &h.nodes[i])
+		switch {
+		case first < 0 || score > firstScore || (score == firstScore && h.compareTie(h.nodes[i].id, h.nodes[first].id) < 0):
+			second, secondScore = first, firstScore
+			first, firstScore = i, score
+		case second < 0 || score > secondScore || (score == secondScore && h.compareTie(h.nodes[i].id, h.nodes[second].id) < 0):
+			second, secondScore = i, score
+		}
+	}
+	return first, second
+}
@@ -0,0 +1,28 @@
+package rendezvous
+
+import "testing"
+
+func TestHashGetTwoChoices(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	if _, ok := New[hashableString]().GetTwoChoices("key", nil); ok {
+		t.Errorf("got a node from an empty Hash")
+	}
+
+	for _, key := range sampleKeys {
+		ranked := hash.GetN(2, key)
+		loads := map[hashableString]float64{ranked[0]: 1, ranked[1]: 1}
+
+		if got, _ := hash.GetTwoChoices(key, func(n hashableString) float64 { return loads[n] }); got != ranked[0] {
+			t.Errorf("key=%q - got: %v, expected: %v on equal load", key, got, ranked[0])
+		}
+		loads[ranked[0]] = 2
+		if got, _ := hash.GetTwoChoices(key, func(n hashableString) float64 { return loads[n] }); got != ranked[1] {
+			t.Errorf("key=%q - got: %v, expected: %v when the first choice is busier", key, got, ranked[1])
+		}
+	}
+
+	single := New[hashableString]("a")
+	if got, ok := single.GetTwoChoices("key", func(hashableString) float64 { return 0 }); !ok || got != "a" {
+		t.Errorf("got: (%v, %t), expected: (a, true)", got, ok)
+	}
+}
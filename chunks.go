@@ -0,0 +1,63 @@
+package rendezvous
+
+import "slices"
+
+// GetChunk returns the nodes holding the content-addressed chunk with the
+// given digest, replicated replicas times: its primary followed by its
+// replicas, ordered by descending score. As the key is the digest itself,
+// identical chunks of different objects always land on the same nodes.
+// Digests are binary, so key normalizers don't apply to them, and on a Hash
+// with normalizers such lookups bypass statistics and sampling, as for
+// GetNParts.
+func (h *Hash[N]) GetChunk(digest []byte, replicas int) []N {
+	if h.normalizers == nil {
+		return h.GetN(replicas, string(digest))
+	}
+	return h.getNBinary(replicas, digest)
+}
+
+// GetChunks returns the nodes holding each of the chunks of an object with
+// the given digests, as GetChunk does. With spread, the chunks of the
+// object are spread across distinct nodes: each chunk is placed on its
+// highest scoring nodes among those holding the fewest of the object's
+// earlier chunks, so no node holds two of them until every node holds one,
+// and reading the object fans out across as many nodes as possible. Nodes
+// with a weight of zero or less are only used once no other node is left.
+//
+// A spread chunk's placement depends on the chunks before it, so an object
+// must always be placed with the same digests in the same order, and a
+// chunk shared with another object may be placed differently in each.
+func (h *Hash[N]) GetChunks(digests [][]byte, replicas int, spread bool) [][]N {
+	placements := make([][]N, len(digests))
+	if !spread {
+		for i, digest := range digests {
+			placements[i] = h.GetChunk(digest, replicas)
+		}
+		return placements
+	}
+	if len(h.nodes) == 0 || replicas <= 0 {
+		return placements
+	}
+
+	held := make([]int, len(h.nodes))
+	for c, digest := range digests {
+		h.rank(digest)
+		// Stable sorting keeps nodes holding as many chunks in score order.
+		slices.SortStableFunc(h.order, func(a, b int) int {
+			if drainedA, drainedB := h.nodes[a].effective <= 0, h.nodes[b].effective <= 0; drainedA != drainedB {
+				if drainedA {
+					return 1
+				}
+				return -1
+			}
+			return held[a] - held[b]
+		})
+		placement := make([]N, min(replicas, len(h.order)))
+		for i := range placement {
+			placement[i] = h.nodes[h.order[i]].node
+			held[h.order[i]]++
+		}
+		placements[c] = placement
+	}
+	return placements
+}
@@ -0,0 +1,86 @@
+package rendezvous
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHashGetChunks(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	var digests [][]byte
+	for i := range 12 {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("chunk-%d", i)))
+		digests = append(digests, digest[:])
+	}
+
+	placements := hash.GetChunks(digests, 2, false)
+	for i, digest := range digests {
+		if expected := hash.GetN(2, string(digest)); !reflect.DeepEqual(placements[i], expected) || !reflect.DeepEqual(hash.GetChunk(digest, 2), expected) {
+			t.Errorf("chunk=%d - got: %v, expected: %v", i, placements[i], expected)
+		}
+	}
+
+	// 12 chunks with 2 replicas each are 24 copies, so spread over 5 nodes
+	// each node holds 4 or 5, and a chunk's replicas are always distinct.
+	spread := hash.GetChunks(digests, 2, true)
+	held := make(map[hashableString]int)
+	for i, placement := range spread {
+		if len(placement) != 2 || placement[0] == placement[1] {
+			t.Errorf("chunk=%d - got: %v, expected 2 distinct nodes", i, placement)
+		}
+		for _, node := range placement {
+			held[node]++
+		}
+	}
+	for node, count := range held {
+		if count < 4 || count > 5 {
+			t.Errorf("node=%s - got: %d chunks, expected: 4 or 5", node, count)
+		}
+	}
+	if first := hash.GetN(2, string(digests[0])); !reflect.DeepEqual(spread[0], first) {
+		t.Errorf("got first chunk on %v, expected its own ranking %v", spread[0], first)
+	}
+	if again := hash.GetChunks(digests, 2, true); !reflect.DeepEqual(again, spread) {
+		t.Errorf("got %v, expected a deterministic placement %v", again, spread)
+	}
+
+	// Drained nodes are used only once no other node is left.
+	hash.SetWeight("a", 0)
+	for i, placement := range hash.GetChunks(digests, 4, true) {
+		for _, node := range placement {
+			if node == "a" {
+				t.Errorf("chunk=%d - got: %v, expected no drained node", i, placement)
+			}
+		}
+	}
+	if placement := hash.GetChunks(digests[:1], 5, true)[0]; placement[4] != "a" {
+		t.Errorf("got %v, expected the drained node last", placement)
+	}
+}
+
+func TestHashGetChunksNormalizer(t *testing.T) {
+	// Digests are binary and placed as they are: strings.ToLower would map
+	// their invalid UTF-8 bytes to U+FFFD, merging distinct digests.
+	nodes := []hashableString{"a", "b", "c", "d", "e"}
+	hash := NewWithOptions(nodes, WithKeyNormalizer(strings.ToLower), WithHasher(Hash128))
+	plain := NewWithOptions(nodes, WithHasher(Hash128))
+	var digests [][]byte
+	for i := range 100 {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("chunk-%d", i)))
+		digests = append(digests, digest[:])
+	}
+
+	for i, digest := range digests {
+		if got, expected := hash.GetChunk(digest, 3), plain.GetChunk(digest, 3); !reflect.DeepEqual(got, expected) {
+			t.Errorf("chunk=%d - got: %v, expected: %v", i, got, expected)
+		}
+	}
+	for _, spread := range []bool{false, true} {
+		if got, expected := hash.GetChunks(digests, 2, spread), plain.GetChunks(digests, 2, spread); !reflect.DeepEqual(got, expected) {
+			t.Errorf("spread=%t - got: %v, expected: %v", spread, got, expected)
+		}
+	}
+}
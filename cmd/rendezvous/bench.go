@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// benchConfig holds the settings for a bench run.
+type benchConfig struct {
+	nodes    []node
+	keys     []string
+	replicas int
+}
+
+// runBench implements the bench command.
+func runBench(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	nodeList := flags.String("nodes", "", "comma-separated node identities")
+	keyCount := flags.Int("keys", 100000, "number of random keys to generate")
+	keyFile := flags.String("key-file", "", "file of newline-separated keys to replay instead of random keys")
+	seed := flags.Uint64("seed", 1, "seed for random key generation")
+	replicas := flags.Int("n", 0, "benchmark GetN with this many replicas instead of Get")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := benchConfig{nodes: parseNodes(*nodeList), replicas: *replicas}
+	if len(cfg.nodes) == 0 {
+		return errors.New("-nodes is required")
+	}
+
+	var err error
+	if *keyFile != "" {
+		cfg.keys, err = readKeys(*keyFile)
+	} else {
+		cfg.keys = randomKeys(*keyCount, *seed)
+	}
+	if err != nil {
+		return err
+	}
+	if len(cfg.keys) == 0 {
+		return errors.New("no keys to replay")
+	}
+
+	return bench(cfg, stdout)
+}
+
+// readKeys reads newline-separated keys from path, skipping blank lines.
+func readKeys(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// randomKeys returns n pseudo-random keys that are reproducible for a seed.
+func randomKeys(n int, seed uint64) []string {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = strconv.FormatUint(rng.Uint64(), 16)
+	}
+	return keys
+}
+
+// bench replays cfg.keys against a Hash of cfg.nodes and writes a report of
+// throughput, allocations, and per-node distribution to w.
+func bench(cfg benchConfig, w io.Writer) error {
+	hash := rendezvous.New(cfg.nodes...)
+	counts := make(map[node]int, len(cfg.nodes))
+	owners := make([]node, len(cfg.keys))
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for i, key := range cfg.keys {
+		if cfg.replicas > 0 {
+			if ranked := hash.GetN(cfg.replicas, key); len(ranked) > 0 {
+				owners[i] = ranked[0]
+			}
+		} else {
+			owners[i], _ = hash.Get(key)
+		}
+	}
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	for _, owner := range owners {
+		counts[owner]++
+	}
+
+	ops := float64(len(cfg.keys))
+	operation := "Get"
+	if cfg.replicas > 0 {
+		operation = fmt.Sprintf("GetN(%d)", cfg.replicas)
+	}
+
+	fmt.Fprintf(w, "operation:   %s\n", operation)
+	fmt.Fprintf(w, "nodes:       %d\n", len(cfg.nodes))
+	fmt.Fprintf(w, "keys:        %d\n", len(cfg.keys))
+	fmt.Fprintf(w, "elapsed:     %s\n", elapsed)
+	fmt.Fprintf(w, "throughput:  %.0f ops/s\n", ops/elapsed.Seconds())
+	fmt.Fprintf(w, "latency:     %.1f ns/op\n", float64(elapsed.Nanoseconds())/ops)
+	fmt.Fprintf(w, "allocs:      %.2f allocs/op\n", float64(after.Mallocs-before.Mallocs)/ops)
+	fmt.Fprintf(w, "bytes:       %.1f B/op\n", float64(after.TotalAlloc-before.TotalAlloc)/ops)
+	fmt.Fprintln(w)
+
+	nodes := slices.Clone(cfg.nodes)
+	slices.Sort(nodes)
+	expected := 1 / float64(len(nodes))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "node\tkeys\tshare\tdeviation")
+	for _, n := range nodes {
+		share := float64(counts[n]) / ops
+		fmt.Fprintf(tw, "%s\t%d\t%.2f%%\t%+.2f%%\n", n, counts[n], share*100, (share-expected)/expected*100)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRandomKeysReproducible(t *testing.T) {
+	if a, b := randomKeys(100, 7), randomKeys(100, 7); !slices.Equal(a, b) {
+		t.Errorf("got different keys for the same seed")
+	}
+	if a, b := randomKeys(100, 7), randomKeys(100, 8); slices.Equal(a, b) {
+		t.Errorf("got identical keys for different seeds")
+	}
+}
+
+func TestBenchReport(t *testing.T) {
+	var out bytes.Buffer
+	err := runBench([]string{"-nodes", "a,b,c", "-keys", "1000", "-n", "2"}, &out)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	report := out.String()
+	for _, want := range []string{"operation:   GetN(2)", "keys:        1000", "throughput:", "allocs:"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	for _, n := range []string{"\na ", "\nb ", "\nc "} {
+		if !strings.Contains(report, n) {
+			t.Errorf("report missing distribution row %q:\n%s", n, report)
+		}
+	}
+
+	if err := runBench(nil, &out); err == nil {
+		t.Errorf("got nil error without -nodes")
+	}
+}
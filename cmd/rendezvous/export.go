@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// runExport implements the export command.
+func runExport(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	nodeList := flags.String("nodes", "", "comma-separated node identities")
+	samples := flags.Int("samples", 100000, "number of keys to sample")
+	format := flags.String("format", "dot", "output format: dot or html")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	nodes := parseNodes(*nodeList)
+	if len(nodes) == 0 {
+		return errors.New("-nodes is required")
+	}
+
+	shares := rendezvous.New(nodes...).SampleShares(*samples)
+	switch *format {
+	case "dot":
+		return rendezvous.WriteDOT(stdout, shares)
+	case "html":
+		return rendezvous.WriteHTML(stdout, shares)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// hashers maps the names accepted by -hasher to Hashers, and the
+// expressions that select them in generated code.
+var hashers = map[string]struct {
+	hasher rendezvous.Hasher
+	expr   string
+}{
+	"crc32c":  {rendezvous.CRC32C, "rendezvous.CRC32C"},
+	"hash128": {rendezvous.Hash128, "rendezvous.Hash128"},
+	"sha256":  {rendezvous.SHA256, "rendezvous.SHA256"},
+}
+
+// generateConfig holds the settings for a generate run.
+type generateConfig struct {
+	nodes  []rendezvous.ConfigNode
+	keys   []string
+	pkg    string
+	name   string
+	hasher string
+	args   []string
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// runGenerate implements the generate command.
+func runGenerate(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	nodeList := flags.String("nodes", "", "comma-separated node identities, each optionally followed by =weight")
+	keyFile := flags.String("key-file", "", "file of newline-separated keys to precompute")
+	var patterns stringList
+	flags.Var(&patterns, "pattern", "key pattern to precompute, with {lo..hi} expanding to each integer in the range; may be repeated")
+	maxKeys := flags.Int("max-keys", 1<<20, "maximum number of keys to precompute")
+	pkg := flags.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	name := flags.String("func", "Lookup", "name of the generated lookup function")
+	hasher := flags.String("hasher", "crc32c", "hasher: crc32c, hash128 or sha256")
+	output := flags.String("o", "", "file to write instead of standard output")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := generateConfig{pkg: *pkg, name: *name, hasher: *hasher, args: args}
+	var err error
+	if cfg.nodes, err = rendezvous.ParseNodeList(*nodeList); err != nil {
+		return err
+	}
+	if len(cfg.nodes) == 0 {
+		return errors.New("-nodes is required")
+	}
+	if !token.IsIdentifier(cfg.name) {
+		return fmt.Errorf("invalid -func %q", cfg.name)
+	}
+	if cfg.pkg == "" {
+		return errors.New("-package is required outside go generate")
+	}
+	if _, ok := hashers[cfg.hasher]; !ok {
+		return fmt.Errorf("unknown hasher %q", cfg.hasher)
+	}
+
+	if *keyFile != "" {
+		if cfg.keys, err = readKeys(*keyFile); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range patterns {
+		if cfg.keys, err = expandPattern(cfg.keys, pattern, *maxKeys); err != nil {
+			return err
+		}
+	}
+	if len(cfg.keys) > *maxKeys {
+		return fmt.Errorf("%d keys exceed -max-keys %d", len(cfg.keys), *maxKeys)
+	}
+
+	src, err := generate(cfg)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*output, src, 0o644)
+}
+
+// expandPattern appends every key matching pattern to keys. Each {lo..hi}
+// in pattern expands to the integers from lo to hi inclusive. It returns an
+// error once keys would exceed limit.
+func expandPattern(keys []string, pattern string, limit int) ([]string, error) {
+	start := strings.Index(pattern, "{")
+	if start < 0 {
+		if len(keys) >= limit {
+			return nil, fmt.Errorf("keys exceed -max-keys %d", limit)
+		}
+		return append(keys, pattern), nil
+	}
+	end := strings.Index(pattern[start:], "}")
+	if end < 0 {
+		return nil, fmt.Errorf("pattern %q: unterminated {", pattern)
+	}
+	end += start
+
+	loText, hiText, ok := strings.Cut(pattern[start+1:end], "..")
+	lo, loErr := strconv.Atoi(loText)
+	hi, hiErr := strconv.Atoi(hiText)
+	if !ok || loErr != nil || hiErr != nil || lo > hi {
+		return nil, fmt.Errorf("pattern %q: invalid range %q", pattern, pattern[start:end+1])
+	}
+	var err error
+	for i := lo; i <= hi && err == nil; i++ {
+		keys, err = expandPattern(keys, pattern[:start]+strconv.Itoa(i)+pattern[end+1:], limit)
+	}
+	return keys, err
+}
+
+// generate returns the source of a Go file with a lookup function answering
+// cfg.keys from a precomputed table and other keys by rendezvous hashing
+// over cfg.nodes.
+func generate(cfg generateConfig) ([]byte, error) {
+	hasher := hashers[cfg.hasher]
+	hash := rendezvous.NewWithOptions(cfg.nodes, rendezvous.WithHasher(hasher.hasher))
+	index := make(map[string]int, len(cfg.nodes))
+	for i, node := range cfg.nodes {
+		index[node.ID] = i
+	}
+
+	keys := slices.Clone(cfg.keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	// The generated variables are unexported whatever the function's name.
+	base := strings.ToLower(cfg.name[:1]) + cfg.name[1:]
+	table, nodes, lazy := base+"Table", base+"Nodes", base+"Hash"
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"rendezvous generate %s\"; DO NOT EDIT.\n\n", strings.Join(cfg.args, " "))
+	fmt.Fprintf(&b, "package %s\n\n", cfg.pkg)
+	fmt.Fprintf(&b, "import (\n\t\"sync\"\n\n\t\"github.com/beam-cloud/rendezvous\"\n)\n\n")
+
+	fmt.Fprintf(&b, "// %s are the nodes keys are placed on.\n", nodes)
+	fmt.Fprintf(&b, "var %s = []rendezvous.ConfigNode{\n", nodes)
+	for _, node := range cfg.nodes {
+		fmt.Fprintf(&b, "\trendezvous.NewConfigNode(%q, %s, \"\"),\n", node.ID, strconv.FormatFloat(node.Weight(), 'g', -1, 64))
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "// %s maps each precomputed key to the index of its node in %s.\n", table, nodes)
+	fmt.Fprintf(&b, "var %s = map[string]int{\n", table)
+	for _, key := range keys {
+		node, _ := hash.Get(key)
+		fmt.Fprintf(&b, "\t%q: %d,\n", key, index[node.ID])
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	fmt.Fprintf(&b, "// %s places keys missing from %s.\n", lazy, table)
+	fmt.Fprintf(&b, "var %s = sync.OnceValue(func() *rendezvous.Hash[rendezvous.ConfigNode] {\n", lazy)
+	fmt.Fprintf(&b, "\treturn rendezvous.NewWithOptions(%s, rendezvous.WithHasher(%s))\n})\n\n", nodes, hasher.expr)
+
+	fmt.Fprintf(&b, "// %s returns the ID of the node that owns key: from a precomputed table\n", cfg.name)
+	fmt.Fprintf(&b, "// for the keys it was generated with, and by rendezvous hashing otherwise.\n")
+	fmt.Fprintf(&b, "func %s(key string) string {\n", cfg.name)
+	fmt.Fprintf(&b, "\tif i, ok := %s[key]; ok {\n\t\treturn %s[i].ID\n\t}\n", table, nodes)
+	fmt.Fprintf(&b, "\tnode, _ := %s().Get(key)\n\treturn node.ID\n}\n", lazy)
+
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+func TestExpandPattern(t *testing.T) {
+	keys, err := expandPattern(nil, "user-{1..2}-{0..1}", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"user-1-0", "user-1-1", "user-2-0", "user-2-1"}; !slices.Equal(keys, expected) {
+		t.Errorf("got: %v, expected: %v", keys, expected)
+	}
+
+	for _, pattern := range []string{"user-{1..", "user-{2..1}", "user-{a..b}", "user-{1}"} {
+		if _, err := expandPattern(nil, pattern, 10); err == nil {
+			t.Errorf("pattern=%q - got nil error", pattern)
+		}
+	}
+	if _, err := expandPattern(nil, "user-{0..10}", 10); err == nil {
+		t.Errorf("got nil error for 11 keys with a limit of 10")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var out bytes.Buffer
+	err := runGenerate([]string{"-package", "edge", "-func", "Owner", "-nodes", "a=2,b,c", "-pattern", "user-{0..99}", "-hasher", "hash128"}, &out)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+
+	src := out.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "owner.go", src, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, src)
+	}
+	for _, want := range []string{"package edge", "func Owner(key string) string", "var ownerTable = map[string]int{", `rendezvous.NewConfigNode("a", 2, "")`, "rendezvous.WithHasher(rendezvous.Hash128)"} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source missing %q:\n%s", want, src)
+		}
+	}
+
+	nodes, _ := rendezvous.ParseNodeList("a=2,b,c")
+	hash := rendezvous.NewWithOptions(nodes, rendezvous.WithHasher(rendezvous.Hash128))
+	for _, key := range []string{"user-0", "user-42", "user-99"} {
+		node, _ := hash.Get(key)
+		index := slices.IndexFunc(nodes, func(n rendezvous.ConfigNode) bool { return n.ID == node.ID })
+		if entry := regexp.MustCompile(fmt.Sprintf(`%q:\s+%d,`, key, index)); !entry.MatchString(src) {
+			t.Errorf("key=%q - table missing entry for node index %d", key, index)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-package", "edge"},
+		{"-nodes", "a"},
+		{"-package", "edge", "-nodes", "a", "-hasher", "md5"},
+		{"-package", "edge", "-nodes", "a", "-func", "not valid"},
+	} {
+		if err := runGenerate(args, &out); err == nil {
+			t.Errorf("args=%q - got nil error", args)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Command rendezvous provides tooling around the rendezvous package.
+//
+// Usage:
+//
+//	rendezvous <command> [flags]
+//
+// Commands:
+//
+//	bench    replay keys against a topology and report throughput and distribution
+//	export   render sampled keyspace ownership as Graphviz DOT or HTML
+//	generate write a Go file with a precomputed placement table, for go:generate
+//	vectors  write JSON test vectors for verifying ports to other languages
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// node is a plain string node identity.
+type node = rendezvous.StringNode
+
+// parseNodes splits a comma-separated node list.
+func parseNodes(list string) []node {
+	var nodes []node
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			nodes = append(nodes, node(name))
+		}
+	}
+	return nodes
+}
+
+var commands = map[string]func(args []string, stdout io.Writer) error{
+	"bench":    runBench,
+	"export":   runExport,
+	"generate": runGenerate,
+	"vectors":  runVectors,
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: rendezvous <command> [flags]")
+	fmt.Fprintln(w, "commands:")
+	fmt.Fprintln(w, "  bench    replay keys against a topology and report throughput and distribution")
+	fmt.Fprintln(w, "  export   render sampled keyspace ownership as Graphviz DOT or HTML")
+	fmt.Fprintln(w, "  generate write a Go file with a precomputed placement table, for go:generate")
+	fmt.Fprintln(w, "  vectors  write JSON test vectors for verifying ports to other languages")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "rendezvous: unknown command %q\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	if err := command(os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "rendezvous %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// runVectors implements the vectors command.
+func runVectors(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("vectors", flag.ContinueOnError)
+	nodeList := flags.String("nodes", "", "comma-separated node identities, each optionally followed by =weight")
+	keyFile := flags.String("key-file", "", "file of newline-separated keys to use instead of the canonical keys")
+	hasher := flags.String("hasher", "crc32c", "hasher: crc32c, hash128 or sha256")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	nodes, err := rendezvous.ParseNodeList(*nodeList)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return errors.New("-nodes is required")
+	}
+	h, ok := hashers[*hasher]
+	if !ok {
+		return fmt.Errorf("unknown hasher %q", *hasher)
+	}
+
+	keys := rendezvous.VectorKeys()
+	if *keyFile != "" {
+		if keys, err = readKeys(*keyFile); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(rendezvous.NewWithOptions(nodes, rendezvous.WithHasher(h.hasher)).Vectors(keys))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+func TestVectors(t *testing.T) {
+	var out bytes.Buffer
+	if err := runVectors([]string{"-nodes", "a,b=2,c", "-hasher", "sha256"}, &out); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	var set rendezvous.VectorSet
+	if err := json.Unmarshal(out.Bytes(), &set); err != nil {
+		t.Fatal(err)
+	}
+	if set.Hasher != "SHA256" || len(set.Nodes) != 3 || len(set.Vectors) != len(rendezvous.VectorKeys()) {
+		t.Errorf("got %+v, expected SHA256 vectors for the canonical keys", set)
+	}
+	if err := runVectors(nil, &out); err == nil {
+		t.Errorf("got nil error without -nodes")
+	}
+}
@@ -0,0 +1,219 @@
+package rendezvous
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/beam-cloud/rendezvous/cbor"
+)
+
+// ConfigNode is a node declared in a configuration file. Its weight and
+// zone are reported through the Weighted and Zoned interfaces.
+type ConfigNode struct {
+	ID      string
+	Address string
+	Labels  map[string]string
+
+	weight float64
+	zone   string
+}
+
+// NewConfigNode returns a ConfigNode with the given identity, weight and
+// zone.
+func NewConfigNode(id string, weight float64, zone string) ConfigNode {
+	return ConfigNode{ID: id, weight: weight, zone: zone}
+}
+
+// Bytes implements the Hashable interface. A ConfigNode is identified by
+// its ID.
+func (n ConfigNode) Bytes() []byte {
+	return []byte(n.ID)
+}
+
+// Weight implements the Weighted interface.
+func (n ConfigNode) Weight() float64 {
+	return n.weight
+}
+
+// Zone implements the Zoned interface.
+func (n ConfigNode) Zone() string {
+	return n.zone
+}
+
+// String returns the node's ID.
+func (n ConfigNode) String() string {
+	return n.ID
+}
+
+// configNodeJSON is the JSON encoding of a ConfigNode.
+type configNodeJSON struct {
+	ID      string            `json:"id"`
+	Address string            `json:"address,omitempty"`
+	Weight  *float64          `json:"weight,omitempty"`
+	Zone    string            `json:"zone,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// MarshalJSON encodes n as a node entry of a Config.
+func (n ConfigNode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(configNodeJSON{ID: n.ID, Address: n.Address, Weight: &n.weight, Zone: n.zone, Labels: n.Labels})
+}
+
+// UnmarshalJSON decodes a node entry of a Config. A missing weight
+// defaults to 1.
+func (n *ConfigNode) UnmarshalJSON(data []byte) error {
+	var entry configNodeJSON
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	weight := 1.0
+	if entry.Weight != nil {
+		weight = *entry.Weight
+	}
+	*n = ConfigNode{ID: entry.ID, Address: entry.Address, Labels: entry.Labels, weight: weight, zone: entry.Zone}
+	return nil
+}
+
+// MarshalCBOR encodes n as MarshalJSON does, in CBOR.
+func (n ConfigNode) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(configNodeJSON{ID: n.ID, Address: n.Address, Weight: &n.weight, Zone: n.zone, Labels: n.Labels})
+}
+
+// UnmarshalCBOR decodes a node encoded by MarshalCBOR. A missing weight
+// defaults to 1.
+func (n *ConfigNode) UnmarshalCBOR(data []byte) error {
+	var entry configNodeJSON
+	if err := cbor.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+	weight := 1.0
+	if entry.Weight != nil {
+		weight = *entry.Weight
+	}
+	*n = ConfigNode{ID: entry.ID, Address: entry.Address, Labels: entry.Labels, weight: weight, zone: entry.Zone}
+	return nil
+}
+
+// Config is a declarative description of a Hash's topology. In JSON:
+//
+//	{
+//		"nodes": [
+//			{"id": "cache-1", "address": "10.0.0.1:6379", "weight": 2, "zone": "us-east", "labels": {"disk": "ssd"}},
+//			{"id": "cache-2", "address": "10.0.0.2:6379"}
+//		],
+//		"zoneWeights": {"us-east": 1}
+//	}
+//
+// A node's weight defaults to 1.
+type Config struct {
+	Nodes       []ConfigNode
+	ZoneWeights map[string]float64
+}
+
+// configJSON is the JSON encoding of a Config.
+type configJSON struct {
+	Nodes       []configNodeJSON   `json:"nodes"`
+	ZoneWeights map[string]float64 `json:"zoneWeights"`
+}
+
+// ConfigError reports an invalid node entry in a configuration.
+type ConfigError struct {
+	// Index is the position of the entry in the list of nodes.
+	Index int
+	ID    string
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("rendezvous: nodes[%d]: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("rendezvous: nodes[%d] (%q): %v", e.Index, e.ID, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ParseConfig parses and validates a JSON configuration. Unknown fields are
+// rejected, and syntax errors report their line. Every invalid node entry
+// is reported as a *ConfigError, joined together.
+func ParseConfig(data []byte) (*Config, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var raw configJSON
+	if err := decoder.Decode(&raw); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line := 1 + bytes.Count(data[:syntax.Offset], []byte("\n"))
+			return nil, fmt.Errorf("rendezvous: config line %d: %w", line, err)
+		}
+		return nil, fmt.Errorf("rendezvous: config: %w", err)
+	}
+
+	var errs []error
+	for zone, weight := range raw.ZoneWeights {
+		if !(weight >= 0) || math.IsInf(weight, 0) {
+			errs = append(errs, fmt.Errorf("rendezvous: zone %q: weight must be a finite number no less than 0, got %v", zone, weight))
+		}
+	}
+
+	config := &Config{Nodes: make([]ConfigNode, len(raw.Nodes)), ZoneWeights: raw.ZoneWeights}
+	seen := make(map[string]int, len(raw.Nodes))
+	for i, entry := range raw.Nodes {
+		weight := 1.0
+		if entry.Weight != nil {
+			weight = *entry.Weight
+		}
+		switch first, duplicate := seen[entry.ID]; {
+		case entry.ID == "":
+			errs = append(errs, &ConfigError{Index: i, Err: errors.New("missing id")})
+		case duplicate:
+			errs = append(errs, &ConfigError{Index: i, ID: entry.ID, Err: fmt.Errorf("duplicate of nodes[%d]", first)})
+		case weight < 0 || math.IsInf(weight, 0):
+			errs = append(errs, &ConfigError{Index: i, ID: entry.ID, Err: fmt.Errorf("weight must be a finite number no less than 0, got %v", weight)})
+		}
+		if _, duplicate := seen[entry.ID]; !duplicate {
+			seen[entry.ID] = i
+		}
+		config.Nodes[i] = ConfigNode{ID: entry.ID, Address: entry.Address, Labels: entry.Labels, weight: weight, zone: entry.Zone}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return config, nil
+}
+
+// LoadConfig reads and parses the JSON configuration file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfigNodes parses a JSON configuration and returns its nodes,
+// dropping its zone weights. Passed to NewWatcher, it reloads only the nodes
+// of a configuration file; NewConfigWatcher reloads its zone weights too.
+func ParseConfigNodes(data []byte) ([]ConfigNode, error) {
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	return config.Nodes, nil
+}
+
+// NewFromConfig returns a new Hash with config's nodes and zone weights,
+// configured by opts.
+func NewFromConfig(config *Config, opts ...Option) *Hash[ConfigNode] {
+	hash := NewWithOptions(config.Nodes, opts...)
+	for zone, weight := range config.ZoneWeights {
+		hash.SetZoneWeight(zone, weight)
+	}
+	return hash
+}
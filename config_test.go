@@ -0,0 +1,85 @@
+package rendezvous
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	data := `{
+		"nodes": [
+			{"id": "a", "address": "10.0.0.1:80", "weight": 2, "zone": "us", "labels": {"disk": "ssd"}},
+			{"id": "b", "zone": "eu"},
+			{"id": "c", "weight": 0, "zone": "eu"}
+		],
+		"zoneWeights": {"eu": 2}
+	}`
+	path := filepath.Join(t.TempDir(), "nodes.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("got error %v, expected none", err)
+	}
+
+	a := config.Nodes[0]
+	if a.ID != "a" || a.Address != "10.0.0.1:80" || a.Weight() != 2 || a.Zone() != "us" || a.Labels["disk"] != "ssd" {
+		t.Errorf("got node %+v, expected every field of a", a)
+	}
+	if config.Nodes[1].Weight() != 1 || config.Nodes[2].Weight() != 0 {
+		t.Errorf("got weights %v and %v, expected the default of 1 and an explicit 0", config.Nodes[1].Weight(), config.Nodes[2].Weight())
+	}
+
+	hash := NewFromConfig(config)
+	expected := New(NewConfigNode("a", 2, "us"), NewConfigNode("b", 1, "eu"), NewConfigNode("c", 0, "eu"))
+	expected.SetZoneWeight("eu", 2)
+	if !hash.Equal(expected) {
+		t.Errorf("got %v, expected %v", hash, expected)
+	}
+
+	moved := config.Nodes
+	moved[1].Address = "10.0.0.9:80"
+	hash.Apply(hash.Diff(moved))
+	if got := hash.Nodes()[1]; got.Address != "10.0.0.9:80" {
+		t.Errorf("got address %q, expected the reloaded address", got.Address)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	testcases := []struct {
+		data     string
+		expected []string
+	}{
+		{`{"nodes": [{"id": "a"}, {"address": "x"}, {"id": "a"}, {"id": "d", "weight": -1}]}`, []string{
+			`nodes[1]: missing id`,
+			`nodes[2] ("a"): duplicate of nodes[0]`,
+			`nodes[3] ("d"): weight must be a finite number no less than 0, got -1`,
+		}},
+		{"{\n\"nodes\": [\n{\"id\": \"a\",}\n]}", []string{"config line 3"}},
+		{`{"nodes": [{"id": "a", "wieght": 2}]}`, []string{`unknown field "wieght"`}},
+		{`{"zoneWeights": {"us": -1}}`, []string{`zone "us"`}},
+	}
+
+	for _, testcase := range testcases {
+		_, err := ParseConfig([]byte(testcase.data))
+		if err == nil {
+			t.Errorf("data=%s - got no error", testcase.data)
+			continue
+		}
+		for _, expected := range testcase.expected {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("data=%s - got error %q, expected it to contain %q", testcase.data, err, expected)
+			}
+		}
+	}
+
+	_, err := ParseConfig([]byte(`{"nodes": [{"id": "a", "weight": -1}]}`))
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Index != 0 || configErr.ID != "a" {
+		t.Errorf("got error %v, expected a ConfigError for nodes[0]", err)
+	}
+}
@@ -0,0 +1,176 @@
+package rendezvous
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// Connection is a long-lived connection, such as a WebSocket or a streaming
+// RPC, registered with Connections against the node serving it.
+type Connection[N any] struct {
+	ID uint64
+	// Key is the key the connection was routed by.
+	Key string
+	// Node is the node the connection was routed to.
+	Node N
+
+	id []byte
+	// moved records that OnMoved was called for the connection since its
+	// key last mapped to its node.
+	moved bool
+}
+
+// Connections routes long-lived connections by key and tracks the node
+// each was routed to. Unlike a request, a connection stays on its node
+// when the topology changes, so Update reports the connections whose key
+// has moved to another node, for the application to migrate or drain them.
+//
+// Connections is safe for concurrent use. Set Locker if the Hash is
+// modified concurrently with it.
+type Connections[N any] struct {
+	// Locker, if set, is held while the Hash is read.
+	Locker sync.Locker
+	// OnMoved, if set, is called by Update once for each connection whose
+	// key has moved to owner, or to no node if ok is false, while the
+	// connection stays on its node. It is called again if the key moves
+	// back to the connection's node and away once more.
+	OnMoved func(conn *Connection[N], owner N, ok bool)
+
+	hash *Hash[N]
+
+	mu     sync.Mutex
+	conns  map[uint64]*Connection[N]
+	lastID uint64
+	// epoch is the epoch of the Hash at the last Update.
+	epoch uint64
+}
+
+// NewConnections returns Connections routed by hash.
+func NewConnections[N any](hash *Hash[N]) *Connections[N] {
+	return &Connections[N]{hash: hash, conns: make(map[uint64]*Connection[N])}
+}
+
+// Connect selects the node for a new connection with key, and registers the
+// connection against it. It returns false if the Hash is empty.
+func (c *Connections[N]) Connect(key string) (*Connection[N], bool) {
+	c.lock()
+	node, ok := c.hash.Get(key)
+	id := c.hash.Identity(node)
+	c.unlock()
+	if !ok {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastID++
+	conn := &Connection[N]{ID: c.lastID, Key: key, Node: node, id: id}
+	c.conns[conn.ID] = conn
+	return conn, true
+}
+
+// Disconnect unregisters the connection with id, and reports whether it
+// was registered.
+func (c *Connections[N]) Disconnect(id uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.conns[id]
+	delete(c.conns, id)
+	return ok
+}
+
+// On returns the connections registered against node, matched by identity.
+func (c *Connections[N]) On(node N) []*Connection[N] {
+	c.lock()
+	id := c.hash.Identity(node)
+	c.unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var conns []*Connection[N]
+	for _, conn := range c.conns {
+		if bytes.Equal(conn.id, id) {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// Len returns the number of registered connections.
+func (c *Connections[N]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.conns)
+}
+
+// Update checks every registered connection against the Hash's current
+// topology, calls OnMoved for those whose key has moved since the last
+// Update, and returns their number. It does nothing if the topology hasn't
+// changed since the last Update.
+func (c *Connections[N]) Update() int {
+	type move struct {
+		conn  *Connection[N]
+		owner N
+		ok    bool
+	}
+	var moves []move
+
+	c.mu.Lock()
+	c.lock()
+	epoch := c.hash.Epoch()
+	if epoch == c.epoch {
+		c.unlock()
+		c.mu.Unlock()
+		return 0
+	}
+	c.epoch = epoch
+	for _, conn := range c.conns {
+		owner, ok := c.hash.Get(conn.Key)
+		if ok && bytes.Equal(c.hash.Identity(owner), conn.id) {
+			conn.moved = false
+			continue
+		}
+		if !conn.moved {
+			conn.moved = true
+			moves = append(moves, move{conn, owner, ok})
+		}
+	}
+	c.unlock()
+	c.mu.Unlock()
+
+	if c.OnMoved != nil {
+		for _, m := range moves {
+			c.OnMoved(m.conn, m.owner, m.ok)
+		}
+	}
+	return len(moves)
+}
+
+// Run calls Update every interval until ctx is done, and returns ctx's
+// error.
+func (c *Connections[N]) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			c.Update()
+		}
+	}
+}
+
+func (c *Connections[N]) lock() {
+	if c.Locker != nil {
+		c.Locker.Lock()
+	}
+}
+
+func (c *Connections[N]) unlock() {
+	if c.Locker != nil {
+		c.Locker.Unlock()
+	}
+}
@@ -0,0 +1,61 @@
+package rendezvous
+
+import "testing"
+
+func TestConnections(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	conns := NewConnections(hash)
+	var moved []*Connection[hashableString]
+	conns.OnMoved = func(conn *Connection[hashableString], owner hashableString, ok bool) {
+		if expected, _ := hash.Get(conn.Key); !ok || owner != expected {
+			t.Errorf("key=%q - got owner: %v, expected: %v", conn.Key, owner, expected)
+		}
+		moved = append(moved, conn)
+	}
+
+	for _, key := range sampleKeys {
+		conn, ok := conns.Connect(key)
+		if expected, _ := hash.Get(key); !ok || conn.Node != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, conn.Node, expected)
+		}
+	}
+	if got := len(conns.On("a")) + len(conns.On("b")) + len(conns.On("c")); got != len(sampleKeys) || conns.Len() != len(sampleKeys) {
+		t.Errorf("got %d connections across nodes, expected: %d", got, len(sampleKeys))
+	}
+	if got := conns.Update(); got != 0 || len(moved) != 0 {
+		t.Errorf("got %d moved connections, expected none before a topology change", got)
+	}
+
+	hash.Add("d")
+	expected := 0
+	for _, key := range sampleKeys {
+		if owner, _ := hash.Get(key); owner == "d" {
+			expected++
+		}
+	}
+	if got := conns.Update(); got != expected || len(moved) != expected {
+		t.Errorf("got %d moved connections, expected: %d", got, expected)
+	}
+	for _, conn := range moved {
+		if conn.Node == "d" {
+			t.Errorf("key=%q - got connection moved to its own node", conn.Key)
+		}
+	}
+
+	// Connections are reported once per move, until their key moves back.
+	hash.Add("e")
+	hash.Remove("e")
+	if got := conns.Update(); got != 0 {
+		t.Errorf("got %d moved connections, expected moved connections to be reported once", got)
+	}
+	hash.Remove("d")
+	conns.Update()
+	hash.Add("d")
+	if got := conns.Update(); got != expected {
+		t.Errorf("got %d moved connections, expected %d reported again", got, expected)
+	}
+
+	if len(moved) > 0 && (!conns.Disconnect(moved[0].ID) || conns.Disconnect(moved[0].ID)) {
+		t.Errorf("expected the connection to be disconnected once")
+	}
+}
@@ -0,0 +1,166 @@
+package rendezvous
+
+import "slices"
+
+// Constraint reports whether candidate may join a replica set that already
+// holds selected, for example to keep replicas on different hosts.
+type Constraint[N any] func(selected []N, candidate N) bool
+
+// GetNConstrained returns no more than n nodes for the given key, walking
+// nodes in descending score order and skipping any candidate that constraint
+// rejects. The result is deterministic for a given topology and constraint.
+// Fewer than n nodes are returned if not enough nodes satisfy constraint.
+func (h *Hash[N]) GetNConstrained(n int, key string, constraint Constraint[N]) []N {
+	if len(h.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	h.rank(h.keyBytes(key))
+
+	selected := make([]N, 0, min(n, len(h.order)))
+	for _, i := range h.order {
+		if len(selected) == n {
+			break
+		}
+		if candidate := h.nodes[i].node; constraint(selected, candidate) {
+			selected = append(selected, candidate)
+		}
+	}
+	return selected
+}
+
+// GetNDistinct returns no more than n nodes for the given key that are
+// pairwise distinct under equivalent, for example to keep replicas off
+// nodes that share a physical host behind different ports. Nodes are walked
+// in descending score order, skipping any equivalent to a node already
+// selected.
+func (h *Hash[N]) GetNDistinct(n int, key string, equivalent func(a, b N) bool) []N {
+	return h.GetNConstrained(n, key, func(selected []N, candidate N) bool {
+		return !slices.ContainsFunc(selected, func(node N) bool {
+			return equivalent(node, candidate)
+		})
+	})
+}
+
+// ZoneMinimums maps zones, as reported by nodes implementing Zoned, to the
+// minimum number of replicas each must hold.
+type ZoneMinimums map[string]int
+
+// GetNZoned returns no more than n nodes for the given key, such that every
+// zone in minimums holds at least its minimum number of replicas where it has
+// enough nodes. Each zone's requirement is met with its highest scoring
+// nodes, and the remaining slots are filled in descending score order. The
+// result is ordered by descending score, so its first node may differ from
+// Get's when the top-ranked nodes cannot satisfy minimums.
+//
+// If the minimums add up to more than n, zones are served in the order their
+// nodes appear in the ranking until n nodes are selected.
+func (h *Hash[N]) GetNZoned(n int, key string, minimums ZoneMinimums) []N {
+	if len(h.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	h.rank(h.keyBytes(key))
+
+	placed := make(map[string]int, len(minimums))
+	return h.selectRanked(n, func(ns *nodeScore[N]) bool {
+		if placed[ns.zone] < minimums[ns.zone] {
+			placed[ns.zone]++
+			return true
+		}
+		return false
+	})
+}
+
+// Hints steer a GetNHinted lookup. Nodes are matched by identity, and hints
+// naming nodes that aren't in the Hash are ignored.
+type Hints[N any] struct {
+	// Required nodes are always included in the result, such as the node
+	// that already holds a key's data.
+	Required []N
+	// Preferred nodes win ties against nodes with an equal score.
+	Preferred []N
+}
+
+// GetNHinted returns no more than n nodes for the given key, ranked as by
+// GetN but adjusted by hints. Required nodes displace the lowest ranked
+// nodes that would otherwise be returned; if there are more than n, the n
+// highest ranked are kept. The result is ordered by descending score.
+func (h *Hash[N]) GetNHinted(n int, key string, hints Hints[N]) []N {
+	if len(h.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	h.rank(h.keyBytes(key))
+
+	if len(hints.Preferred) > 0 {
+		preferred := h.identitySet(hints.Preferred)
+		for start := 0; start < len(h.order); {
+			end := start + 1
+			for end < len(h.order) && h.nodes[h.order[end]].score == h.nodes[h.order[start]].score {
+				end++
+			}
+			slices.SortStableFunc(h.order[start:end], func(a, b int) int {
+				_, preferA := preferred[string(h.nodes[a].id)]
+				_, preferB := preferred[string(h.nodes[b].id)]
+				switch {
+				case preferA && !preferB:
+					return -1
+				case preferB && !preferA:
+					return 1
+				}
+				return 0
+			})
+			start = end
+		}
+	}
+
+	required := h.identitySet(hints.Required)
+	return h.selectRanked(n, func(ns *nodeScore[N]) bool {
+		_, ok := required[string(ns.id)]
+		return ok
+	})
+}
+
+// selectRanked returns up to n nodes from the ranking in h.order. Nodes for
+// which reserve returns true are selected first, visiting nodes in rank
+// order, and remaining slots are filled in rank order. The selected nodes
+// are returned in rank order.
+func (h *Hash[N]) selectRanked(n int, reserve func(ns *nodeScore[N]) bool) []N {
+	n = min(n, len(h.order))
+	chosen := make([]bool, len(h.order))
+	count := 0
+
+	for rank, i := range h.order {
+		if count == n {
+			break
+		}
+		if reserve(&h.nodes[i]) {
+			chosen[rank] = true
+			count++
+		}
+	}
+	for rank := range h.order {
+		if count == n {
+			break
+		}
+		if !chosen[rank] {
+			chosen[rank] = true
+			count++
+		}
+	}
+
+	nodes := make([]N, 0, n)
+	for rank, i := range h.order {
+		if chosen[rank] {
+			nodes = append(nodes, h.nodes[i].node)
+		}
+	}
+	return nodes
+}
+
+// identitySet returns the set of node identities in nodes.
+func (h *Hash[N]) identitySet(nodes []N) map[string]struct{} {
+	set := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		set[string(h.nodeID(node))] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,148 @@
+package rendezvous
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHashGetNConstrained(t *testing.T) {
+	hash := New[hashableString]("h1/a", "h1/b", "h2/a", "h2/b", "h3/a")
+
+	// Nodes are named host/disk; replicas must land on different hosts.
+	differentHost := func(selected []hashableString, candidate hashableString) bool {
+		host, _, _ := strings.Cut(string(candidate), "/")
+		for _, node := range selected {
+			if strings.HasPrefix(string(node), host+"/") {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, key := range sampleKeys {
+		got := hash.GetNConstrained(3, key, differentHost)
+		if len(got) != 3 {
+			t.Fatalf("key=%q - got %v, expected 3 nodes", key, got)
+		}
+		for i, node := range got {
+			if !differentHost(got[:i], node) {
+				t.Errorf("key=%q - got %v, expected replicas on distinct hosts", key, got)
+			}
+		}
+
+		// The result must be the full ranking, filtered greedily.
+		var expected []hashableString
+		for _, node := range hash.GetN(5, key) {
+			if len(expected) < 3 && differentHost(expected, node) {
+				expected = append(expected, node)
+			}
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+
+	if got := hash.GetNConstrained(5, "foo", differentHost); len(got) != 3 {
+		t.Errorf("got %v, expected only the 3 satisfiable replicas", got)
+	}
+	if got := New[hashableString]().GetNConstrained(3, "foo", differentHost); got != nil {
+		t.Errorf("got: %v, expected: nil", got)
+	}
+}
+
+func TestHashGetNDistinct(t *testing.T) {
+	hash := New[hashableString]("10.0.0.1:80", "10.0.0.1:81", "10.0.0.2:80", "10.0.0.2:81", "10.0.0.3:80")
+	sameHost := func(a, b hashableString) bool {
+		hostA, _, _ := strings.Cut(string(a), ":")
+		hostB, _, _ := strings.Cut(string(b), ":")
+		return hostA == hostB
+	}
+
+	for _, key := range sampleKeys {
+		got := hash.GetNDistinct(3, key, sameHost)
+		if len(got) != 3 || sameHost(got[0], got[1]) || sameHost(got[0], got[2]) || sameHost(got[1], got[2]) {
+			t.Errorf("key=%q - got %v, expected 3 nodes on distinct hosts", key, got)
+		}
+		if first, _ := hash.Get(key); got[0] != first {
+			t.Errorf("key=%q - got first node %v, expected %v", key, got[0], first)
+		}
+	}
+	if got := hash.GetNDistinct(5, "foo", sameHost); len(got) != 3 {
+		t.Errorf("got %v, expected one node per host", got)
+	}
+}
+
+func TestHashGetNZoned(t *testing.T) {
+	hash := New(
+		zonedNode{"a1", "a", 1}, zonedNode{"a2", "a", 1}, zonedNode{"a3", "a", 1},
+		zonedNode{"b1", "b", 1}, zonedNode{"c1", "c", 1}, zonedNode{"c2", "c", 1},
+	)
+
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		got := hash.GetNZoned(2, key, ZoneMinimums{"a": 1, "b": 1})
+
+		zones := map[string]int{}
+		for _, node := range got {
+			zones[node.zone]++
+		}
+		if len(got) != 2 || zones["a"] != 1 || zones["b"] != 1 {
+			t.Fatalf("key=%q - got %v, expected one replica each in zones a and b", key, got)
+		}
+
+		// The result must follow the unconstrained ranking.
+		ranking := hash.GetN(6, key)
+		if slices.Index(ranking, got[0]) > slices.Index(ranking, got[1]) {
+			t.Errorf("key=%q - got %v out of rank order %v", key, got, ranking)
+		}
+
+		// With no minimums, the policy reduces to GetN.
+		if got, expected := hash.GetNZoned(3, key, nil), hash.GetN(3, key); !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+
+	if got := hash.GetNZoned(3, "foo", ZoneMinimums{"c": 5}); len(got) != 3 {
+		t.Errorf("got %v, expected 3 nodes", got)
+	}
+}
+
+func TestHashGetNHinted(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+
+	for _, key := range sampleKeys {
+		ranking := hash.GetN(5, key)
+		last := ranking[4]
+
+		got := hash.GetNHinted(2, key, Hints[hashableString]{Required: []hashableString{last, "missing"}})
+		expected := []hashableString{ranking[0], last}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+
+		got = hash.GetNHinted(3, key, Hints[hashableString]{Required: []hashableString{ranking[1]}})
+		if !reflect.DeepEqual(got, ranking[:3]) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, ranking[:3])
+		}
+	}
+
+	// Zero-weight nodes all score zero, so only a preference or the identity
+	// tie-break can order them.
+	weighted := New(zonedNode{"a", "", 1}, zonedNode{"b", "", 0}, zonedNode{"c", "", 0})
+	ids := func(nodes []zonedNode) (ids []string) {
+		for _, node := range nodes {
+			ids = append(ids, node.id)
+		}
+		return ids
+	}
+	if got := ids(weighted.GetNHinted(3, "foo", Hints[zonedNode]{})); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("got: %v, expected: [a b c]", got)
+	}
+	preferC := Hints[zonedNode]{Preferred: []zonedNode{{"c", "", 0}}}
+	if got := ids(weighted.GetNHinted(3, "foo", preferC)); !reflect.DeepEqual(got, []string{"a", "c", "b"}) {
+		t.Errorf("got: %v, expected: [a c b]", got)
+	}
+}
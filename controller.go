@@ -0,0 +1,124 @@
+package rendezvous
+
+import (
+	"sync"
+	"time"
+)
+
+// WeightController adjusts node weights from observed latencies and errors.
+// It keeps an exponentially weighted moving average (EWMA) of each node's
+// latency and, on Update, scales the node's base weight by how much slower
+// it is than the fastest observed node. Traffic therefore shifts away from
+// slow nodes gradually and returns as they recover.
+//
+// Observe is safe for concurrent use. Update modifies the Hash, so it must
+// not run concurrently with other uses of it.
+type WeightController[N any] struct {
+	// ErrorPenalty is the latency recorded for a failed request.
+	ErrorPenalty time.Duration
+	// MinFactor is the smallest fraction of its base weight a node is scaled
+	// down to. Keeping it positive ensures slow nodes still see enough
+	// traffic to be observed recovering.
+	MinFactor float64
+
+	hash  *Hash[N]
+	alpha float64
+
+	mu    sync.Mutex
+	nodes map[string]*controlledNode[N]
+}
+
+// controlledNode is the state a WeightController keeps for a node.
+type controlledNode[N any] struct {
+	node N
+	// base is the node's weight before the controller first adjusted it.
+	base float64
+	// ewma is the moving average latency in seconds.
+	ewma float64
+}
+
+// NewWeightController returns a WeightController adjusting the weights of
+// hash's nodes. alpha, in (0, 1], is the weight given to each new sample:
+// higher values react faster, lower values smooth out noise. ErrorPenalty
+// defaults to one second and MinFactor to 0.1.
+func NewWeightController[N any](hash *Hash[N], alpha float64) *WeightController[N] {
+	return &WeightController[N]{
+		ErrorPenalty: time.Second,
+		MinFactor:    0.1,
+		hash:         hash,
+		alpha:        alpha,
+		nodes:        make(map[string]*controlledNode[N]),
+	}
+}
+
+// Observe records a request to node that took latency and failed with err,
+// if not nil.
+func (c *WeightController[N]) Observe(node N, latency time.Duration, err error) {
+	if err != nil && latency < c.ErrorPenalty {
+		latency = c.ErrorPenalty
+	}
+	sample := latency.Seconds()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := string(c.hash.nodeID(node))
+	state, ok := c.nodes[id]
+	if !ok {
+		c.nodes[id] = &controlledNode[N]{node: node, base: -1, ewma: sample}
+		return
+	}
+	state.ewma += c.alpha * (sample - state.ewma)
+}
+
+// Update sets the weight of every observed node to its base weight scaled
+// by its latency relative to the fastest observed node, as a single change
+// to the Hash. Nodes no longer in the Hash are forgotten.
+func (c *WeightController[N]) Update() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fastest := -1.0
+	for id, state := range c.nodes {
+		current, ok := c.hash.Weight(state.node)
+		if !ok {
+			delete(c.nodes, id)
+			continue
+		}
+		if state.base < 0 {
+			state.base = current
+		}
+		if fastest < 0 || state.ewma < fastest {
+			fastest = state.ewma
+		}
+	}
+
+	var changes Changeset[N]
+	changes.Actor = "weight-controller"
+	for _, state := range c.nodes {
+		factor := 1.0
+		if state.ewma > 0 {
+			factor = max(fastest/state.ewma, c.MinFactor)
+		}
+		weight := state.base * factor
+		if current, _ := c.hash.Weight(state.node); current != weight {
+			changes.Weights = append(changes.Weights, WeightChange[N]{Node: state.node, Weight: weight})
+		}
+	}
+	c.hash.Apply(changes)
+}
+
+// Reset restores the base weight of every observed node and forgets all
+// observations.
+func (c *WeightController[N]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changes := Changeset[N]{Actor: "weight-controller"}
+	for _, state := range c.nodes {
+		if _, ok := c.hash.Weight(state.node); ok && state.base >= 0 {
+			changes.Weights = append(changes.Weights, WeightChange[N]{Node: state.node, Weight: state.base})
+		}
+	}
+	c.hash.Apply(changes)
+	clear(c.nodes)
+}
@@ -0,0 +1,51 @@
+package rendezvous
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWeightController(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	hash.SetWeight("c", 2)
+	controller := NewWeightController(hash, 0.5)
+
+	for i := 0; i < 4; i++ {
+		controller.Observe("a", 10*time.Millisecond, nil)
+		controller.Observe("b", 40*time.Millisecond, nil)
+		controller.Observe("c", 10*time.Millisecond, nil)
+	}
+	controller.Update()
+	if weight, _ := hash.Weight("a"); weight != 1 {
+		t.Errorf("got weight %v for a, expected 1", weight)
+	}
+	if weight, _ := hash.Weight("b"); weight != 0.25 {
+		t.Errorf("got weight %v for b, expected 0.25 at a quarter of the speed", weight)
+	}
+	if weight, _ := hash.Weight("c"); weight != 2 {
+		t.Errorf("got weight %v for c, expected its base weight of 2", weight)
+	}
+
+	controller.Observe("a", 0, errors.New("timeout"))
+	controller.Update()
+	if weight, _ := hash.Weight("a"); weight >= 1 {
+		t.Errorf("got weight %v for a, expected an error to reduce it", weight)
+	}
+
+	for i := 0; i < 20; i++ {
+		controller.Observe("a", 10*time.Millisecond, nil)
+		controller.Observe("b", 10*time.Millisecond, nil)
+	}
+	controller.Update()
+	if weight, _ := hash.Weight("b"); weight < 0.99 {
+		t.Errorf("got weight %v for b, expected it to recover towards 1", weight)
+	}
+
+	hash.Remove("b")
+	controller.Update()
+	controller.Reset()
+	if weight, _ := hash.Weight("a"); weight != 1 {
+		t.Errorf("got weight %v for a after Reset, expected 1", weight)
+	}
+}
@@ -0,0 +1,73 @@
+package rendezvous
+
+import (
+	"cmp"
+	"slices"
+)
+
+// CostMatrix holds the cost of transferring data from nodes to clients,
+// such as egress pricing or round-trip time, for cost-aware selection.
+// Costs maps a client, such as the client's zone, to the cost of reading
+// from each node, keyed by node identity or, for nodes without an entry of
+// their own, by zone. Default is the cost for pairs without an entry.
+type CostMatrix struct {
+	Costs   map[string]map[string]float64
+	Default float64
+}
+
+// Cost returns the cost of client reading from the node with the given
+// identity and zone.
+func (m CostMatrix) Cost(client, id, zone string) float64 {
+	costs := m.Costs[client]
+	if cost, ok := costs[id]; ok {
+		return cost
+	}
+	if cost, ok := costs[zone]; ok {
+		return cost
+	}
+	return m.Default
+}
+
+// GetCost returns the node with the best blend of affinity and transfer
+// cost for client reading key. See GetNCost.
+func (h *Hash[N]) GetCost(key, client string, costs CostMatrix, blend float64) (N, bool) {
+	nodes := h.GetNCost(1, key, client, costs, blend)
+	if len(nodes) == 0 {
+		var zero N
+		return zero, false
+	}
+	return nodes[0], true
+}
+
+// GetNCost returns no more than n nodes for client reading key, trading
+// affinity for transfer cost. Each node's objective is its rank for key
+// plus blend times its cost from costs, and nodes are returned in
+// ascending objective order, ties going to the higher ranked node. Blend
+// is thus the number of ranks a unit of cost is worth: a blend of 0 returns
+// what GetN returns, and a large blend the cheapest nodes, so a small blend
+// moves bulk reads off the owner only for large savings.
+//
+// Selection is for reads: the owner is still the node Get returns.
+func (h *Hash[N]) GetNCost(n int, key, client string, costs CostMatrix, blend float64) []N {
+	if len(h.nodes) == 0 {
+		return nil
+	}
+	h.rank(h.keyBytes(key))
+
+	type candidate struct {
+		node      N
+		objective float64
+	}
+	candidates := make([]candidate, len(h.order))
+	for rank, i := range h.order {
+		ns := &h.nodes[i]
+		candidates[rank] = candidate{ns.node, float64(rank) + blend*costs.Cost(client, string(ns.id), ns.zone)}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return cmp.Compare(a.objective, b.objective) })
+
+	nodes := make([]N, min(n, len(candidates)))
+	for i := range nodes {
+		nodes[i] = candidates[i].node
+	}
+	return nodes
+}
@@ -0,0 +1,63 @@
+package rendezvous
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestHashGetNCost(t *testing.T) {
+	nodes := []zonedNode{
+		{"us-1", "us", 1}, {"us-2", "us", 1}, {"eu-1", "eu", 1}, {"eu-2", "eu", 1}, {"ap-1", "ap", 1},
+	}
+	hash := New(nodes...)
+	costs := CostMatrix{
+		Costs: map[string]map[string]float64{
+			"us": {"us": 0, "eu": 2, "eu-2": 1},
+		},
+		Default: 5,
+	}
+
+	if got := costs.Cost("us", "eu-1", "eu"); got != 2 {
+		t.Errorf("got: %v, expected the zone's cost 2", got)
+	}
+	if got := costs.Cost("us", "eu-2", "eu"); got != 1 {
+		t.Errorf("got: %v, expected the node's cost 1", got)
+	}
+	if got := costs.Cost("eu", "eu-2", "eu"); got != 5 {
+		t.Errorf("got: %v, expected the default cost 5", got)
+	}
+
+	for _, key := range sampleKeys {
+		ranked := hash.GetN(len(nodes), key)
+		if got := hash.GetNCost(3, key, "us", costs, 0); !reflect.DeepEqual(got, ranked[:3]) {
+			t.Errorf("key=%q - got: %v, expected GetN's %v with no blend", key, got, ranked[:3])
+		}
+
+		// With a large blend, the cheapest nodes come first, in rank order.
+		var cheapest []zonedNode
+		for _, node := range ranked {
+			if node.zone == "us" {
+				cheapest = append(cheapest, node)
+			}
+		}
+		if got := hash.GetNCost(2, key, "us", costs, 100); !reflect.DeepEqual(got, cheapest) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, cheapest)
+		}
+
+		// With a blend of 1, a node one unit of cost cheaper is worth a rank.
+		node, _ := hash.GetCost(key, "us", costs, 1)
+		objective := func(node zonedNode) float64 {
+			return float64(slices.Index(ranked, node)) + costs.Cost("us", node.id, node.zone)
+		}
+		for _, other := range ranked {
+			if objective(other) < objective(node) {
+				t.Errorf("key=%q - got: %v, expected: %v with a lower objective", key, node, other)
+			}
+		}
+	}
+
+	if _, ok := New[zonedNode]().GetCost("key", "us", costs, 1); ok {
+		t.Error("got a node from an empty Hash")
+	}
+}
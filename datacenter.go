@@ -0,0 +1,70 @@
+package rendezvous
+
+import (
+	"cmp"
+	"slices"
+)
+
+// GetLocal returns the highest scoring node for key in the caller's
+// datacenter, and the global primary Get returns, for writing locally and
+// replicating to the global owner across datacenters. Datacenters are the
+// zones reported by nodes implementing Zoned. When the primary is in zone,
+// local is the primary too, and if no node is in zone, local falls back to
+// the primary. It returns false if h is empty.
+func (h *Hash[N]) GetLocal(key, zone string) (local, global N, ok bool) {
+	if len(h.nodes) == 0 {
+		return local, global, false
+	}
+	h.scorer.begin(h.layout, h.keyBytes(key))
+	best, bestLocal := -1, -1
+	var bestScore, bestLocalScore float64
+	for i := range h.nodes {
+		ns := &h.nodes[i]
+		score := h.scoreWith(&h.scorer, ns)
+		if best < 0 || score > bestScore || (score == bestScore && h.compareTie(ns.id, h.nodes[best].id) < 0) {
+			best, bestScore = i, score
+		}
+		if ns.zone == zone && (bestLocal < 0 || score > bestLocalScore || (score == bestLocalScore && h.compareTie(ns.id, h.nodes[bestLocal].id) < 0)) {
+			bestLocal, bestLocalScore = i, score
+		}
+	}
+	if bestLocal < 0 {
+		bestLocal = best
+	}
+	return h.nodes[bestLocal].node, h.nodes[best].node, true
+}
+
+// GetNNear returns the nodes GetN returns for key, reordered for reads by a
+// client in zone: the nodes in zone first, then the others, each group in
+// descending score order. The replica set is GetN's, so ownership is
+// unchanged, but the first node is only the owner if it is in zone or no
+// node is, so writes should still go to the owner Get returns.
+func (h *Hash[N]) GetNNear(n int, key, zone string) []N {
+	return h.GetNByDistance(n, key, func(node N) int {
+		if nodeZone(node) == zone {
+			return 0
+		}
+		return 1
+	})
+}
+
+// GetNByDistance is GetNNear with nodes ordered by their distance from the
+// client, as reported by distance, such as 0 for the client's zone, 1 for
+// its region and 2 for the rest. Nodes at the same distance stay in
+// descending score order.
+func (h *Hash[N]) GetNByDistance(n int, key string, distance func(N) int) []N {
+	type replica struct {
+		node     N
+		distance int
+	}
+	nodes := h.GetN(n, key)
+	replicas := make([]replica, len(nodes))
+	for i, node := range nodes {
+		replicas[i] = replica{node, distance(node)}
+	}
+	slices.SortStableFunc(replicas, func(a, b replica) int { return cmp.Compare(a.distance, b.distance) })
+	for i, r := range replicas {
+		nodes[i] = r.node
+	}
+	return nodes
+}
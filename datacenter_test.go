@@ -0,0 +1,63 @@
+package rendezvous
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashGetLocal(t *testing.T) {
+	nodes := []zonedNode{
+		{"us-1", "us", 1}, {"us-2", "us", 1}, {"eu-1", "eu", 1}, {"eu-2", "eu", 2}, {"ap-1", "ap", 1},
+	}
+	hash := New(nodes...)
+
+	for _, key := range sampleKeys {
+		primary, _ := hash.Get(key)
+		var expected zonedNode
+		for _, node := range hash.GetN(len(nodes), key) {
+			if node.zone == "eu" {
+				expected = node
+				break
+			}
+		}
+		local, global, ok := hash.GetLocal(key, "eu")
+		if !ok || local != expected || global != primary {
+			t.Errorf("key=%q - got: %v and %v, expected: %v and %v", key, local, global, expected, primary)
+		}
+		if local, _, _ := hash.GetLocal(key, "sa"); local != primary {
+			t.Errorf("key=%q - got: %v, expected the primary %v for a datacenter without nodes", key, local, primary)
+		}
+	}
+
+	if _, _, ok := New[zonedNode]().GetLocal("key", "eu"); ok {
+		t.Errorf("got a node from an empty Hash")
+	}
+}
+
+func TestHashGetNNear(t *testing.T) {
+	nodes := []zonedNode{
+		{"us-1", "us", 1}, {"us-2", "us", 1}, {"eu-1", "eu", 1}, {"eu-2", "eu", 2}, {"ap-1", "ap", 1},
+	}
+	hash := New(nodes...)
+
+	for _, key := range sampleKeys {
+		replicas := hash.GetN(3, key)
+		var expected []zonedNode
+		for _, node := range replicas {
+			if node.zone == "eu" {
+				expected = append(expected, node)
+			}
+		}
+		for _, node := range replicas {
+			if node.zone != "eu" {
+				expected = append(expected, node)
+			}
+		}
+		if got := hash.GetNNear(3, key, "eu"); !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+		if got := hash.GetNNear(3, key, "sa"); !reflect.DeepEqual(got, replicas) {
+			t.Errorf("key=%q - got: %v, expected GetN's order %v", key, got, replicas)
+		}
+	}
+}
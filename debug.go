@@ -0,0 +1,78 @@
+package rendezvous
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// stringNodeLimit is the number of nodes String lists before truncating.
+const stringNodeLimit = 10
+
+// String returns a one-line summary of the Hash: its epoch, node count, and
+// the first few node identities with any non-default weights and zones.
+func (h *Hash[N]) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "rendezvous.Hash{epoch=%d nodes=%d [", h.epoch, len(h.nodes))
+	for i, ns := range h.nodes {
+		if i == stringNodeLimit {
+			fmt.Fprintf(&b, " ...+%d", len(h.nodes)-i)
+			break
+		}
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%q", ns.id)
+		if ns.zone != "" {
+			fmt.Fprintf(&b, "@%s", ns.zone)
+		}
+		if ns.weight != 1 {
+			fmt.Fprintf(&b, "(w=%g)", ns.weight)
+		}
+	}
+	b.WriteString("]}")
+	return b.String()
+}
+
+// Dump returns a multi-line description of the Hash's full state: its
+// epoch, zone weights, canaries, and every node with its zone, weight, and
+// expected share of keys.
+func (h *Hash[N]) Dump() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "epoch: %d\n", h.epoch)
+	fmt.Fprintf(&b, "nodes: %d\n", len(h.nodes))
+
+	if len(h.zoneWeights) > 0 {
+		b.WriteString("zone weights:")
+		for _, zone := range slices.Sorted(maps.Keys(h.zoneWeights)) {
+			fmt.Fprintf(&b, " %q=%g", zone, h.zoneWeights[zone])
+		}
+		b.WriteByte('\n')
+	}
+	if len(h.canaries) > 0 {
+		b.WriteString("canaries:")
+		for _, id := range slices.Sorted(maps.Keys(h.canaries)) {
+			fmt.Fprintf(&b, " %q=%g", id, h.canaries[id])
+		}
+		b.WriteByte('\n')
+	}
+
+	var total float64
+	for _, ns := range h.nodes {
+		total += ns.effective
+	}
+
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "IDENTITY\tZONE\tWEIGHT\tSHARE")
+	for _, ns := range h.nodes {
+		share := 0.0
+		if total > 0 {
+			share = ns.effective / total
+		}
+		fmt.Fprintf(tw, "%q\t%s\t%g\t%.4f\n", ns.id, ns.zone, ns.weight, share)
+	}
+	tw.Flush()
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package rendezvous
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHashString(t *testing.T) {
+	hash := New(zonedNode{"a", "us", 1}, zonedNode{"b", "", 2})
+	expected := `rendezvous.Hash{epoch=1 nodes=2 ["a"@us "b"(w=2)]}`
+	if got := fmt.Sprint(hash); got != expected {
+		t.Errorf("got: %s, expected: %s", got, expected)
+	}
+
+	many := New[hashableString]()
+	for i := 0; i < 12; i++ {
+		many.Add(hashableString(fmt.Sprintf("n%02d", i)))
+	}
+	if got := many.String(); !strings.HasSuffix(got, `"n09" ...+2]}`) {
+		t.Errorf("got: %s, expected a truncated node list", got)
+	}
+}
+
+func TestHashDump(t *testing.T) {
+	hash := New(zonedNode{"a", "us", 1}, zonedNode{"b", "eu", 3})
+	hash.SetZoneWeight("eu", 1)
+
+	dump := hash.Dump()
+	for _, want := range []string{"epoch: 2\n", `zone weights: "eu"=1`, `"a"       us    1       0.5000`, `"b"       eu    3       0.5000`} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump missing %q:\n%s", want, dump)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package rendezvous
+
+import (
+	"maps"
+	"sync"
+)
+
+// DualRun runs two placement implementations side by side during a
+// migration: it always answers with the primary's placement, and records
+// how often the shadow agrees. Placements are functions of the form of
+// Hash.Get, so either side may be a Hash or a wrapper around another
+// implementation, such as a legacy ketama ring.
+//
+// DualRun is safe for concurrent use if both placements are.
+type DualRun[N any] struct {
+	// Classify, if set, returns the class of a key, such as its tenant or
+	// prefix, so that agreement is reported per class. Keys are all in the
+	// class "" otherwise.
+	Classify func(key string) string
+	// OnDisagree, if set, is called with every key the two sides place
+	// differently and each side's placement.
+	OnDisagree func(key string, primary, shadow N)
+
+	primary, shadow func(key string) (N, bool)
+	equal           func(a, b N) bool
+
+	mu    sync.Mutex
+	stats map[string]DualRunStats
+}
+
+// DualRunStats counts the outcomes of a DualRun's lookups in one class.
+// Lookups that neither side placed are not counted.
+type DualRunStats struct {
+	// Agreed and Disagreed count lookups both sides placed, on the same
+	// node and on different nodes respectively.
+	Agreed, Disagreed uint64
+	// PrimaryOnly and ShadowOnly count lookups only one side placed.
+	PrimaryOnly, ShadowOnly uint64
+}
+
+// Agreement returns the fraction of counted lookups on which the two sides
+// agreed, or 1 if none were counted.
+func (s DualRunStats) Agreement() float64 {
+	total := s.Agreed + s.Disagreed + s.PrimaryOnly + s.ShadowOnly
+	if total == 0 {
+		return 1
+	}
+	return float64(s.Agreed) / float64(total)
+}
+
+// NewDualRun returns a DualRun answering with primary and comparing it
+// against shadow. equal reports whether two placements name the same node.
+func NewDualRun[N any](primary, shadow func(key string) (N, bool), equal func(a, b N) bool) *DualRun[N] {
+	return &DualRun[N]{primary: primary, shadow: shadow, equal: equal, stats: make(map[string]DualRunStats)}
+}
+
+// Get returns the primary's placement of key, after comparing it with the
+// shadow's.
+func (d *DualRun[N]) Get(key string) (N, bool) {
+	primary, primaryOK := d.primary(key)
+	shadow, shadowOK := d.shadow(key)
+
+	var class string
+	if d.Classify != nil {
+		class = d.Classify(key)
+	}
+	disagreed := false
+	d.mu.Lock()
+	stats := d.stats[class]
+	switch {
+	case primaryOK && shadowOK && d.equal(primary, shadow):
+		stats.Agreed++
+	case primaryOK && shadowOK:
+		stats.Disagreed++
+		disagreed = true
+	case primaryOK:
+		stats.PrimaryOnly++
+	case shadowOK:
+		stats.ShadowOnly++
+	}
+	d.stats[class] = stats
+	d.mu.Unlock()
+
+	if disagreed && d.OnDisagree != nil {
+		d.OnDisagree(key, primary, shadow)
+	}
+	return primary, primaryOK
+}
+
+// Stats returns the outcome counts of each key class.
+func (d *DualRun[N]) Stats() map[string]DualRunStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return maps.Clone(d.stats)
+}
+
+// Reset clears the outcome counts.
+func (d *DualRun[N]) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clear(d.stats)
+}
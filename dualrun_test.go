@@ -0,0 +1,52 @@
+package rendezvous
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDualRun(t *testing.T) {
+	primary := New[hashableString]("a", "b", "c")
+	legacy := New[hashableString]("a", "b", "c", "d")
+	dual := NewDualRun(primary.Get, legacy.Get, func(a, b hashableString) bool { return a == b })
+	dual.Classify = func(key string) string {
+		class, _, _ := strings.Cut(key, "-")
+		return class
+	}
+	var disagreements int
+	dual.OnDisagree = func(key string, primary, shadow hashableString) {
+		disagreements++
+		if shadow != "d" {
+			t.Errorf("key=%q - got shadow %v, expected only keys moved to d to disagree", key, shadow)
+		}
+	}
+
+	keys := []string{"user-1", "user-2", "user-3", "user-4", "order-1", "order-2", "order-3", "order-4"}
+	for _, key := range keys {
+		got, _ := dual.Get(key)
+		expected, _ := primary.Get(key)
+		if got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+
+	stats := dual.Stats()
+	var total uint64
+	for class, s := range stats {
+		if class != "user" && class != "order" {
+			t.Errorf("got unexpected class %q", class)
+		}
+		total += s.Agreed + s.Disagreed
+	}
+	if total != uint64(len(keys)) || int(stats["user"].Disagreed+stats["order"].Disagreed) != disagreements {
+		t.Errorf("got %+v and %d disagreements, expected every key counted", stats, disagreements)
+	}
+
+	dual.Reset()
+	if stats := dual.Stats(); len(stats) != 0 {
+		t.Errorf("got %+v, expected no stats after Reset", stats)
+	}
+	if agreement := (DualRunStats{Agreed: 3, Disagreed: 1}).Agreement(); agreement != 0.75 {
+		t.Errorf("got agreement %v, expected 0.75", agreement)
+	}
+}
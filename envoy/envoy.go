@@ -0,0 +1,278 @@
+// Package envoy converts the topology of a rendezvous.Hash into Envoy
+// cluster (CDS) and endpoint (EDS) resources, so the membership that drives
+// in-process routing can drive sidecar proxies too.
+//
+// Resources are plain structs following the JSON mapping of Envoy's v3 API.
+// WriteCDS and WriteEDS write them as discovery responses, which Envoy reads
+// from files named by a path_config_source and reloads as they change, so no
+// xDS server is needed:
+//
+//	dynamic_resources:
+//	  cds_config:
+//	    path_config_source: {path: /etc/envoy/cds.json}
+//	    resource_api_version: V3
+//
+// Nodes map to endpoints grouped by zone into localities. Node weights
+// become endpoint weights, and zone weights locality weights. Nodes with a
+// weight of zero or less, such as drained nodes, are reported as DRAINING,
+// which stops Envoy sending them new requests.
+package envoy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// Type URLs of the resources in discovery responses.
+const (
+	ClusterType  = "type.googleapis.com/envoy.config.cluster.v3.Cluster"
+	EndpointType = "type.googleapis.com/envoy.config.endpoint.v3.ClusterLoadAssignment"
+)
+
+// Cluster is an envoy.config.cluster.v3.Cluster.
+type Cluster struct {
+	Name             string           `json:"name"`
+	Type             string           `json:"type"`
+	ConnectTimeout   string           `json:"connect_timeout"`
+	LbPolicy         string           `json:"lb_policy"`
+	EdsClusterConfig EdsClusterConfig `json:"eds_cluster_config"`
+	CommonLbConfig   *CommonLbConfig  `json:"common_lb_config,omitempty"`
+}
+
+// EdsClusterConfig is an envoy.config.cluster.v3.Cluster.EdsClusterConfig.
+type EdsClusterConfig struct {
+	EdsConfig ConfigSource `json:"eds_config"`
+}
+
+// ConfigSource is an envoy.config.core.v3.ConfigSource that reads from a
+// file, or from the aggregated discovery service if Path is nil.
+type ConfigSource struct {
+	Path               *PathConfigSource `json:"path_config_source,omitempty"`
+	Ads                *struct{}         `json:"ads,omitempty"`
+	ResourceAPIVersion string            `json:"resource_api_version"`
+}
+
+// PathConfigSource is an envoy.config.core.v3.PathConfigSource.
+type PathConfigSource struct {
+	Path string `json:"path"`
+}
+
+// CommonLbConfig is an envoy.config.cluster.v3.Cluster.CommonLbConfig.
+type CommonLbConfig struct {
+	LocalityWeightedLbConfig *struct{} `json:"locality_weighted_lb_config,omitempty"`
+}
+
+// ClusterLoadAssignment is an
+// envoy.config.endpoint.v3.ClusterLoadAssignment.
+type ClusterLoadAssignment struct {
+	ClusterName string                `json:"cluster_name"`
+	Endpoints   []LocalityLbEndpoints `json:"endpoints"`
+}
+
+// LocalityLbEndpoints is an envoy.config.endpoint.v3.LocalityLbEndpoints.
+type LocalityLbEndpoints struct {
+	Locality            *Locality    `json:"locality,omitempty"`
+	LbEndpoints         []LbEndpoint `json:"lb_endpoints"`
+	LoadBalancingWeight uint32       `json:"load_balancing_weight,omitempty"`
+}
+
+// Locality is an envoy.config.core.v3.Locality.
+type Locality struct {
+	Zone string `json:"zone,omitempty"`
+}
+
+// LbEndpoint is an envoy.config.endpoint.v3.LbEndpoint.
+type LbEndpoint struct {
+	Endpoint            Endpoint `json:"endpoint"`
+	HealthStatus        string   `json:"health_status,omitempty"`
+	LoadBalancingWeight uint32   `json:"load_balancing_weight"`
+}
+
+// Endpoint is an envoy.config.endpoint.v3.Endpoint.
+type Endpoint struct {
+	Address  Address `json:"address"`
+	Hostname string  `json:"hostname,omitempty"`
+}
+
+// Address is an envoy.config.core.v3.Address.
+type Address struct {
+	SocketAddress SocketAddress `json:"socket_address"`
+}
+
+// SocketAddress is an envoy.config.core.v3.SocketAddress.
+type SocketAddress struct {
+	Address   string `json:"address"`
+	PortValue uint32 `json:"port_value"`
+}
+
+// Resources are the resources generated from a topology.
+type Resources struct {
+	// Version is the epoch of the Hash they were generated from.
+	Version   string
+	Cluster   Cluster
+	Endpoints ClusterLoadAssignment
+}
+
+// Generator generates the Envoy resources of a Hash's topology.
+type Generator[N any] struct {
+	// Cluster is the name of the cluster.
+	Cluster string
+	// Address returns the host and port of node.
+	Address func(node N) (host string, port uint32)
+	// WeightScale scales weights, which Envoy requires to be whole numbers
+	// of at least 1, before they are rounded. It defaults to 100.
+	WeightScale float64
+	// LbPolicy is the cluster's load balancing policy. It defaults to
+	// RING_HASH, as Envoy has no rendezvous hashing policy of its own.
+	LbPolicy string
+	// ConnectTimeout is the cluster's connect timeout. It defaults to one
+	// second.
+	ConnectTimeout time.Duration
+	// EDSPath, if set, is the file the cluster reads its endpoints from, as
+	// written by WriteEDS. Otherwise it reads them over ADS.
+	EDSPath string
+	// Locker, if set, is held while the Hash is read.
+	Locker sync.Locker
+
+	hash *rendezvous.Hash[N]
+}
+
+// NewGenerator returns a Generator of cluster's resources from hash, with
+// nodes addressed by address.
+func NewGenerator[N any](hash *rendezvous.Hash[N], cluster string, address func(N) (string, uint32)) *Generator[N] {
+	return &Generator[N]{
+		Cluster:        cluster,
+		Address:        address,
+		WeightScale:    100,
+		LbPolicy:       "RING_HASH",
+		ConnectTimeout: time.Second,
+		hash:           hash,
+	}
+}
+
+// Resources returns the resources of the Hash's current topology.
+// Localities are ordered by zone, and endpoints by node identity, so an
+// unchanged topology generates identical resources.
+func (g *Generator[N]) Resources() Resources {
+	if g.Locker != nil {
+		g.Locker.Lock()
+		defer g.Locker.Unlock()
+	}
+	zoneWeights := g.hash.ZoneWeights()
+	resources := Resources{
+		Version: strconv.FormatUint(g.hash.Epoch(), 10),
+		Cluster: Cluster{
+			Name:           g.Cluster,
+			Type:           "EDS",
+			ConnectTimeout: g.ConnectTimeout.String(),
+			LbPolicy:       g.LbPolicy,
+			EdsClusterConfig: EdsClusterConfig{EdsConfig: ConfigSource{
+				ResourceAPIVersion: "V3",
+			}},
+		},
+		Endpoints: ClusterLoadAssignment{ClusterName: g.Cluster, Endpoints: []LocalityLbEndpoints{}},
+	}
+	if g.EDSPath != "" {
+		resources.Cluster.EdsClusterConfig.EdsConfig.Path = &PathConfigSource{Path: g.EDSPath}
+	} else {
+		resources.Cluster.EdsClusterConfig.EdsConfig.Ads = &struct{}{}
+	}
+	if len(zoneWeights) > 0 {
+		resources.Cluster.CommonLbConfig = &CommonLbConfig{LocalityWeightedLbConfig: &struct{}{}}
+	}
+
+	localities := make(map[string]*LocalityLbEndpoints)
+	for _, node := range g.hash.Nodes() {
+		zone := ""
+		if zoned, ok := any(node).(rendezvous.Zoned); ok {
+			zone = zoned.Zone()
+		}
+		locality, ok := localities[zone]
+		if !ok {
+			locality = &LocalityLbEndpoints{LbEndpoints: []LbEndpoint{}}
+			if zone != "" {
+				locality.Locality = &Locality{Zone: zone}
+			}
+			if len(zoneWeights) > 0 {
+				weight, ok := zoneWeights[zone]
+				if !ok {
+					weight = 1
+				}
+				locality.LoadBalancingWeight = g.weight(weight)
+			}
+			localities[zone] = locality
+		}
+
+		host, port := g.Address(node)
+		weight, _ := g.hash.Weight(node)
+		endpoint := LbEndpoint{
+			Endpoint:            Endpoint{Address: Address{SocketAddress: SocketAddress{Address: host, PortValue: port}}},
+			LoadBalancingWeight: g.weight(weight),
+		}
+		if weight <= 0 {
+			endpoint.HealthStatus = "DRAINING"
+		}
+		locality.LbEndpoints = append(locality.LbEndpoints, endpoint)
+	}
+
+	zones := make([]string, 0, len(localities))
+	for zone := range localities {
+		zones = append(zones, zone)
+	}
+	slices.Sort(zones)
+	for _, zone := range zones {
+		resources.Endpoints.Endpoints = append(resources.Endpoints.Endpoints, *localities[zone])
+	}
+	return resources
+}
+
+// weight returns weight scaled and rounded to a valid Envoy weight.
+func (g *Generator[N]) weight(weight float64) uint32 {
+	return uint32(min(max(math.Round(weight*g.WeightScale), 1), math.MaxUint32))
+}
+
+// WriteCDS writes the cluster to w as a discovery response.
+func (r Resources) WriteCDS(w io.Writer) error {
+	return writeDiscoveryResponse(w, r.Version, ClusterType, r.Cluster)
+}
+
+// WriteEDS writes the cluster's endpoints to w as a discovery response.
+func (r Resources) WriteEDS(w io.Writer) error {
+	return writeDiscoveryResponse(w, r.Version, EndpointType, r.Endpoints)
+}
+
+// writeDiscoveryResponse writes resource to w as the only resource of a
+// discovery response, tagged with its type URL.
+func writeDiscoveryResponse(w io.Writer, version, typeURL string, resource any) error {
+	encoded, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	// Splice the type URL into the resource, as the JSON mapping of
+	// google.protobuf.Any requires.
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return err
+	}
+	fields["@type"], _ = json.Marshal(typeURL)
+
+	response := struct {
+		VersionInfo string                       `json:"version_info"`
+		TypeURL     string                       `json:"type_url"`
+		Resources   []map[string]json.RawMessage `json:"resources"`
+	}{version, typeURL, []map[string]json.RawMessage{fields}}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(response); err != nil {
+		return fmt.Errorf("envoy: encoding %s: %w", typeURL, err)
+	}
+	return nil
+}
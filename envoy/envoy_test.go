@@ -0,0 +1,85 @@
+package envoy
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+func TestResources(t *testing.T) {
+	a := rendezvous.NewConfigNode("a", 2, "us-east-1a")
+	b := rendezvous.NewConfigNode("b", 1.5, "us-east-1b")
+	c := rendezvous.NewConfigNode("c", 1, "us-east-1a")
+	hash := rendezvous.New(c, b, a)
+	hash.SetWeight(c, 0)
+	address := func(node rendezvous.ConfigNode) (string, uint32) {
+		return node.ID + ".internal", 8080
+	}
+	generator := NewGenerator(hash, "cache", address)
+
+	resources := generator.Resources()
+	if resources.Version != "2" {
+		t.Errorf("got version %q, expected the epoch 2", resources.Version)
+	}
+	if resources.Cluster.EdsClusterConfig.EdsConfig.Ads == nil || resources.Cluster.CommonLbConfig != nil {
+		t.Errorf("got cluster %+v, expected ADS and no locality weighting", resources.Cluster)
+	}
+	endpoints := resources.Endpoints.Endpoints
+	if len(endpoints) != 2 || endpoints[0].Locality.Zone != "us-east-1a" || endpoints[1].Locality.Zone != "us-east-1b" {
+		t.Fatalf("got %+v, expected localities us-east-1a and us-east-1b", endpoints)
+	}
+	if got := endpoints[0].LbEndpoints; len(got) != 2 ||
+		got[0].Endpoint.Address.SocketAddress.Address != "a.internal" || got[0].LoadBalancingWeight != 200 || got[0].HealthStatus != "" ||
+		got[1].Endpoint.Address.SocketAddress.Address != "c.internal" || got[1].LoadBalancingWeight != 1 || got[1].HealthStatus != "DRAINING" {
+		t.Errorf("got %+v, expected a with weight 200 and c draining", got)
+	}
+	if got := endpoints[1].LbEndpoints; len(got) != 1 || got[0].LoadBalancingWeight != 150 {
+		t.Errorf("got %+v, expected b with weight 150", got)
+	}
+
+	hash.SetZoneWeight("us-east-1b", 3)
+	generator.EDSPath = "/etc/envoy/eds.json"
+	resources = generator.Resources()
+	if resources.Cluster.CommonLbConfig == nil || resources.Cluster.EdsClusterConfig.EdsConfig.Path.Path != "/etc/envoy/eds.json" {
+		t.Errorf("got cluster %+v, expected locality weighting and a path config source", resources.Cluster)
+	}
+	if endpoints := resources.Endpoints.Endpoints; endpoints[0].LoadBalancingWeight != 100 || endpoints[1].LoadBalancingWeight != 300 {
+		t.Errorf("got locality weights %d and %d, expected 100 and 300", endpoints[0].LoadBalancingWeight, endpoints[1].LoadBalancingWeight)
+	}
+}
+
+func TestWriteDiscoveryResponse(t *testing.T) {
+	hash := rendezvous.New(rendezvous.NewConfigNode("a", 1, ""))
+	resources := NewGenerator(hash, "cache", func(node rendezvous.ConfigNode) (string, uint32) {
+		return "10.0.0.1", 80
+	}).Resources()
+
+	for name, test := range map[string]struct {
+		write   func(*bytes.Buffer) error
+		typeURL string
+		field   string
+	}{
+		"CDS": {func(b *bytes.Buffer) error { return resources.WriteCDS(b) }, ClusterType, "name"},
+		"EDS": {func(b *bytes.Buffer) error { return resources.WriteEDS(b) }, EndpointType, "cluster_name"},
+	} {
+		var b bytes.Buffer
+		if err := test.write(&b); err != nil {
+			t.Fatalf("%s - got error: %v", name, err)
+		}
+		var response struct {
+			VersionInfo string           `json:"version_info"`
+			Resources   []map[string]any `json:"resources"`
+		}
+		if err := json.Unmarshal(b.Bytes(), &response); err != nil {
+			t.Fatalf("%s - got error: %v", name, err)
+		}
+		if response.VersionInfo != resources.Version || len(response.Resources) != 1 {
+			t.Fatalf("%s - got: %s", name, b.String())
+		}
+		if got := response.Resources[0]; got["@type"] != test.typeURL || got[test.field] != "cache" {
+			t.Errorf("%s - got: %v, expected @type %s and %s cache", name, got, test.typeURL, test.field)
+		}
+	}
+}
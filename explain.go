@@ -0,0 +1,140 @@
+package rendezvous
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Candidate describes how a node scored for a key in an Explanation.
+type Candidate[N any] struct {
+	Node N
+	// ID is the node's identity.
+	ID   string
+	Zone string
+	// Weight is the node's configured weight, and Effective its weight
+	// after zone weighting.
+	Weight, Effective float64
+	// Raw is the node's hash for the key, and Score the score derived from
+	// it. When weights are uniform, Score is Raw.
+	Raw   uint64
+	Score float64
+	// Tiebreak is the word used to order nodes with equal scores, higher
+	// first, before falling back to identity order.
+	Tiebreak uint64
+	// Tied is true if the candidate's score equals that of a neighbour in
+	// the ranking, so that its position was decided by the tie-break.
+	Tied bool
+	// Rejected is true if the constraint of ExplainConstrained rejected
+	// the candidate.
+	Rejected bool
+	// Selected is true if the candidate is in the result.
+	Selected bool
+}
+
+// Explanation describes how the Hash placed a key: every node in rank
+// order with the inputs of its score, and the nodes selected.
+type Explanation[N any] struct {
+	Key    string
+	Epoch  uint64
+	Hasher Hasher
+	// Weighted is true if scores follow the logarithmic method because
+	// node weights differ.
+	Weighted bool
+	// Ranked lists every node in descending score order.
+	Ranked []Candidate[N]
+	// Selected lists the nodes chosen, in order. Get's choice is the first.
+	Selected []N
+}
+
+// Explain returns how Get places key: every node ranked with its score,
+// and the node chosen.
+func (h *Hash[N]) Explain(key string) Explanation[N] {
+	return h.explain(1, key, nil)
+}
+
+// ExplainConstrained returns how GetNConstrained places key: every node
+// ranked with its score, which candidates constraint rejected, and the
+// nodes chosen.
+func (h *Hash[N]) ExplainConstrained(n int, key string, constraint Constraint[N]) Explanation[N] {
+	return h.explain(n, key, constraint)
+}
+
+// explain builds the Explanation of selecting up to n nodes for key,
+// subject to constraint if it is not nil.
+func (h *Hash[N]) explain(n int, key string, constraint Constraint[N]) Explanation[N] {
+	explanation := Explanation[N]{
+		Key:      key,
+		Epoch:    h.epoch,
+		Hasher:   h.hasher,
+		Weighted: (!h.uniform || h.logScores) && h.scoreFunc == nil,
+	}
+	if len(h.nodes) == 0 {
+		return explanation
+	}
+
+	keyBytes := h.keyBytes(key)
+	h.rank(keyBytes)
+	h.scorer.begin(h.layout, keyBytes)
+	explanation.Ranked = make([]Candidate[N], len(h.order))
+	for rank, i := range h.order {
+		ns := &h.nodes[i]
+		raw, tiebreak := h.scorer.next(ns.id)
+		candidate := Candidate[N]{
+			Node:      ns.node,
+			ID:        string(ns.id),
+			Zone:      ns.zone,
+			Weight:    ns.weight,
+			Effective: ns.effective,
+			Raw:       raw,
+			Score:     ns.score,
+			Tiebreak:  tiebreak,
+		}
+		if rank > 0 && explanation.Ranked[rank-1].Score == candidate.Score {
+			explanation.Ranked[rank-1].Tied = true
+			candidate.Tied = true
+		}
+		if len(explanation.Selected) < n {
+			if constraint != nil && !constraint(explanation.Selected, ns.node) {
+				candidate.Rejected = true
+			} else {
+				candidate.Selected = true
+				explanation.Selected = append(explanation.Selected, ns.node)
+			}
+		}
+		explanation.Ranked[rank] = candidate
+	}
+	return explanation
+}
+
+// String returns a table of the ranking, for support and debugging.
+func (e Explanation[N]) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "key %q at epoch %d", e.Key, e.Epoch)
+	if e.Weighted {
+		b.WriteString(", weighted")
+	}
+	b.WriteByte('\n')
+	if len(e.Ranked) == 0 {
+		b.WriteString("no nodes\n")
+		return b.String()
+	}
+
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tIDENTITY\tZONE\tWEIGHT\tRAW\tSCORE\tNOTE")
+	for rank, c := range e.Ranked {
+		var notes []string
+		if c.Selected {
+			notes = append(notes, "selected")
+		}
+		if c.Rejected {
+			notes = append(notes, "rejected by constraint")
+		}
+		if c.Tied {
+			notes = append(notes, fmt.Sprintf("tied, tiebreak %#x", c.Tiebreak))
+		}
+		fmt.Fprintf(tw, "%d\t%q\t%s\t%g\t%#x\t%g\t%s\n", rank+1, c.ID, c.Zone, c.Weight, c.Raw, c.Score, strings.Join(notes, ", "))
+	}
+	tw.Flush()
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package rendezvous
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashExplain(t *testing.T) {
+	hash := New(NewConfigNode("a", 1, "east"), NewConfigNode("b", 1, "east"), NewConfigNode("c", 2, "west"))
+	for _, key := range sampleKeys {
+		explanation := hash.Explain(key)
+		expected, _ := hash.Get(key)
+		if len(explanation.Selected) != 1 || explanation.Selected[0].ID != expected.ID || !explanation.Ranked[0].Selected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, explanation.Selected, expected)
+		}
+		for i := 1; i < len(explanation.Ranked); i++ {
+			if explanation.Ranked[i].Score > explanation.Ranked[i-1].Score {
+				t.Errorf("key=%q - got ranking out of order: %v", key, explanation)
+			}
+		}
+	}
+
+	differentZone := func(selected []ConfigNode, candidate ConfigNode) bool {
+		for _, node := range selected {
+			if node.Zone() == candidate.Zone() {
+				return false
+			}
+		}
+		return true
+	}
+	explanation := hash.ExplainConstrained(2, "foo", differentZone)
+	expected := hash.GetNConstrained(2, "foo", differentZone)
+	if len(explanation.Selected) != 2 || explanation.Selected[0].ID != expected[0].ID || explanation.Selected[1].ID != expected[1].ID {
+		t.Errorf("got: %v, expected: %v", explanation.Selected, expected)
+	}
+	if explanation.Ranked[1].Rejected != (explanation.Ranked[0].Zone == explanation.Ranked[1].Zone) {
+		t.Errorf("got %v, expected the second node rejected only if in the first's zone", explanation)
+	}
+	if text := explanation.String(); !strings.Contains(text, "selected") || !strings.Contains(text, `"c"`) {
+		t.Errorf("got %q, expected a table of the ranking", text)
+	}
+}
+
+func TestHashExplainTies(t *testing.T) {
+	// Nodes sharing an identity always tie.
+	hash := NewFunc(func(n zonedNode) []byte { return []byte(n.id) }, zonedNode{id: "x"}, zonedNode{id: "x", zone: "other"})
+	explanation := hash.Explain("key")
+	if len(explanation.Ranked) != 2 || !explanation.Ranked[0].Tied || !explanation.Ranked[1].Tied {
+		t.Errorf("got %v, expected the duplicate nodes tied", explanation)
+	}
+}
@@ -0,0 +1,129 @@
+package rendezvous
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+)
+
+// Share is the sampled fraction of the keyspace owned by a node.
+type Share[N any] struct {
+	Node N
+	// Keys is the number of sampled keys owned by Node.
+	Keys int
+	// Fraction is Keys divided by the total number of samples.
+	Fraction float64
+	// Expected is the fraction Node would own if keys were split exactly
+	// in proportion to node weights.
+	Expected float64
+}
+
+// Deviation returns how far the share is from its expected value, relative
+// to the expected value. A Deviation of 0.5 means the node owns 50% more of
+// the keyspace than it should.
+func (s Share[N]) Deviation() float64 {
+	if s.Expected == 0 {
+		return 0
+	}
+	return (s.Fraction - s.Expected) / s.Expected
+}
+
+// SampleShares estimates each node's share of the keyspace by looking up
+// samples deterministic keys. Shares are returned in node order.
+func (h *Hash[N]) SampleShares(samples int) []Share[N] {
+	if len(h.nodes) == 0 || samples <= 0 {
+		return nil
+	}
+
+	counts := make(map[string]int, len(h.nodes))
+	for i := 0; i < samples; i++ {
+		if i, _ := h.top(unsafeBytes("sample-" + strconv.Itoa(i))); i >= 0 {
+			counts[string(h.nodes[i].id)]++
+		}
+	}
+
+	var total float64
+	for _, ns := range h.nodes {
+		total += ns.effective
+	}
+
+	shares := make([]Share[N], len(h.nodes))
+	for i, ns := range h.nodes {
+		keys := counts[string(ns.id)]
+		shares[i] = Share[N]{
+			Node:     ns.node,
+			Keys:     keys,
+			Fraction: float64(keys) / float64(samples),
+		}
+		if total > 0 {
+			shares[i].Expected = ns.effective / total
+		}
+	}
+	return shares
+}
+
+// WriteDOT renders shares as a Graphviz DOT graph. Each node hangs off a
+// shared keyspace vertex, with edge width proportional to its share and
+// nodes shaded red as they deviate from their expected share.
+func WriteDOT[N any](w io.Writer, shares []Share[N]) error {
+	if _, err := fmt.Fprintln(w, "digraph keyspace {\n\trankdir=LR;\n\tkeyspace [shape=circle];"); err != nil {
+		return err
+	}
+	for _, share := range shares {
+		label := fmt.Sprint(share.Node)
+		_, err := fmt.Fprintf(w, "\t%s [shape=box, style=filled, fillcolor=%q, label=%s];\n\tkeyspace -> %s [label=\"%.1f%%\", penwidth=%.2f];\n",
+			strconv.Quote(label), deviationColor(share.Deviation()), strconv.Quote(fmt.Sprintf("%s\n%+.1f%%", label, share.Deviation()*100)),
+			strconv.Quote(label), share.Fraction*100, 1+share.Fraction*20)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// deviationColor maps a relative deviation to a fill color, from white at
+// no deviation to full red at 50% or more in either direction.
+func deviationColor(deviation float64) string {
+	if deviation < 0 {
+		deviation = -deviation
+	}
+	intensity := min(deviation/0.5, 1)
+	other := 255 - int(intensity*255)
+	return fmt.Sprintf("#ff%02x%02x", other, other)
+}
+
+var htmlTemplate = template.Must(template.New("keyspace").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.2f%%", f*100) },
+	"signed":  func(f float64) string { return fmt.Sprintf("%+.2f%%", f*100) },
+	"width":   func(f float64) string { return fmt.Sprintf("%.1f", f*100) },
+	"color":   deviationColor,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Keyspace ownership</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { padding: 0.3em 0.8em; text-align: left; }
+.bar { height: 1em; background: #4a7bd0; }
+</style>
+</head>
+<body>
+<h1>Keyspace ownership</h1>
+<table>
+<tr><th>Node</th><th>Keys</th><th>Share</th><th>Deviation</th><th></th></tr>
+{{- range .}}
+<tr style="background: {{color .Deviation}}"><td>{{.Node}}</td><td>{{.Keys}}</td><td>{{percent .Fraction}}</td><td>{{signed .Deviation}}</td><td><div class="bar" style="width: {{width .Fraction}}em"></div></td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML renders shares as a standalone HTML page.
+func WriteHTML[N any](w io.Writer, shares []Share[N]) error {
+	return htmlTemplate.Execute(w, shares)
+}
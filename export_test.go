@@ -0,0 +1,50 @@
+package rendezvous
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHashSampleShares(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	shares := hash.SampleShares(1000)
+	if len(shares) != 4 {
+		t.Fatalf("got %d shares, expected 4", len(shares))
+	}
+
+	total := 0
+	for _, share := range shares {
+		total += share.Keys
+		if share.Expected != 0.25 {
+			t.Errorf("node=%v - got expected share %v, expected 0.25", share.Node, share.Expected)
+		}
+	}
+	if total != 1000 {
+		t.Errorf("got %d sampled keys, expected 1000", total)
+	}
+
+	if shares := New[hashableString]().SampleShares(1000); shares != nil {
+		t.Errorf("got: %v, expected: nil", shares)
+	}
+}
+
+func TestWriteDOTAndHTML(t *testing.T) {
+	shares := New[hashableString]("a", "b").SampleShares(100)
+
+	var dot bytes.Buffer
+	if err := WriteDOT(&dot, shares); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.HasPrefix(dot.String(), "digraph keyspace {") || !strings.Contains(dot.String(), `keyspace -> "a"`) {
+		t.Errorf("unexpected DOT output:\n%s", dot.String())
+	}
+
+	var html bytes.Buffer
+	if err := WriteHTML(&html, shares); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !strings.Contains(html.String(), "<td>a</td>") || !strings.Contains(html.String(), "<td>b</td>") {
+		t.Errorf("unexpected HTML output:\n%s", html.String())
+	}
+}
@@ -0,0 +1,65 @@
+package rendezvous
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoNodes is returned by lookups that need at least one node when the
+// Hash is empty.
+var ErrNoNodes = errors.New("rendezvous: no nodes")
+
+// Attempt records a failed call made by GetWithFallback.
+type Attempt[N any] struct {
+	// Rank is the node's position in the key's ranking, starting at 0.
+	Rank int
+	Node N
+	Err  error
+}
+
+// FallbackError is returned by GetWithFallback when every attempt failed.
+// It unwraps to the errors of the individual attempts.
+type FallbackError[N any] struct {
+	Attempts []Attempt[N]
+}
+
+func (e *FallbackError[N]) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "rendezvous: all %d attempts failed", len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		fmt.Fprintf(&b, "; rank %d (%v): %v", attempt.Rank, attempt.Node, attempt.Err)
+	}
+	return b.String()
+}
+
+func (e *FallbackError[N]) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		errs[i] = attempt.Err
+	}
+	return errs
+}
+
+// GetWithFallback calls fn with the nodes for key in descending score order
+// until a call succeeds, making at most maxAttempts calls. It returns nil
+// once fn succeeds, ErrNoNodes if the Hash is empty, and otherwise a
+// *FallbackError recording the rank, node and error of every failed call.
+// Fewer than maxAttempts calls are made if the Hash has fewer nodes, and a
+// maxAttempts less than 1 is treated as 1.
+func (h *Hash[N]) GetWithFallback(key string, fn func(N) error, maxAttempts int) error {
+	nodes := h.GetN(max(maxAttempts, 1), key)
+	if len(nodes) == 0 {
+		return ErrNoNodes
+	}
+
+	var failed FallbackError[N]
+	for rank, node := range nodes {
+		err := fn(node)
+		if err == nil {
+			return nil
+		}
+		failed.Attempts = append(failed.Attempts, Attempt[N]{Rank: rank, Node: node, Err: err})
+	}
+	return &failed
+}
@@ -0,0 +1,40 @@
+package rendezvous
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestHashGetWithFallback(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	if err := New[hashableString]().GetWithFallback("key", nil, 3); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("got error %v, expected ErrNoNodes", err)
+	}
+
+	for _, key := range sampleKeys {
+		ranked := hash.GetN(4, key)
+		down := errors.New("down")
+
+		var called []hashableString
+		err := hash.GetWithFallback(key, func(n hashableString) error {
+			called = append(called, n)
+			if n == ranked[0] || n == ranked[1] {
+				return down
+			}
+			return nil
+		}, 3)
+		if err != nil || !slices.Equal(called, ranked[:3]) {
+			t.Errorf("key=%q - got: (%v, %v), expected: (%v, nil)", key, called, err, ranked[:3])
+		}
+
+		err = hash.GetWithFallback(key, func(hashableString) error { return down }, 2)
+		var failed *FallbackError[hashableString]
+		if !errors.As(err, &failed) || !errors.Is(err, down) {
+			t.Fatalf("key=%q - got error %v, expected a FallbackError", key, err)
+		}
+		if len(failed.Attempts) != 2 || failed.Attempts[1].Rank != 1 || failed.Attempts[1].Node != ranked[1] {
+			t.Errorf("key=%q - got attempts %+v, expected ranks 0 and 1", key, failed.Attempts)
+		}
+	}
+}
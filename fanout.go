@@ -0,0 +1,107 @@
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// FanOut calls fn concurrently with each of the no more than n nodes for
+// key, as returned by GetN, and waits for every call to return. If n is
+// zero or less it calls nothing and returns nil. Otherwise it returns
+// ErrNoNodes if the Hash is empty, and the errors of the failed calls joined
+// together, each prefixed with its node, or nil if every call succeeded.
+func (h *Hash[N]) FanOut(ctx context.Context, n int, key string, fn func(context.Context, N) error) error {
+	if n <= 0 {
+		return nil
+	}
+	nodes := h.GetN(n, key)
+	if len(nodes) == 0 {
+		return ErrNoNodes
+	}
+
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx, node); err != nil {
+				errs[i] = fmt.Errorf("%v: %w", node, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// ErrNoQuorum is returned by Quorum when too few calls succeeded.
+var ErrNoQuorum = errors.New("rendezvous: quorum not reached")
+
+// Quorum calls fn concurrently with each of the no more than n nodes for key,
+// as returned by GetN, and returns as soon as k calls have succeeded,
+// canceling the context passed to the remaining calls. It returns the nodes
+// whose calls succeeded before it returned, in descending score order.
+//
+// If k calls can no longer succeed, Quorum returns early with an error
+// wrapping ErrNoQuorum and the errors of the failed calls. Calls still
+// running when Quorum returns are not waited for, but their context is
+// canceled.
+//
+// No call is made unless 0 < k <= n: otherwise Quorum returns an error. It
+// also returns ErrNoNodes if the Hash is empty, and an error wrapping
+// ErrNoQuorum if it has fewer than k nodes.
+func (h *Hash[N]) Quorum(ctx context.Context, n, k int, key string, fn func(context.Context, N) error) ([]N, error) {
+	if k <= 0 || k > n {
+		return nil, fmt.Errorf("rendezvous: Quorum needs 0 < k <= n, got k=%d and n=%d", k, n)
+	}
+	nodes := h.GetN(n, key)
+	if len(nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+	if k > len(nodes) {
+		return nil, fmt.Errorf("%w: %d nodes cannot acknowledge %d", ErrNoQuorum, len(nodes), k)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		rank int
+		err  error
+	}
+	results := make(chan result, len(nodes))
+	for rank, node := range nodes {
+		go func() {
+			results <- result{rank, fn(ctx, node)}
+		}()
+	}
+
+	acked := make([]bool, len(nodes))
+	acks := 0
+	var errs []error
+	for range nodes {
+		if acks >= k || len(nodes)-len(errs) < k {
+			break
+		}
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", nodes[r.rank], r.err))
+			continue
+		}
+		acked[r.rank] = true
+		acks++
+	}
+
+	succeeded := make([]N, 0, acks)
+	for rank, ok := range acked {
+		if ok {
+			succeeded = append(succeeded, nodes[rank])
+		}
+	}
+	if acks < k {
+		return succeeded, fmt.Errorf("%w: %d of %d acknowledged: %w", ErrNoQuorum, acks, k, errors.Join(errs...))
+	}
+	return succeeded, nil
+}
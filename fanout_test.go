@@ -0,0 +1,100 @@
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestHashFanOut(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	ctx := context.Background()
+	if err := New[hashableString]().FanOut(ctx, 2, "key", nil); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("got error %v, expected ErrNoNodes", err)
+	}
+	for _, n := range []int{0, -1} {
+		if err := hash.FanOut(ctx, n, "key", nil); err != nil {
+			t.Errorf("n=%d - got error %v, expected none", n, err)
+		}
+	}
+
+	key := sampleKeys[0]
+	ranked := hash.GetN(3, key)
+	down := errors.New("down")
+
+	var mu sync.Mutex
+	var called []hashableString
+	err := hash.FanOut(ctx, 3, key, func(_ context.Context, n hashableString) error {
+		mu.Lock()
+		called = append(called, n)
+		mu.Unlock()
+		if n == ranked[1] {
+			return down
+		}
+		return nil
+	})
+	slices.Sort(called)
+	if expected := slices.Sorted(slices.Values(ranked)); !slices.Equal(called, expected) {
+		t.Errorf("key=%q - got calls to %v, expected: %v", key, called, expected)
+	}
+	if !errors.Is(err, down) || err.Error() != string(ranked[1])+": down" {
+		t.Errorf("key=%q - got error %v, expected %v: down", key, err, ranked[1])
+	}
+
+	if err := hash.FanOut(ctx, 3, key, func(context.Context, hashableString) error { return nil }); err != nil {
+		t.Errorf("got error %v, expected none", err)
+	}
+}
+
+func TestHashQuorum(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	ctx := context.Background()
+	called := func(context.Context, hashableString) error {
+		t.Error("got a call, expected none for invalid arguments")
+		return nil
+	}
+	for _, args := range [][2]int{{3, 0}, {3, -1}, {3, 4}, {0, 1}, {-1, -1}} {
+		if _, err := hash.Quorum(ctx, args[0], args[1], "key", called); err == nil || errors.Is(err, ErrNoNodes) {
+			t.Errorf("n=%d k=%d - got error %v, expected an argument error", args[0], args[1], err)
+		}
+	}
+	if _, err := New[hashableString]().Quorum(ctx, 3, 2, "key", called); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("got error %v, expected ErrNoNodes", err)
+	}
+	if _, err := New[hashableString]("a").Quorum(ctx, 3, 2, "key", called); !errors.Is(err, ErrNoQuorum) {
+		t.Errorf("got error %v, expected ErrNoQuorum", err)
+	}
+	key := sampleKeys[0]
+	ranked := hash.GetN(3, key)
+	down := errors.New("down")
+
+	// The first ranked node blocks until its context is canceled, which
+	// only happens once the other two acknowledge.
+	succeeded, err := hash.Quorum(ctx, 3, 2, key, func(ctx context.Context, n hashableString) error {
+		if n == ranked[0] {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || !slices.Equal(succeeded, ranked[1:]) {
+		t.Errorf("key=%q - got: (%v, %v), expected: (%v, nil)", key, succeeded, err, ranked[1:])
+	}
+
+	// Two failures make the quorum unreachable, so Quorum returns without
+	// waiting for the third call.
+	release := make(chan struct{})
+	defer close(release)
+	succeeded, err = hash.Quorum(ctx, 3, 2, key, func(_ context.Context, n hashableString) error {
+		if n == ranked[2] {
+			<-release
+			return nil
+		}
+		return down
+	})
+	if !errors.Is(err, ErrNoQuorum) || !errors.Is(err, down) || len(succeeded) != 0 {
+		t.Errorf("key=%q - got: (%v, %v), expected no nodes and ErrNoQuorum", key, succeeded, err)
+	}
+}
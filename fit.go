@@ -0,0 +1,39 @@
+package rendezvous
+
+// Resources are amounts of compute resources, such as those a request
+// requires or those a node has free.
+type Resources struct {
+	GPU int
+	// CPU is in cores, and may be fractional.
+	CPU float64
+	// Memory is in bytes.
+	Memory int64
+}
+
+// Fits reports whether r fits within free.
+func (r Resources) Fits(free Resources) bool {
+	return r.GPU <= free.GPU && r.CPU <= free.CPU && r.Memory <= free.Memory
+}
+
+// GetFit returns the highest scoring node for key with room for required,
+// as reported by free, which returns a node's free resources from a
+// snapshot of the nodes' capacity. A key is placed on its usual node
+// whenever that node fits, and otherwise on the same fallback for as long
+// as the snapshot is unchanged. It returns false if no node fits.
+func (h *Hash[N]) GetFit(key string, required Resources, free func(N) Resources) (N, bool) {
+	nodes := h.GetNFit(1, key, required, free)
+	if len(nodes) == 0 {
+		var zero N
+		return zero, false
+	}
+	return nodes[0], true
+}
+
+// GetNFit returns no more than n nodes for key with room for required, as
+// reported by free, in descending score order. free is called for nodes in
+// descending score order until n fit.
+func (h *Hash[N]) GetNFit(n int, key string, required Resources, free func(N) Resources) []N {
+	return h.GetNConstrained(n, key, func(_ []N, candidate N) bool {
+		return required.Fits(free(candidate))
+	})
+}
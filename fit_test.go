@@ -0,0 +1,43 @@
+package rendezvous
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashGetFit(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	free := map[hashableString]Resources{
+		"a": {GPU: 0, CPU: 16, Memory: 64 << 30},
+		"b": {GPU: 1, CPU: 8, Memory: 32 << 30},
+		"c": {GPU: 4, CPU: 32, Memory: 256 << 30},
+		"d": {GPU: 2, CPU: 2, Memory: 8 << 30},
+	}
+	snapshot := func(node hashableString) Resources { return free[node] }
+
+	for _, key := range sampleKeys {
+		first, _ := hash.Get(key)
+		// Requests that fit everywhere are placed as Get places them.
+		if got, _ := hash.GetFit(key, Resources{CPU: 1}, snapshot); got != first {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, first)
+		}
+
+		required := Resources{GPU: 2, CPU: 2, Memory: 8 << 30}
+		var expected []hashableString
+		for _, node := range hash.GetN(4, key) {
+			if required.Fits(free[node]) {
+				expected = append(expected, node)
+			}
+		}
+		if got := hash.GetNFit(4, key, required, snapshot); !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+		if got, ok := hash.GetFit(key, required, snapshot); !ok || got != expected[0] {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected[0])
+		}
+
+		if got, ok := hash.GetFit(key, Resources{GPU: 8}, snapshot); ok {
+			t.Errorf("key=%q - got: %v, expected no node to fit", key, got)
+		}
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/beam-cloud/rendezvous
 
-go 1.22
+go 1.23
@@ -0,0 +1,138 @@
+package rendezvous
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// Handoff describes a node that was removed from a Hash or drained, its
+// weight set to zero or less, so that a service holding data for it can
+// push the affected keys to their new owners before decommissioning it.
+type Handoff[N any] struct {
+	Node N
+	// Epoch is the epoch of the Hash once the node was removed or drained.
+	Epoch uint64
+	// Drained is true if the node is still in the Hash, drained, rather
+	// than removed.
+	Drained bool
+	// Transfers reports whether the node owned key before it was removed or
+	// drained, and if so returns the key's new owner. It compares the
+	// topologies before and after the change, whatever changes since, and
+	// is safe for concurrent use.
+	Transfers func(key string) (to N, ok bool)
+}
+
+// Handoffs watches a Hash for nodes that are removed or drained, and
+// reports a Handoff for each. Like Connections, it compares topologies
+// when Update finds the Hash's epoch changed, so a node removed and added
+// back between Updates is not reported.
+//
+// Handoffs is safe for concurrent use. Set Locker if the Hash is modified
+// concurrently with it.
+type Handoffs[N any] struct {
+	// Locker, if set, is held while the Hash is read.
+	Locker sync.Locker
+	// OnHandoff, if set, is called by Update for each handoff.
+	OnHandoff func(Handoff[N])
+
+	hash *Hash[N]
+
+	mu sync.Mutex
+	// last is a copy of the Hash as of the last Update.
+	last *Hash[N]
+}
+
+// NewHandoffs returns Handoffs watching hash, from its current topology.
+func NewHandoffs[N any](hash *Hash[N]) *Handoffs[N] {
+	return &Handoffs[N]{hash: hash, last: hash.clone()}
+}
+
+// Update compares the Hash's current topology with the one of the last
+// Update, calls OnHandoff for each node removed or drained since, and
+// returns their handoffs. Nodes that were already drained are not reported
+// again when they are removed.
+func (h *Handoffs[N]) Update() []Handoff[N] {
+	h.mu.Lock()
+	h.lock()
+	if h.hash.Epoch() == h.last.Epoch() {
+		h.unlock()
+		h.mu.Unlock()
+		return nil
+	}
+	prev, next := h.last, h.hash.clone()
+	h.unlock()
+	h.last = next.clone()
+	h.mu.Unlock()
+
+	// prev and next are shared by the handoffs' Transfers, which score
+	// with them and so must take turns.
+	var mu sync.Mutex
+	var handoffs []Handoff[N]
+	for _, ns := range prev.nodes {
+		if ns.weight <= 0 {
+			continue
+		}
+		drained := false
+		if i := next.find(ns.id); i >= 0 {
+			if next.nodes[i].weight > 0 {
+				continue
+			}
+			drained = true
+		}
+		node, id := ns.node, ns.id
+		handoffs = append(handoffs, Handoff[N]{
+			Node:    node,
+			Epoch:   next.epoch,
+			Drained: drained,
+			Transfers: func(key string) (N, bool) {
+				mu.Lock()
+				defer mu.Unlock()
+				var zero N
+				if !prev.Owns(node, key) {
+					return zero, false
+				}
+				to, ok := next.Get(key)
+				if !ok || bytes.Equal(next.nodeID(to), id) {
+					return zero, false
+				}
+				return to, true
+			},
+		})
+	}
+
+	if h.OnHandoff != nil {
+		for _, handoff := range handoffs {
+			h.OnHandoff(handoff)
+		}
+	}
+	return handoffs
+}
+
+// Run calls Update every interval until ctx is done, and returns ctx's
+// error.
+func (h *Handoffs[N]) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			h.Update()
+		}
+	}
+}
+
+func (h *Handoffs[N]) lock() {
+	if h.Locker != nil {
+		h.Locker.Lock()
+	}
+}
+
+func (h *Handoffs[N]) unlock() {
+	if h.Locker != nil {
+		h.Locker.Unlock()
+	}
+}
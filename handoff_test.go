@@ -0,0 +1,53 @@
+package rendezvous
+
+import "testing"
+
+func TestHandoffs(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	handoffs := NewHandoffs(hash)
+	if got := handoffs.Update(); got != nil {
+		t.Errorf("got: %v, expected no handoffs without a change", got)
+	}
+
+	before := make(map[string]hashableString)
+	for _, key := range sampleKeys {
+		before[key], _ = hash.Get(key)
+	}
+	var reported []Handoff[hashableString]
+	handoffs.OnHandoff = func(handoff Handoff[hashableString]) { reported = append(reported, handoff) }
+	hash.Remove("c")
+	hash.SetWeight("d", 0)
+
+	got := handoffs.Update()
+	if len(got) != 2 || len(reported) != 2 {
+		t.Fatalf("got %d handoffs, %d reported, expected 2", len(got), len(reported))
+	}
+	for _, handoff := range got {
+		if expected := handoff.Node == "d"; handoff.Drained != expected {
+			t.Errorf("node=%v - got drained: %v, expected: %v", handoff.Node, handoff.Drained, expected)
+		}
+		if handoff.Epoch != hash.Epoch() {
+			t.Errorf("node=%v - got epoch: %d, expected: %d", handoff.Node, handoff.Epoch, hash.Epoch())
+		}
+	}
+
+	// Transfers compares the topologies of the Update, whatever changes since.
+	hash.Add("f")
+	for _, key := range sampleKeys {
+		for _, handoff := range got {
+			to, ok := handoff.Transfers(key)
+			if expected := before[key] == handoff.Node; ok != expected {
+				t.Errorf("key=%q node=%v - got transfer: %v, expected: %v", key, handoff.Node, ok, expected)
+			}
+			if ok && (to == "c" || to == "d" || to == "f") {
+				t.Errorf("key=%q node=%v - got new owner: %v", key, handoff.Node, to)
+			}
+		}
+	}
+
+	reported = nil
+	hash.Remove("d")
+	if got := handoffs.Update(); len(got) != 0 || len(reported) != 0 {
+		t.Errorf("got: %v, expected no handoff for a node already drained", got)
+	}
+}
@@ -0,0 +1,301 @@
+package rendezvous
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"hash"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+
+	"github.com/beam-cloud/rendezvous/score"
+)
+
+// crc32Table is the CRC-32C table used by the CRC32C hasher and ShardFor.
+var crc32Table = crc32.MakeTable(crc32.Castagnoli)
+
+// Hasher selects the hash function used to score nodes for keys. Changing
+// the hasher of an existing deployment moves nearly every key.
+type Hasher int
+
+const (
+	// CRC32C scores nodes with the 32-bit CRC-32C of the key followed by the
+	// node's identity. It is the default, and the fastest.
+	CRC32C Hasher = iota
+	// Hash128 scores nodes with a 128-bit hash of the key and the node's
+	// identity: 128-bit FNV-1a, with each half passed through a finalizer
+	// that mixes every input bit into every output bit. The high half orders
+	// nodes, and the low half breaks ties, so nodes with equal scores are
+	// practically impossible even at very large node and key counts.
+	Hash128
+	// SHA256 scores nodes with the SHA-256 of the key followed by the node's
+	// identity, truncated to 128 bits: the first 64 bits order nodes, and
+	// the next 64 bits break ties. It is the slowest hasher, for deployments
+	// restricted to FIPS-validated primitives.
+	SHA256
+)
+
+// WithHasher scores nodes with hasher instead of CRC32C.
+func WithHasher(hasher Hasher) Option {
+	return func(c *config) {
+		c.hasher = hasher
+	}
+}
+
+// Layout describes how a key and a node identity are framed into the input
+// of the hash function. The zero Layout, the default, hashes the key
+// immediately followed by the node identity. Other layouts exist to match
+// the placement of another rendezvous hashing implementation.
+type Layout struct {
+	// NodeFirst hashes the node identity before the key.
+	NodeFirst bool
+	// LengthPrefix writes the length of the key and of the node identity,
+	// as a big-endian uint32, before each of them.
+	LengthPrefix bool
+	// Separator is written between the key and the node identity.
+	Separator string
+}
+
+// WithLayout frames the input of the hash function as described by layout.
+func WithLayout(layout Layout) Option {
+	return func(c *config) {
+		c.layout = layout
+	}
+}
+
+// digest computes the raw scores of a Hasher. A digest may keep scratch
+// state, so it is not safe for concurrent use.
+type digest interface {
+	// sum returns the raw score of the node identified by id for key, and
+	// a second word used to break ties between equal raw scores.
+	sum(key, id []byte) (raw, tiebreak uint64)
+	// bits returns the width of the raw scores returned by sum.
+	bits() int
+	// stream returns a keyStream computing the same scores as sum.
+	stream() keyStream
+}
+
+// keyStream hashes a key incrementally as it is written, so that keys too
+// large to hold in memory can be placed. It supports layouts that hash the
+// key first without a length prefix.
+type keyStream interface {
+	io.Writer
+	// sum returns the raw score and tie-breaking word of the node
+	// identified by id for the key written so far followed by separator.
+	sum(separator string, id []byte) (raw, tiebreak uint64)
+}
+
+// newDigest returns a digest for hasher.
+func (hasher Hasher) newDigest() digest {
+	switch hasher {
+	case Hash128:
+		return &hash128Digest{hash: fnv.New128a()}
+	case SHA256:
+		return &sha256Digest{hash: sha256.New()}
+	default:
+		return crc32Digest{}
+	}
+}
+
+// crc32Digest is the digest of CRC32C. It has no tie-breaking word.
+type crc32Digest struct{}
+
+func (crc32Digest) sum(key, id []byte) (uint64, uint64) {
+	return uint64(crc32.Update(crc32.Update(0, crc32Table, key), crc32Table, id)), 0
+}
+
+func (crc32Digest) bits() int { return 32 }
+
+func (crc32Digest) stream() keyStream { return &crc32Stream{} }
+
+// crc32Stream is the keyStream of CRC32C.
+type crc32Stream struct {
+	crc uint32
+}
+
+func (s *crc32Stream) Write(p []byte) (int, error) {
+	s.crc = crc32.Update(s.crc, crc32Table, p)
+	return len(p), nil
+}
+
+func (s *crc32Stream) sum(separator string, id []byte) (uint64, uint64) {
+	crc := crc32.Update(s.crc, crc32Table, unsafeBytes(separator))
+	return uint64(crc32.Update(crc, crc32Table, id)), 0
+}
+
+// hash128Digest is the digest of Hash128.
+type hash128Digest struct {
+	hash hash.Hash
+	buf  [16]byte
+}
+
+func (d *hash128Digest) sum(key, id []byte) (uint64, uint64) {
+	d.hash.Reset()
+	d.hash.Write(key)
+	d.hash.Write(id)
+	return score.Finish128(d.hash.Sum(d.buf[:0]))
+}
+
+func (*hash128Digest) bits() int { return 64 }
+
+func (*hash128Digest) stream() keyStream {
+	return &hashStream{key: fnv.New128a(), node: fnv.New128a(), finish: score.Finish128}
+}
+
+// sha256Digest is the digest of SHA256.
+type sha256Digest struct {
+	hash hash.Hash
+	buf  [sha256.Size]byte
+}
+
+func (d *sha256Digest) sum(key, id []byte) (uint64, uint64) {
+	d.hash.Reset()
+	d.hash.Write(key)
+	d.hash.Write(id)
+	return score.FinishSHA256(d.hash.Sum(d.buf[:0]))
+}
+
+func (*sha256Digest) bits() int { return 64 }
+
+func (*sha256Digest) stream() keyStream {
+	return &hashStream{key: sha256.New(), node: sha256.New(), finish: score.FinishSHA256}
+}
+
+// hashStream is the keyStream of hashers built on a hash.Hash. After the
+// key is written, the key hash's state is saved once and restored into the
+// node hash for every node.
+type hashStream struct {
+	key, node hash.Hash
+	finish    func(sum []byte) (uint64, uint64)
+	// state is the saved state of key, valid unless dirty.
+	state []byte
+	dirty bool
+	buf   [sha256.Size]byte
+}
+
+func (s *hashStream) Write(p []byte) (int, error) {
+	s.dirty = true
+	return s.key.Write(p)
+}
+
+func (s *hashStream) sum(separator string, id []byte) (uint64, uint64) {
+	if s.dirty || s.state == nil {
+		// The standard library's hashes never fail to marshal their state.
+		s.state, _ = s.key.(encoding.BinaryMarshaler).MarshalBinary()
+		s.dirty = false
+	}
+	s.node.(encoding.BinaryUnmarshaler).UnmarshalBinary(s.state)
+	io.WriteString(s.node, separator)
+	s.node.Write(id)
+	return s.finish(s.node.Sum(s.buf[:0]))
+}
+
+// scorer hashes keys and node identities. Each goroutine scoring nodes
+// needs its own scorer.
+type scorer struct {
+	digest digest
+	// input is scratch space for framing hash inputs.
+	input []byte
+
+	// layout and key are those passed to begin. If crc is true, the key is
+	// hashed with CRC32C under the default layout, and keySum holds its
+	// checksum so that next only needs to hash node identities.
+	layout Layout
+	key    []byte
+	crc    bool
+	keySum uint32
+	// stream, if set by beginStream, hashes the key instead of key.
+	stream keyStream
+	// keyDigest is the hash of the key alone, for a ScoreFunc. It is
+	// computed on first use, as recorded by digested.
+	keyDigest uint64
+	digested  bool
+}
+
+// begin prepares s to score nodes for key with next. Hashing every node's
+// input for a key as a batch lets work on the key itself be done once.
+func (s *scorer) begin(layout Layout, key []byte) {
+	s.layout, s.key, s.stream, s.digested = layout, key, nil, false
+	_, s.crc = s.digest.(crc32Digest)
+	s.crc = s.crc && layout == (Layout{})
+	if s.crc {
+		s.keySum = crc32.Update(0, crc32Table, key)
+	}
+}
+
+// beginStream is begin for a key hashed by stream. layout must hash the key
+// first without a length prefix.
+func (s *scorer) beginStream(layout Layout, stream keyStream) {
+	s.layout, s.key, s.stream, s.crc, s.digested = layout, nil, stream, false, false
+}
+
+// next returns the raw score and tie-breaking word of the node identified
+// by id for the key passed to begin.
+func (s *scorer) next(id []byte) (uint64, uint64) {
+	if s.crc {
+		return uint64(crc32.Update(s.keySum, crc32Table, id)), 0
+	}
+	if s.stream != nil {
+		return s.stream.sum(s.layout.Separator, id)
+	}
+	return s.sum(s.layout, s.key, id)
+}
+
+// digestKey returns the hash of the key passed to begin alone.
+func (s *scorer) digestKey() uint64 {
+	if !s.digested {
+		if s.stream != nil {
+			s.keyDigest, _ = s.stream.sum("", nil)
+		} else {
+			s.keyDigest, _ = s.digest.sum(s.key, nil)
+		}
+		s.digested = true
+	}
+	return s.keyDigest
+}
+
+// sum returns the raw score and tie-breaking word of the node identified by
+// id for key, framing the hash input as described by layout.
+func (s *scorer) sum(layout Layout, key, id []byte) (uint64, uint64) {
+	if layout == (Layout{}) {
+		return s.digest.sum(key, id)
+	}
+
+	first, second := key, id
+	if layout.NodeFirst {
+		first, second = id, key
+	}
+	input := s.input[:0]
+	if layout.LengthPrefix {
+		input = binary.BigEndian.AppendUint32(input, uint32(len(first)))
+	}
+	input = append(input, first...)
+	input = append(input, layout.Separator...)
+	if layout.LengthPrefix {
+		input = binary.BigEndian.AppendUint32(input, uint32(len(second)))
+	}
+	input = append(input, second...)
+	s.input = input
+	return s.digest.sum(input, nil)
+}
+
+// compareTie orders the nodes identified by a and b, which have equal
+// scores for the key last passed to h.scorer.begin: first by the hasher's
+// tie-breaking word, higher first, and then by identity. It returns a
+// negative number if a ranks first.
+func (h *Hash[N]) compareTie(a, b []byte) int {
+	return h.compareTieWith(&h.scorer, a, b)
+}
+
+// compareTieWith is compareTie using scorer s.
+func (h *Hash[N]) compareTieWith(s *scorer, a, b []byte) int {
+	_, tieA := s.next(a)
+	_, tieB := s.next(b)
+	if tieA != tieB {
+		return cmp.Compare(tieB, tieA)
+	}
+	return bytes.Compare(a, b)
+}
@@ -0,0 +1,172 @@
+package rendezvous
+
+import (
+	"cmp"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous/score"
+)
+
+func TestHashHash128(t *testing.T) {
+	nodes := []hashableString{"node-1", "node-2", "node-3", "node-4", "node-5"}
+	hash := NewWithOptions(nodes, WithHasher(Hash128))
+	backward := slices.Clone(nodes)
+	slices.Reverse(backward)
+	reversed := NewWithOptions(backward, WithHasher(Hash128))
+	hash.SetWeight("node-5", 2)
+	reversed.SetWeight("node-5", 2)
+
+	const keys = 60000
+	counts := make(map[hashableString]int)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		node, _ := hash.Get(key)
+		counts[node]++
+
+		ranked := hash.GetN(5, key)
+		if ranked[0] != node || !slices.Equal(ranked, reversed.GetN(5, key)) {
+			t.Fatalf("key=%q - got ranking %v for Get %v, expected a consistent ranking", key, ranked, node)
+		}
+	}
+
+	for _, node := range nodes {
+		expected := keys / 6.0
+		if node == "node-5" {
+			expected *= 2
+		}
+		if deviation := math.Abs(float64(counts[node])-expected) / expected; deviation > 0.05 {
+			t.Errorf("node=%v - got %d keys, expected about %.0f", node, counts[node], expected)
+		}
+	}
+}
+
+func TestHashHash128Ties(t *testing.T) {
+	// A constant ScoreFunc ties every node, so only the tie-breaking word
+	// orders them: higher words first, and it should not always favor the
+	// lowest identity.
+	nodes := []hashableString{"a", "b", "c", "d"}
+	hash := NewWithOptions(nodes, WithHasher(Hash128), WithScoreFunc(func(uint64, uint64, float64) float64 { return 0 }))
+	digest := Hash128.newDigest()
+
+	firsts := make(map[hashableString]bool)
+	for _, key := range sampleKeys {
+		expected := slices.Clone(nodes)
+		slices.SortFunc(expected, func(a, b hashableString) int {
+			_, tieA := digest.sum([]byte(key), a.Bytes())
+			_, tieB := digest.sum([]byte(key), b.Bytes())
+			return cmp.Or(cmp.Compare(tieB, tieA), cmp.Compare(a, b))
+		})
+		if got := hash.GetN(len(nodes), key); !slices.Equal(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+		node, _ := hash.Get(key)
+		if node != expected[0] {
+			t.Errorf("key=%q - got: %v, expected: %v", key, node, expected[0])
+		}
+		firsts[node] = true
+	}
+	if len(firsts) < 2 {
+		t.Errorf("got %v as the only first choices, expected ties to be broken by hash", firsts)
+	}
+}
+
+func TestSHA256Vectors(t *testing.T) {
+	testcases := []struct {
+		key, id       string
+		raw, tiebreak uint64
+	}{
+		{"", "", 0xe3b0c44298fc1c14, 0x9afbf4c8996fb924},
+		{"key", "node-1", 0x73fc4b30e4d35de0, 0xba3608472b5dfa24},
+		{"352DAB08-C1FD-4462-B573-7640B730B721", "a", 0xc59ef1ae440cce41, 0xdf7c9381f8980411},
+	}
+
+	digest := SHA256.newDigest()
+	for _, testcase := range testcases {
+		raw, tiebreak := digest.sum([]byte(testcase.key), []byte(testcase.id))
+		if raw != testcase.raw || tiebreak != testcase.tiebreak {
+			t.Errorf("key=%q id=%q - got: (%#x, %#x), expected: (%#x, %#x)", testcase.key, testcase.id, raw, tiebreak, testcase.raw, testcase.tiebreak)
+		}
+	}
+
+	hash := NewWithOptions([]hashableString{"a", "b", "c"}, WithHasher(SHA256))
+	expected := []hashableString{"b", "a", "c"}
+	if got := hash.GetN(3, sampleKeys[0]); !slices.Equal(got, expected) {
+		t.Errorf("key=%q - got: %v, expected: %v", sampleKeys[0], got, expected)
+	}
+}
+
+func TestHashLayout(t *testing.T) {
+	nodes := []hashableString{"a", "b", "c", "d", "e"}
+	testcases := []struct {
+		layout Layout
+		input  func(key, id string) []byte
+	}{
+		{Layout{}, func(key, id string) []byte { return []byte(key + id) }},
+		{Layout{NodeFirst: true}, func(key, id string) []byte { return []byte(id + key) }},
+		{Layout{Separator: ":"}, func(key, id string) []byte { return []byte(key + ":" + id) }},
+		{Layout{NodeFirst: true, LengthPrefix: true, Separator: "|"}, func(key, id string) []byte {
+			return fmt.Appendf(nil, "\x00\x00\x00%c%s|\x00\x00\x00%c%s", len(id), id, len(key), key)
+		}},
+	}
+
+	for _, testcase := range testcases {
+		hash := NewWithOptions(nodes, WithLayout(testcase.layout))
+		for _, key := range sampleKeys {
+			var expected hashableString
+			var best uint32
+			for _, node := range nodes {
+				if score := crc32.Checksum(testcase.input(key, string(node)), crc32Table); expected == "" || score > best {
+					expected, best = node, score
+				}
+			}
+			if got, _ := hash.Get(key); got != expected {
+				t.Errorf("layout=%+v key=%q - got: %v, expected: %v", testcase.layout, key, got, expected)
+			}
+		}
+	}
+}
+
+func TestScorerBatch(t *testing.T) {
+	ids := [][]byte{[]byte("a"), []byte("node-1"), {}, []byte("a much longer node identity")}
+	for _, hasher := range []Hasher{CRC32C, Hash128, SHA256} {
+		for _, layout := range []Layout{{}, {NodeFirst: true, Separator: "/"}} {
+			s := scorer{digest: hasher.newDigest()}
+			for _, key := range sampleKeys {
+				s.begin(layout, []byte(key))
+				for _, id := range ids {
+					raw, tiebreak := s.next(id)
+					expectedRaw, expectedTiebreak := s.sum(layout, []byte(key), id)
+					if raw != expectedRaw || tiebreak != expectedTiebreak {
+						t.Errorf("hasher=%d layout=%+v key=%q id=%q - got: (%#x, %#x), expected: (%#x, %#x)", hasher, layout, key, id, raw, tiebreak, expectedRaw, expectedTiebreak)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHashMatchesScorePackage(t *testing.T) {
+	nodes := []hashableString{"a", "b", "c", "d"}
+	identities := make([][]byte, len(nodes))
+	for i, node := range nodes {
+		identities[i] = node.Bytes()
+	}
+	hash := New(nodes...)
+	hash128 := NewWithOptions(nodes, WithHasher(Hash128))
+	for _, key := range sampleKeys {
+		got, _ := hash.Get(key)
+		if expected := nodes[score.Top(nil, []byte(key), identities)]; got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+		for _, candidate := range hash128.Explain(key).Ranked {
+			raw, tiebreak := score.Hash128(nil, []byte(key), []byte(candidate.ID))
+			if candidate.Raw != raw || candidate.Tiebreak != tiebreak {
+				t.Errorf("key=%q node=%s - got: %#x/%#x, expected: %#x/%#x", key, candidate.ID, candidate.Raw, candidate.Tiebreak, raw, tiebreak)
+			}
+		}
+	}
+}
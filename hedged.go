@@ -0,0 +1,76 @@
+package rendezvous
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Hedged is HedgedN with up to two calls: to the highest scoring node for
+// key, and then to the second.
+func (h *Hash[N]) Hedged(ctx context.Context, key string, delay time.Duration, fn func(context.Context, N) error) (N, error) {
+	return h.HedgedN(ctx, 2, key, delay, fn)
+}
+
+// HedgedN calls fn with the highest scoring node for key and, each time
+// delay passes without a call succeeding, hedges with the next node in
+// descending score order, up to n calls in all. A call that fails starts
+// the next one without waiting out the delay. HedgedN returns the node of
+// the first call to succeed, canceling the context passed to the others,
+// which are not waited for. It returns ErrNoNodes if the Hash is empty, and
+// otherwise a *FallbackError recording the failed calls in rank order if
+// every call fails.
+func (h *Hash[N]) HedgedN(ctx context.Context, n int, key string, delay time.Duration, fn func(context.Context, N) error) (N, error) {
+	nodes := h.GetN(max(n, 1), key)
+	if len(nodes) == 0 {
+		var zero N
+		return zero, ErrNoNodes
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		rank int
+		err  error
+	}
+	results := make(chan result, len(nodes))
+	start := func(rank int) {
+		go func() {
+			results <- result{rank, fn(ctx, nodes[rank])}
+		}()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	start(0)
+	started := 1
+	var failed FallbackError[N]
+	for len(failed.Attempts) < started {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return nodes[r.rank], nil
+			}
+			failed.Attempts = append(failed.Attempts, Attempt[N]{Rank: r.rank, Node: nodes[r.rank], Err: r.err})
+			if started < len(nodes) {
+				start(started)
+				started++
+				timer.Reset(delay)
+			}
+		case <-timer.C:
+			if started < len(nodes) {
+				start(started)
+				started++
+				timer.Reset(delay)
+			}
+		case <-ctx.Done():
+			var zero N
+			return zero, ctx.Err()
+		}
+	}
+
+	slices.SortFunc(failed.Attempts, func(a, b Attempt[N]) int { return a.Rank - b.Rank })
+	var zero N
+	return zero, &failed
+}
@@ -0,0 +1,67 @@
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHashHedged(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	key := sampleKeys[0]
+	ranking := hash.GetN(4, key)
+	ctx := context.Background()
+
+	// A fast primary is the only node called.
+	var calls atomic.Int32
+	node, err := hash.Hedged(ctx, key, time.Second, func(ctx context.Context, node hashableString) error {
+		calls.Add(1)
+		return nil
+	})
+	if err != nil || node != ranking[0] || calls.Load() != 1 {
+		t.Errorf("got %v, %v after %d calls, expected %v after one call", node, err, calls.Load(), ranking[0])
+	}
+
+	// A slow primary is hedged with the second node, whose context the
+	// primary's call sees canceled.
+	canceled := make(chan bool, 1)
+	node, err = hash.Hedged(ctx, key, 10*time.Millisecond, func(ctx context.Context, node hashableString) error {
+		if node == ranking[0] {
+			<-ctx.Done()
+			canceled <- true
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || node != ranking[1] {
+		t.Errorf("got %v, %v, expected the hedge %v", node, err, ranking[1])
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Errorf("expected the slow call to be canceled")
+	}
+
+	// Failures start the next call at once, and every call failing returns
+	// the attempts in rank order.
+	start := time.Now()
+	failure := errors.New("down")
+	node, err = hash.HedgedN(ctx, 3, key, time.Hour, func(ctx context.Context, node hashableString) error {
+		return failure
+	})
+	var fallback *FallbackError[hashableString]
+	if !errors.As(err, &fallback) || len(fallback.Attempts) != 3 || !errors.Is(err, failure) || time.Since(start) > time.Second {
+		t.Fatalf("got %v, %v, expected three failed attempts without waiting", node, err)
+	}
+	for rank, attempt := range fallback.Attempts {
+		if attempt.Rank != rank || attempt.Node != ranking[rank] {
+			t.Errorf("got attempt %+v, expected rank %d on %v", attempt, rank, ranking[rank])
+		}
+	}
+
+	if _, err := New[hashableString]().Hedged(ctx, key, time.Second, nil); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("got error %v, expected ErrNoNodes", err)
+	}
+}
@@ -0,0 +1,65 @@
+package rendezvous
+
+import "bytes"
+
+// AddWithID adds node under the identity id instead of its own, so that
+// the identity that places keys stays stable while the value returned to
+// callers, such as an address, changes. If a node with identity id is
+// already in the Hash, node replaces it and keeps its weight, and no keys
+// move.
+//
+// The Hash remembers which identity node was given, so node may be passed
+// to Remove, SetWeight and other methods as usual. A replaced node's value
+// is forgotten, and can no longer be used to find the node.
+func (h *Hash[N]) AddWithID(id []byte, node N) {
+	natural := h.identity(node)
+	var previous []byte
+	changes := Changeset[N]{Add: []N{node}, AddIDs: [][]byte{id}}
+	if i := h.find(id); i >= 0 {
+		old := &h.nodes[i]
+		previous = h.identity(old.node)
+		changes.Remove = []N{old.node}
+		changes.Weights = []WeightChange[N]{{Node: node, Weight: old.weight}}
+	}
+
+	h.Apply(changes)
+	if previous != nil && !bytes.Equal(previous, natural) {
+		delete(h.assigned, string(previous))
+	}
+}
+
+// RemoveID removes every node with identity id, and reports whether there
+// was any.
+func (h *Hash[N]) RemoveID(id []byte) bool {
+	i := h.find(id)
+	if i < 0 {
+		return false
+	}
+	node := h.nodes[i].node
+	h.Apply(Changeset[N]{Remove: []N{node}})
+	delete(h.assigned, string(h.identity(node)))
+	return true
+}
+
+// nodeID returns the identity of node: the one it was given by AddWithID,
+// if any, and otherwise its own.
+func (h *Hash[N]) nodeID(node N) []byte {
+	id := h.identity(node)
+	if assigned, ok := h.assigned[string(id)]; ok {
+		return assigned
+	}
+	return id
+}
+
+// adoptID records the identity other gave node with AddWithID, if any, as
+// h's identity for node.
+func (h *Hash[N]) adoptID(other *Hash[N], node N, id []byte) {
+	natural := other.identity(node)
+	if bytes.Equal(natural, id) {
+		return
+	}
+	if h.assigned == nil {
+		h.assigned = make(map[string][]byte)
+	}
+	h.assigned[string(natural)] = id
+}
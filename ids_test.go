@@ -0,0 +1,68 @@
+package rendezvous
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHashAddWithID(t *testing.T) {
+	hash := New[hashableString]()
+	hash.AddWithID([]byte("a"), "10.0.0.1:80")
+	hash.AddWithID([]byte("b"), "10.0.0.2:80")
+	hash.Add("c")
+	plain := New[hashableString]("a", "b", "c")
+
+	placement := func() map[string]string {
+		placed := make(map[string]string)
+		for _, key := range sampleKeys {
+			node, _ := hash.Get(key)
+			placed[key] = string(hash.Identity(node))
+		}
+		return placed
+	}
+	before := placement()
+	for key, id := range before {
+		if expected, _ := plain.Get(key); id != string(expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, id, expected)
+		}
+	}
+
+	// Moving a to a new address keeps its keys and weight.
+	hash.SetWeight("10.0.0.1:80", 2)
+	hash.AddWithID([]byte("a"), "10.0.0.9:80")
+	if node, ok := hash.Find([]byte("a")); !ok || node != "10.0.0.9:80" {
+		t.Errorf("got %v, expected a at its new address", node)
+	}
+	if weight, ok := hash.Weight("10.0.0.9:80"); !ok || weight != 2 {
+		t.Errorf("got weight %v, %v, expected the replaced node's weight", weight, ok)
+	}
+	if _, ok := hash.Weight("10.0.0.1:80"); ok {
+		t.Error("expected the old address to be forgotten")
+	}
+	hash.SetWeight("10.0.0.9:80", 1)
+	for key, id := range placement() {
+		if id != before[key] {
+			t.Errorf("key=%q - moved from %v to %v after an address change", key, before[key], id)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := hash.SaveSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := New[hashableString]()
+	if err := restored.LoadSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Equal(hash) || !bytes.Equal(restored.Identity("10.0.0.9:80"), []byte("a")) {
+		t.Errorf("got %s, expected assigned identities restored", restored.Dump())
+	}
+
+	hash.Remove("10.0.0.2:80")
+	if !hash.RemoveID([]byte("a")) || hash.RemoveID([]byte("a")) {
+		t.Error("expected RemoveID to remove a once")
+	}
+	if nodes := hash.Nodes(); len(nodes) != 1 || nodes[0] != "c" {
+		t.Errorf("got %v, expected only c", nodes)
+	}
+}
@@ -0,0 +1,110 @@
+package rendezvous
+
+import (
+	"cmp"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// KetamaPoint is a point on a ketama-style continuum, such as those of
+// libketama and nginx: keys whose hash is at most Point, and greater than
+// the previous point's, belong to Server.
+type KetamaPoint struct {
+	Point  uint32
+	Server string
+}
+
+// ketamaServer is a server in a ketama server list.
+type ketamaServer struct {
+	addr   string
+	weight uint64
+}
+
+// ketamaServers returns the Hash's nodes with a positive weight as ketama
+// servers, addressed by addr. libketama reads integer weights, so weights
+// are scaled by the smallest power of ten, up to a million, that makes them
+// all whole, and then rounded.
+func (h *Hash[N]) ketamaServers(addr func(N) string) []ketamaServer {
+	scale := 1.0
+	for _, ns := range h.nodes {
+		for ns.weight > 0 && ns.weight*scale != math.Trunc(ns.weight*scale) && scale < 1e6 {
+			scale *= 10
+		}
+	}
+	var servers []ketamaServer
+	for _, ns := range h.nodes {
+		if weight := uint64(math.Round(ns.weight * scale)); ns.weight > 0 && weight > 0 {
+			servers = append(servers, ketamaServer{addr: addr(ns.node), weight: weight})
+		}
+	}
+	return servers
+}
+
+// WriteKetama writes the Hash's nodes to w as a libketama server list: one
+// "host:port weight" line per node with a positive weight, addressed by
+// addr. Clients that only speak ketama can build their continuum from the
+// list while other services place keys with the Hash, during a migration
+// from one to the other. Zone weights and canaries have no ketama
+// equivalent and are ignored.
+func (h *Hash[N]) WriteKetama(w io.Writer, addr func(N) string) error {
+	for _, server := range h.ketamaServers(addr) {
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", server.addr, server.weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ketama returns the continuum a libketama client builds from the server
+// list written by WriteKetama, sorted by point. Each server gets 160 points
+// for an equal share of the total weight, 4 from the MD5 of each of
+// "host:port-0", "host:port-1" and so on.
+func (h *Hash[N]) Ketama(addr func(N) string) []KetamaPoint {
+	servers := h.ketamaServers(addr)
+	var total uint64
+	for _, server := range servers {
+		total += server.weight
+	}
+
+	var continuum []KetamaPoint
+	for _, server := range servers {
+		// libketama computes the share in single precision, and the number
+		// of points from it in double precision.
+		share := float32(server.weight) / float32(total)
+		points := int(math.Floor(float64(share) * 40 * float64(len(servers))))
+		for k := range points {
+			digest := md5.Sum([]byte(server.addr + "-" + strconv.Itoa(k)))
+			for i := range 4 {
+				continuum = append(continuum, KetamaPoint{
+					Point:  binary.LittleEndian.Uint32(digest[i*4:]),
+					Server: server.addr,
+				})
+			}
+		}
+	}
+	slices.SortFunc(continuum, func(a, b KetamaPoint) int {
+		return cmp.Or(cmp.Compare(a.Point, b.Point), cmp.Compare(a.Server, b.Server))
+	})
+	return continuum
+}
+
+// KetamaGet returns the server a libketama client places key on, given
+// its continuum, or "" if the continuum is empty.
+func KetamaGet(continuum []KetamaPoint, key string) string {
+	if len(continuum) == 0 {
+		return ""
+	}
+	digest := md5.Sum([]byte(key))
+	hash := binary.LittleEndian.Uint32(digest[:])
+	i := sort.Search(len(continuum), func(i int) bool { return continuum[i].Point >= hash })
+	if i == len(continuum) {
+		i = 0
+	}
+	return continuum[i].Server
+}
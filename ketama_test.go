@@ -0,0 +1,81 @@
+package rendezvous
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// newServers returns a Hash of servers identified and addressed by
+// host:port.
+func newServers(servers ...server) *Hash[server] {
+	return NewFunc(func(s server) []byte { return []byte(serverAddr(s)) }, servers...)
+}
+
+func serverAddr(s server) string {
+	return fmt.Sprintf("%s:%d", s.Name, s.Port)
+}
+
+func TestHashWriteKetama(t *testing.T) {
+	hash := newServers(server{"10.0.0.1", 11211}, server{"10.0.0.2", 11211}, server{"10.0.0.3", 11211})
+	hash.SetWeight(server{"10.0.0.2", 11211}, 1.5)
+	hash.SetWeight(server{"10.0.0.3", 11211}, 0)
+
+	var out strings.Builder
+	if err := hash.WriteKetama(&out, serverAddr); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "10.0.0.1:11211\t10\n10.0.0.2:11211\t15\n"; out.String() != expected {
+		t.Errorf("got: %q, expected: %q", out.String(), expected)
+	}
+}
+
+func TestHashKetama(t *testing.T) {
+	hash := newServers(server{"10.0.0.1", 11211}, server{"10.0.0.2", 11211}, server{"10.0.0.3", 11211}, server{"10.0.0.4", 11211})
+	continuum := hash.Ketama(serverAddr)
+	if len(continuum) != 4*160 {
+		t.Fatalf("got %d points, expected 160 per server", len(continuum))
+	}
+	for i := 1; i < len(continuum); i++ {
+		if continuum[i].Point < continuum[i-1].Point {
+			t.Fatalf("got the continuum unsorted at %d", i)
+		}
+	}
+
+	// The first digest of a server yields its first four points.
+	digest := md5.Sum([]byte("10.0.0.1:11211-0"))
+	found := false
+	for _, point := range continuum {
+		found = found || (point.Point == binary.LittleEndian.Uint32(digest[4:]) && point.Server == "10.0.0.1:11211")
+	}
+	if !found {
+		t.Error("got the continuum without the points of 10.0.0.1:11211-0")
+	}
+
+	counts := make(map[string]int)
+	for i := range 40000 {
+		counts[KetamaGet(continuum, fmt.Sprintf("key-%d", i))]++
+	}
+	for addr, count := range counts {
+		if math.Abs(float64(count)/40000-0.25) > 0.05 {
+			t.Errorf("server=%s - got share %v, expected about a quarter", addr, float64(count)/40000)
+		}
+	}
+
+	hash.SetWeight(server{"10.0.0.1", 11211}, 3)
+	weighted := 0
+	for _, point := range hash.Ketama(serverAddr) {
+		if point.Server == "10.0.0.1:11211" {
+			weighted++
+		}
+	}
+	if weighted != 4*int(math.Floor(0.5*40*4)) {
+		t.Errorf("got %d points for half the weight, expected %d", weighted, 4*80)
+	}
+	if KetamaGet(nil, "key") != "" {
+		t.Error("got a server from an empty continuum")
+	}
+}
@@ -0,0 +1,103 @@
+package rendezvous
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KeyRule routes the keys matching it to a named node pool of a
+// KeyClasses. A key matches if it starts with Prefix, when Prefix is set,
+// and matches Pattern, when Pattern is set.
+type KeyRule struct {
+	Prefix  string
+	Pattern *regexp.Regexp
+	Pool    string
+}
+
+// matches reports whether key matches the rule.
+func (r KeyRule) matches(key string) bool {
+	if r.Prefix != "" && !strings.HasPrefix(key, r.Prefix) {
+		return false
+	}
+	return r.Pattern == nil || r.Pattern.MatchString(key)
+}
+
+// KeyClasses routes classes of keys to named pools of a Hash's nodes, such
+// as system keys to a control pool and tenant data to a general pool, so
+// that one Hash serves them all. Each key is routed to the pool of the
+// first rule it matches, or to the default pool if none, and placed within
+// the pool as a View of its nodes would place it.
+//
+// KeyClasses is not safe for concurrent use, nor for use concurrently with
+// its Hash.
+type KeyClasses[N any] struct {
+	// Default is the pool of keys matching no rule. If it is "", they are
+	// placed across every node of the Hash.
+	Default string
+
+	hash  *Hash[N]
+	pools map[string]*View[N]
+	rules []KeyRule
+}
+
+// KeyClasses returns KeyClasses routing keys across the nodes of h, with
+// no pools or rules.
+func (h *Hash[N]) KeyClasses() *KeyClasses[N] {
+	return &KeyClasses[N]{hash: h, pools: make(map[string]*View[N])}
+}
+
+// DefinePool defines the pool name as the nodes of the Hash for which
+// member returns true, replacing any pool of the same name. Pools may
+// overlap, and follow the Hash's membership changes.
+func (c *KeyClasses[N]) DefinePool(name string, member func(N) bool) {
+	c.pools[name] = c.hash.View(member)
+}
+
+// AddRule appends rule to the rules, which are matched in the order they
+// were added. It returns an error if the rule's pool is not defined.
+func (c *KeyClasses[N]) AddRule(rule KeyRule) error {
+	if _, ok := c.pools[rule.Pool]; !ok {
+		return fmt.Errorf("rendezvous: undefined key pool %q", rule.Pool)
+	}
+	c.rules = append(c.rules, rule)
+	return nil
+}
+
+// Pool returns the name of the pool key is routed to.
+func (c *KeyClasses[N]) Pool(key string) string {
+	for _, rule := range c.rules {
+		if rule.matches(key) {
+			return rule.Pool
+		}
+	}
+	return c.Default
+}
+
+// Get returns the node of key's pool with the highest score for key. If
+// the pool has no nodes, or the default pool is not defined, the zero value
+// of type N is returned along with false.
+func (c *KeyClasses[N]) Get(key string) (N, bool) {
+	pool := c.Pool(key)
+	if pool == "" {
+		return c.hash.Get(key)
+	}
+	if view, ok := c.pools[pool]; ok {
+		return view.Get(key)
+	}
+	var zero N
+	return zero, false
+}
+
+// GetN returns no more than n nodes of key's pool for key, ordered by
+// descending score.
+func (c *KeyClasses[N]) GetN(n int, key string) []N {
+	pool := c.Pool(key)
+	if pool == "" {
+		return c.hash.GetN(n, key)
+	}
+	if view, ok := c.pools[pool]; ok {
+		return view.GetN(n, key)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package rendezvous
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestKeyClasses(t *testing.T) {
+	hash := New[hashableString]("ctl-1", "ctl-2", "gen-1", "gen-2", "gen-3")
+	classes := hash.KeyClasses()
+	control := func(node hashableString) bool { return strings.HasPrefix(string(node), "ctl-") }
+	classes.DefinePool("control", control)
+	classes.DefinePool("general", func(node hashableString) bool { return !control(node) })
+
+	if err := classes.AddRule(KeyRule{Prefix: "system/", Pool: "control"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := classes.AddRule(KeyRule{Pattern: regexp.MustCompile(`^lease-\d+$`), Pool: "control"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := classes.AddRule(KeyRule{Prefix: "tenant/", Pool: "missing"}); err == nil {
+		t.Error("got no error adding a rule for an undefined pool")
+	}
+
+	for _, test := range []struct {
+		key, pool string
+	}{
+		{"system/config", "control"},
+		{"lease-42", "control"},
+		{"lease-x", ""},
+		{"tenant/a/object", ""},
+	} {
+		if got := classes.Pool(test.key); got != test.pool {
+			t.Errorf("key=%q - got pool: %q, expected: %q", test.key, got, test.pool)
+		}
+	}
+
+	for _, key := range sampleKeys {
+		system := "system/" + key
+		if got, expected := classes.GetN(2, system), hash.View(control).GetN(2, system); !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", system, got, expected)
+		}
+		if got, expected := classes.GetN(1, key), hash.GetN(1, key); !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected the Hash's choice %v without a default pool", key, got, expected)
+		}
+	}
+
+	classes.Default = "general"
+	for _, key := range sampleKeys {
+		if got, _ := classes.Get(key); control(got) {
+			t.Errorf("key=%q - got: %v, expected a node of the general pool", key, got)
+		}
+	}
+
+	// Pools follow the Hash's membership.
+	hash.Remove("ctl-1")
+	hash.Remove("ctl-2")
+	if got, ok := classes.Get("system/config"); ok {
+		t.Errorf("got: %v, expected no node from an empty pool", got)
+	}
+}
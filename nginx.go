@@ -0,0 +1,109 @@
+package rendezvous
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// nginxServer is an upstream server in nginx's consistent hashing.
+type nginxServer struct {
+	addr   string
+	weight int
+}
+
+// nginxServers returns the Hash's nodes with a positive weight as nginx
+// upstream servers, addressed by addr. nginx weights are integers, so
+// weights are rounded, to no less than 1.
+func (h *Hash[N]) nginxServers(addr func(N) string) []nginxServer {
+	var servers []nginxServer
+	for _, ns := range h.nodes {
+		if ns.weight > 0 {
+			servers = append(servers, nginxServer{addr: addr(ns.node), weight: max(int(math.Round(ns.weight)), 1)})
+		}
+	}
+	return servers
+}
+
+// WriteNginxUpstream writes the Hash's nodes to w as an nginx upstream
+// block named name that places requests with "hash key consistent", with
+// a server directive for each node with a positive weight, addressed by
+// addr. Weights are rounded to integers, as nginx requires.
+func (h *Hash[N]) WriteNginxUpstream(w io.Writer, name, key string, addr func(N) string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "upstream %s {\n\thash %s consistent;\n", name, key)
+	for _, server := range h.nginxServers(addr) {
+		fmt.Fprintf(&b, "\tserver %s", server.addr)
+		if server.weight != 1 {
+			fmt.Fprintf(&b, " weight=%d", server.weight)
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// NginxContinuum returns the continuum nginx builds for "hash ... consistent"
+// over the upstream servers written by WriteNginxUpstream, sorted by point,
+// so that Go services and nginx agree on which server owns a key. Each
+// server gets 160 points per unit of weight: the CRC-32 of its host, a NUL
+// byte, its port and the previous point. Where points collide, nginx keeps
+// one at random; NginxContinuum keeps the server that sorts first.
+//
+// HAProxy's "hash-type consistent" places keys differently, and is not
+// reproduced.
+func (h *Hash[N]) NginxContinuum(addr func(N) string) []KetamaPoint {
+	var continuum []KetamaPoint
+	for _, server := range h.nginxServers(addr) {
+		host, port := nginxHostPort(server.addr)
+		base := crc32.ChecksumIEEE([]byte(host + "\x00" + port))
+		var prev [4]byte
+		for range server.weight * 160 {
+			point := crc32.Update(base, crc32.IEEETable, prev[:])
+			continuum = append(continuum, KetamaPoint{Point: point, Server: server.addr})
+			binary.LittleEndian.PutUint32(prev[:], point)
+		}
+	}
+	slices.SortFunc(continuum, func(a, b KetamaPoint) int {
+		return cmp.Or(cmp.Compare(a.Point, b.Point), cmp.Compare(a.Server, b.Server))
+	})
+	return slices.CompactFunc(continuum, func(a, b KetamaPoint) bool { return a.Point == b.Point })
+}
+
+// nginxHostPort splits an upstream server address as nginx does for
+// hashing: a "unix:" socket path is all host, and otherwise a trailing
+// ":port" of digits, if any, is the port.
+func nginxHostPort(addr string) (host, port string) {
+	if len(addr) >= 5 && strings.EqualFold(addr[:5], "unix:") {
+		return addr[5:], ""
+	}
+	for i := len(addr) - 1; i >= 0; i-- {
+		c := addr[i]
+		if c == ':' {
+			return addr[:i], addr[i+1:]
+		}
+		if c < '0' || c > '9' {
+			break
+		}
+	}
+	return addr, ""
+}
+
+// NginxGet returns the server nginx places key on, given its continuum, or
+// "" if the continuum is empty. key is the value of the hash directive's
+// key expression for a request.
+func NginxGet(continuum []KetamaPoint, key string) string {
+	if len(continuum) == 0 {
+		return ""
+	}
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(continuum), func(i int) bool { return continuum[i].Point >= hash })
+	return continuum[i%len(continuum)].Server
+}
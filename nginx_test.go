@@ -0,0 +1,76 @@
+package rendezvous
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHashWriteNginxUpstream(t *testing.T) {
+	hash := newServers(server{"10.0.0.1", 8080}, server{"10.0.0.2", 8080}, server{"10.0.0.3", 8080})
+	hash.SetWeight(server{"10.0.0.2", 8080}, 2)
+	hash.SetWeight(server{"10.0.0.3", 8080}, 0)
+
+	var out strings.Builder
+	if err := hash.WriteNginxUpstream(&out, "backend", "$request_uri", serverAddr); err != nil {
+		t.Fatal(err)
+	}
+	expected := "upstream backend {\n\thash $request_uri consistent;\n\tserver 10.0.0.1:8080;\n\tserver 10.0.0.2:8080 weight=2;\n}\n"
+	if out.String() != expected {
+		t.Errorf("got: %q, expected: %q", out.String(), expected)
+	}
+}
+
+func TestNginxHostPort(t *testing.T) {
+	testcases := []struct{ addr, host, port string }{
+		{"10.0.0.1:8080", "10.0.0.1", "8080"},
+		{"backend.example.com", "backend.example.com", ""},
+		{"[::1]:80", "[::1]", "80"},
+		{"unix:/tmp/backend.sock", "/tmp/backend.sock", ""},
+		{"host:http", "host:http", ""},
+	}
+	for _, testcase := range testcases {
+		if host, port := nginxHostPort(testcase.addr); host != testcase.host || port != testcase.port {
+			t.Errorf("addr=%q - got: %q %q, expected: %q %q", testcase.addr, host, port, testcase.host, testcase.port)
+		}
+	}
+}
+
+func TestHashNginxContinuum(t *testing.T) {
+	hash := newServers(server{"10.0.0.1", 8080}, server{"10.0.0.2", 8080}, server{"10.0.0.3", 8080})
+	hash.SetWeight(server{"10.0.0.1", 8080}, 2)
+	continuum := hash.NginxContinuum(serverAddr)
+	if len(continuum) != 4*160 {
+		t.Fatalf("got %d points, expected 160 per unit of weight", len(continuum))
+	}
+
+	// A server's first point is the CRC-32 of host, NUL, port and four zero
+	// bytes, and its second chains from the first.
+	first := crc32.ChecksumIEEE([]byte("10.0.0.2\x008080\x00\x00\x00\x00"))
+	var prev [4]byte
+	binary.LittleEndian.PutUint32(prev[:], first)
+	second := crc32.ChecksumIEEE(append([]byte("10.0.0.2\x008080"), prev[:]...))
+	points := make(map[uint32]string)
+	for _, point := range continuum {
+		points[point.Point] = point.Server
+	}
+	if points[first] != "10.0.0.2:8080" || points[second] != "10.0.0.2:8080" {
+		t.Errorf("got %q and %q, expected the first points of 10.0.0.2:8080", points[first], points[second])
+	}
+
+	counts := make(map[string]int)
+	for i := range 40000 {
+		counts[NginxGet(continuum, fmt.Sprintf("/path/%d", i))]++
+	}
+	for addr, expected := range map[string]float64{"10.0.0.1:8080": 0.5, "10.0.0.2:8080": 0.25, "10.0.0.3:8080": 0.25} {
+		if share := float64(counts[addr]) / 40000; math.Abs(share-expected) > 0.06 {
+			t.Errorf("server=%s - got share %v, expected about %v", addr, share, expected)
+		}
+	}
+	if NginxGet(nil, "key") != "" {
+		t.Error("got a server from an empty continuum")
+	}
+}
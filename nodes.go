@@ -0,0 +1,68 @@
+package rendezvous
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// StringNode is a node identified by its string value.
+type StringNode string
+
+// Bytes implements the Hashable interface.
+func (n StringNode) Bytes() []byte {
+	return []byte(n)
+}
+
+// StringerNode adapts a fmt.Stringer into a node identified by the result of
+// its String method.
+type StringerNode[T fmt.Stringer] struct {
+	Value T
+}
+
+// FromStringer wraps value as a StringerNode.
+func FromStringer[T fmt.Stringer](value T) StringerNode[T] {
+	return StringerNode[T]{Value: value}
+}
+
+// Bytes implements the Hashable interface.
+func (n StringerNode[T]) Bytes() []byte {
+	return []byte(n.Value.String())
+}
+
+// String returns the wrapped value's String.
+func (n StringerNode[T]) String() string {
+	return n.Value.String()
+}
+
+// NewComparable returns a new Hash ready for use with the given nodes, which
+// are identified by a default encoding of their value:
+//
+//   - strings by their bytes, so NewComparable[string] places keys exactly
+//     as New does with StringNode
+//   - signed and unsigned integers by their base-10 representation
+//   - booleans as "true" or "false"
+//
+// The encoding depends only on the kind of N, so named types such as
+// `type NodeID int` are supported. NewComparable panics for other kinds,
+// such as pointers or structs, which have no stable encoding; use NewFunc
+// for those.
+func NewComparable[N comparable](nodes ...N) *Hash[N] {
+	return newHash(comparableIdentity[N](), nodes, nil)
+}
+
+// comparableIdentity returns the default identity function for N.
+func comparableIdentity[N comparable]() func(N) []byte {
+	switch typ := reflect.TypeFor[N](); typ.Kind() {
+	case reflect.String:
+		return func(n N) []byte { return []byte(reflect.ValueOf(n).String()) }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(n N) []byte { return strconv.AppendInt(nil, reflect.ValueOf(n).Int(), 10) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(n N) []byte { return strconv.AppendUint(nil, reflect.ValueOf(n).Uint(), 10) }
+	case reflect.Bool:
+		return func(n N) []byte { return strconv.AppendBool(nil, reflect.ValueOf(n).Bool()) }
+	default:
+		panic(fmt.Sprintf("rendezvous: NewComparable does not support node type %v; use NewFunc", typ))
+	}
+}
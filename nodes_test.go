@@ -0,0 +1,49 @@
+package rendezvous
+
+import (
+	"net/netip"
+	"strconv"
+	"testing"
+)
+
+func TestStringNodes(t *testing.T) {
+	plain := New[StringNode]("10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80")
+	stringers := New(
+		FromStringer(netip.MustParseAddrPort("10.0.0.1:80")),
+		FromStringer(netip.MustParseAddrPort("10.0.0.2:80")),
+		FromStringer(netip.MustParseAddrPort("10.0.0.3:80")),
+	)
+
+	for _, key := range sampleKeys {
+		expected, _ := plain.Get(key)
+		got, _ := stringers.Get(key)
+		if got.String() != string(expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+}
+
+func TestNewComparable(t *testing.T) {
+	type nodeID int
+
+	strs := NewComparable("1", "2", "3")
+	ints := NewComparable[nodeID](1, 2, 3)
+	plain := New[StringNode]("1", "2", "3")
+
+	for _, key := range sampleKeys {
+		expected, _ := plain.Get(key)
+		if got, _ := strs.Get(key); got != string(expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+		if got, _ := ints.Get(key); strconv.Itoa(int(got)) != string(expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewComparable accepted a pointer node type")
+		}
+	}()
+	NewComparable[*int]()
+}
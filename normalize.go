@@ -0,0 +1,37 @@
+package rendezvous
+
+// WithKeyNormalizer canonicalizes every key before it is hashed by passing
+// it through each of normalizers in turn, so that every service placing
+// keys with the same options normalizes them identically. For example,
+// strings.TrimSpace and strings.ToLower make lookups case- and
+// whitespace-insensitive; the NFC normalizer of golang.org/x/text/unicode/norm
+// makes them insensitive to Unicode composition.
+//
+// Normalizers must be deterministic. They apply to every lookup taking a
+// key, but not to keys written to a KeyWriter, which are hashed as they are
+// streamed, nor to keys looked up with GetParts and GetNParts, whose parts
+// are binary, nor to the digests of GetChunk and GetChunks. Use those rather
+// than Get with a key built by PartsKey or from a digest, which normalizers
+// could corrupt: strings.ToLower, for example, maps every invalid UTF-8 byte
+// to U+FFFD, merging distinct keys.
+func WithKeyNormalizer(normalizers ...func(key string) string) Option {
+	return func(c *config) {
+		c.normalizers = append(c.normalizers, normalizers...)
+	}
+}
+
+// keyBytes returns key, normalized, as bytes to hash.
+func (h *Hash[N]) keyBytes(key string) []byte {
+	if h.normalizers != nil {
+		key = h.normalize(key)
+	}
+	return unsafeBytes(key)
+}
+
+// normalize passes key through each normalizer in turn.
+func (h *Hash[N]) normalize(key string) string {
+	for _, normalize := range h.normalizers {
+		key = normalize(key)
+	}
+	return key
+}
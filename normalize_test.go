@@ -0,0 +1,63 @@
+package rendezvous
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHashWithKeyNormalizer(t *testing.T) {
+	nodes := []hashableString{"a", "b", "c", "d"}
+	hash := NewWithOptions(nodes, WithKeyNormalizer(strings.TrimSpace, strings.ToLower))
+	plain := New(nodes...)
+
+	for _, key := range sampleKeys {
+		variant := "  " + strings.ToUpper(key) + "\n"
+		got, _ := hash.Get(variant)
+		expected, _ := plain.Get(strings.ToLower(key))
+		if got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", variant, got, expected)
+		}
+		if got, expected := hash.GetN(2, variant), plain.GetN(2, strings.ToLower(key)); !slices.Equal(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", variant, got, expected)
+		}
+		if !hash.Owns(expected, variant) {
+			t.Errorf("key=%q - expected %v to own the normalized key", variant, expected)
+		}
+	}
+}
+
+func TestHashWithKeyNormalizerParts(t *testing.T) {
+	nodes := []hashableString{"a", "b", "c", "d"}
+	hash := NewWithOptions(nodes, WithKeyNormalizer(strings.ToLower))
+	plain := New(nodes...)
+
+	// Parts are binary and placed as they are, so invalid UTF-8 bytes that
+	// strings.ToLower would map to U+FFFD don't merge.
+	for _, parts := range [][][]byte{
+		{{0xff}, []byte("x")},
+		{{0xfe}, []byte("x")},
+		{[]byte("Tenant"), []byte("X")},
+	} {
+		if got, expected := hash.GetNParts(len(nodes), parts...), plain.GetNParts(len(nodes), parts...); !slices.Equal(got, expected) {
+			t.Errorf("parts=%q - got: %v, expected: %v", parts, got, expected)
+		}
+		got, _ := hash.GetParts(parts...)
+		if expected, _ := plain.GetParts(parts...); got != expected {
+			t.Errorf("parts=%q - got: %v, expected: %v", parts, got, expected)
+		}
+	}
+	// With a hasher that mixes every key bit, such parts place apart. The
+	// default CRC32C is linear, so keys of one length differing in a single
+	// byte rank nodes alike.
+	hash = NewWithOptions(nodes, WithKeyNormalizer(strings.ToLower), WithHasher(Hash128))
+	distinct := false
+	for b := range 0x80 {
+		first := hash.GetNParts(len(nodes), []byte{0xff, byte(b)}, []byte("x"))
+		second := hash.GetNParts(len(nodes), []byte{0xfe, byte(b)}, []byte("x"))
+		distinct = distinct || !slices.Equal(first, second)
+	}
+	if !distinct {
+		t.Error("got the same placement for every pair of distinct parts")
+	}
+}
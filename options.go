@@ -0,0 +1,40 @@
+package rendezvous
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a Hash created by NewWithOptions.
+type Option func(*config)
+
+// config holds the settings applied by Options.
+type config struct {
+	audit     AuditLog
+	cacheSize int
+	// standbySize is the number of keys whose standby node is cached, if
+	// positive.
+	standbySize int
+	hasher      Hasher
+	layout      Layout
+	// parallelism is the number of goroutines used to score nodes.
+	parallelism int
+	// stats enables lookup statistics, counting lookups slower than
+	// statsBudget.
+	stats       bool
+	statsBudget time.Duration
+	// profileCtx, if set, holds the base labels of profiled operations.
+	profileCtx context.Context
+	scoreFunc  ScoreFunc
+	// normalizers canonicalize keys before they are hashed.
+	normalizers []func(string) string
+	// sampleSize is the number of lookups sampled, if positive.
+	sampleSize int
+}
+
+// WithAuditLog records every membership change of the Hash to log.
+func WithAuditLog(log AuditLog) Option {
+	return func(c *config) {
+		c.audit = log
+	}
+}
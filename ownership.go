@@ -0,0 +1,102 @@
+package rendezvous
+
+import (
+	"iter"
+	"strconv"
+)
+
+// exampleKeySearchLimit bounds the number of candidate keys ExampleKeysFor
+// tries, so it terminates for nodes that own little or none of the keyspace.
+const exampleKeySearchLimit = 1 << 20
+
+// Owns reports whether node owns key, meaning Get would return it. It stops
+// scoring as soon as another node outranks node, so it is usually cheaper
+// than calling Get and comparing the result.
+func (h *Hash[N]) Owns(node N, key string) bool {
+	i := h.find(h.nodeID(node))
+	return i >= 0 && h.beaten(i, h.keyBytes(key), 1) == 0
+}
+
+// ReplicaIndex returns node's position in the ranking of nodes for key,
+// where 0 is the primary replica, or -1 if node is not among the first maxN
+// nodes or not in the Hash. It matches node's index in the result of
+// GetN(maxN, key), without ranking the nodes beyond it.
+func (h *Hash[N]) ReplicaIndex(node N, key string, maxN int) int {
+	i := h.find(h.nodeID(node))
+	if i < 0 || maxN <= 0 {
+		return -1
+	}
+	if rank := h.beaten(i, h.keyBytes(key), maxN); rank < maxN {
+		return rank
+	}
+	return -1
+}
+
+// OwnedBy returns the keys of keys that node owns, meaning Get would return
+// it. Keys are checked lazily, against the topology at the time each key is
+// reached. If node isn't in the Hash, the result is empty.
+func (h *Hash[N]) OwnedBy(node N, keys iter.Seq[string]) iter.Seq[string] {
+	return h.ReplicatedBy(node, 1, keys)
+}
+
+// ReplicatedBy returns the keys of keys for which node is one of the first
+// replicas nodes returned by GetN. It is OwnedBy for replicated data.
+func (h *Hash[N]) ReplicatedBy(node N, replicas int, keys iter.Seq[string]) iter.Seq[string] {
+	id := h.nodeID(node)
+	return func(yield func(string) bool) {
+		for key := range keys {
+			i := h.find(id)
+			if i < 0 {
+				return
+			}
+			if h.beaten(i, h.keyBytes(key), replicas) < replicas && !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// beaten returns the number of nodes ranked above the node at index i for
+// keyBytes, counting no further than limit.
+func (h *Hash[N]) beaten(i int, keyBytes []byte, limit int) int {
+	target := &h.nodes[i]
+	h.scorer.begin(h.layout, keyBytes)
+	score := h.scoreWith(&h.scorer, target)
+
+	count := 0
+	for j := range h.nodes {
+		if count >= limit {
+			break
+		}
+		if j == i {
+			continue
+		}
+		other := h.scoreWith(&h.scorer, &h.nodes[j])
+		if other > score || (other == score && h.compareTie(h.nodes[j].id, target.id) < 0) {
+			count++
+		}
+	}
+	return count
+}
+
+// ExampleKeysFor returns count keys owned by node, for use as test fixtures
+// or to reproduce node-specific issues. Keys are found by trying
+// "example-0", "example-1" and so on, so the result is deterministic for a
+// given topology. Fewer keys are returned if node owns too small a share of
+// the keyspace to find count of them in a bounded search, or none if node
+// isn't in the Hash.
+func (h *Hash[N]) ExampleKeysFor(node N, count int) []string {
+	i := h.find(h.nodeID(node))
+	if i < 0 || count <= 0 {
+		return nil
+	}
+
+	var keys []string
+	for n := 0; n < exampleKeySearchLimit && len(keys) < count; n++ {
+		key := "example-" + strconv.Itoa(n)
+		if h.beaten(i, h.keyBytes(key), 1) == 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
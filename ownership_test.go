@@ -0,0 +1,93 @@
+package rendezvous
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHashOwnedBy(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	keys := slices.Values(sampleKeys)
+
+	for _, node := range hash.Nodes() {
+		var owned, replicated []string
+		for _, key := range sampleKeys {
+			if got, _ := hash.Get(key); got == node {
+				owned = append(owned, key)
+			}
+			if slices.Contains(hash.GetN(3, key), node) {
+				replicated = append(replicated, key)
+			}
+		}
+
+		if got := slices.Collect(hash.OwnedBy(node, keys)); !slices.Equal(got, owned) {
+			t.Errorf("node=%v - got: %v, expected: %v", node, got, owned)
+		}
+		if got := slices.Collect(hash.ReplicatedBy(node, 3, keys)); !slices.Equal(got, replicated) {
+			t.Errorf("node=%v - got: %v, expected: %v", node, got, replicated)
+		}
+	}
+
+	if got := slices.Collect(hash.OwnedBy("z", keys)); len(got) != 0 {
+		t.Errorf("got %v, expected no keys for a missing node", got)
+	}
+}
+
+func TestHashOwns(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	for _, key := range sampleKeys {
+		owner, _ := hash.Get(key)
+		for _, node := range hash.Nodes() {
+			if got := hash.Owns(node, key); got != (node == owner) {
+				t.Errorf("key=%q node=%v - got: %t, expected: %t", key, node, got, node == owner)
+			}
+		}
+		if hash.Owns("z", key) {
+			t.Errorf("key=%q - a missing node owns the key", key)
+		}
+	}
+}
+
+func TestHashReplicaIndex(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	for _, key := range sampleKeys {
+		replicas := hash.GetN(3, key)
+		for _, node := range hash.Nodes() {
+			if got, expected := hash.ReplicaIndex(node, key, 3), slices.Index(replicas, node); got != expected {
+				t.Errorf("key=%q node=%v - got: %d, expected: %d", key, node, got, expected)
+			}
+		}
+		if got := hash.ReplicaIndex("z", key, 3); got != -1 {
+			t.Errorf("key=%q - got: %d, expected: -1 for a missing node", key, got)
+		}
+	}
+}
+
+func TestHashExampleKeysFor(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d", "e")
+	for _, node := range hash.Nodes() {
+		keys := hash.ExampleKeysFor(node, 20)
+		if len(keys) != 20 {
+			t.Fatalf("node=%v - got %d keys, expected 20", node, len(keys))
+		}
+		for _, key := range keys {
+			if got, _ := hash.Get(key); got != node {
+				t.Errorf("node=%v key=%q - got owner %v", node, key, got)
+			}
+		}
+		if !slices.Equal(keys, hash.ExampleKeysFor(node, 20)) {
+			t.Errorf("node=%v - got different keys on a second call", node)
+		}
+	}
+	if keys := hash.ExampleKeysFor("z", 1); keys != nil {
+		t.Errorf("got %v, expected no keys for a missing node", keys)
+	}
+}
+
+func BenchmarkHashOwns_10nodes(b *testing.B) {
+	hash := New(hashableString("a"), hashableString("b"), hashableString("c"), hashableString("d"), hashableString("e"), hashableString("f"), hashableString("g"), hashableString("h"), hashableString("i"), hashableString("j"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash.Owns("a", sampleKeys[i%len(sampleKeys)])
+	}
+}
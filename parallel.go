@@ -0,0 +1,106 @@
+package rendezvous
+
+import "sync"
+
+// parallelMinNodes is the fewest nodes each goroutine scores when scoring in
+// parallel. Below it, the cost of starting goroutines outweighs the gain.
+const parallelMinNodes = 1024
+
+// WithParallelism splits the scoring done by Get and GetN across up to
+// workers goroutines. It only pays off for very large Hashes, so scoring
+// stays on the calling goroutine unless every goroutine would score at
+// least 1024 nodes. Results are identical to scoring on a single goroutine.
+func WithParallelism(workers int) Option {
+	return func(c *config) {
+		c.parallelism = workers
+	}
+}
+
+// setParallelism allocates a scorer for each of up to workers goroutines.
+func (h *Hash[N]) setParallelism(workers int) {
+	h.workers = nil
+	if workers < 2 {
+		return
+	}
+	h.workers = make([]scorer, workers)
+	for i := range h.workers {
+		h.workers[i].digest = h.hasher.newDigest()
+	}
+}
+
+// parallelWorkers returns the number of goroutines to score n nodes with,
+// or 0 if n is too small to be worth scoring in parallel.
+func (h *Hash[N]) parallelWorkers(n int) int {
+	if workers := min(len(h.workers), n/parallelMinNodes); workers >= 2 {
+		return workers
+	}
+	return 0
+}
+
+// parallelFor splits the indexes [0, n) into contiguous chunks, one for each
+// of workers goroutines, and calls fn for each chunk on its own goroutine
+// with the chunk's worker number and scorer.
+func (h *Hash[N]) parallelFor(workers, n int, fn func(worker int, s *scorer, lo, hi int)) {
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(w, &h.workers[w], w*chunk, min((w+1)*chunk, n))
+		}()
+	}
+	wg.Wait()
+}
+
+// topParallel is top, scoring nodes in parallel. It reports false if the
+// Hash is too small to be worth scoring in parallel.
+func (h *Hash[N]) topParallel(keyBytes []byte) (int, float64, bool) {
+	workers := h.parallelWorkers(len(h.nodes))
+	if workers == 0 {
+		return 0, 0, false
+	}
+
+	type best struct {
+		index int
+		score float64
+	}
+	bests := make([]best, workers)
+	h.parallelFor(workers, len(h.nodes), func(worker int, s *scorer, lo, hi int) {
+		s.begin(h.layout, keyBytes)
+		b := best{lo, h.scoreWith(s, &h.nodes[lo])}
+		for i := lo + 1; i < hi; i++ {
+			score := h.scoreWith(s, &h.nodes[i])
+			if score > b.score || (score == b.score && h.compareTieWith(s, h.nodes[i].id, h.nodes[b.index].id) < 0) {
+				b = best{i, score}
+			}
+		}
+		bests[worker] = b
+	})
+
+	h.scorer.begin(h.layout, keyBytes)
+	b := bests[0]
+	for _, other := range bests[1:] {
+		if other.score > b.score || (other.score == b.score && h.compareTie(h.nodes[other.index].id, h.nodes[b.index].id) < 0) {
+			b = other
+		}
+	}
+	return b.index, b.score, true
+}
+
+// scoreOrderParallel sets the score of every node in h.order for keyBytes,
+// scoring in parallel. It reports false if h.order is too short to be worth
+// scoring in parallel.
+func (h *Hash[N]) scoreOrderParallel(keyBytes []byte) bool {
+	workers := h.parallelWorkers(len(h.order))
+	if workers == 0 {
+		return false
+	}
+	h.parallelFor(workers, len(h.order), func(_ int, s *scorer, lo, hi int) {
+		s.begin(h.layout, keyBytes)
+		for _, i := range h.order[lo:hi] {
+			h.nodes[i].score = h.scoreWith(s, &h.nodes[i])
+		}
+	})
+	return true
+}
@@ -0,0 +1,52 @@
+package rendezvous
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestHashParallelism(t *testing.T) {
+	nodes := make([]hashableString, 4*parallelMinNodes+7)
+	for i := range nodes {
+		nodes[i] = hashableString(fmt.Sprintf("node-%d", i))
+	}
+	sequential := New(nodes...)
+	parallel := NewWithOptions(nodes, WithParallelism(4))
+	parallel.SetWeight(nodes[0], 2)
+	sequential.SetWeight(nodes[0], 2)
+
+	for _, key := range sampleKeys {
+		expected, _ := sequential.Get(key)
+		if got, _ := parallel.Get(key); got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+		if got, expected := parallel.GetN(10, key), sequential.GetN(10, key); !slices.Equal(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+}
+
+func BenchmarkHashGet_16384nodes_parallel(b *testing.B) {
+	nodes := make([]hashableString, 16384)
+	for i := range nodes {
+		nodes[i] = hashableString(fmt.Sprintf("node-%d", i))
+	}
+	hash := NewWithOptions(nodes, WithParallelism(8))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash.Get(sampleKeys[i%len(sampleKeys)])
+	}
+}
+
+func BenchmarkHashGet_16384nodes(b *testing.B) {
+	nodes := make([]hashableString, 16384)
+	for i := range nodes {
+		nodes[i] = hashableString(fmt.Sprintf("node-%d", i))
+	}
+	hash := New(nodes...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash.Get(sampleKeys[i%len(sampleKeys)])
+	}
+}
@@ -0,0 +1,59 @@
+package rendezvous
+
+import "encoding/binary"
+
+// PartsKey encodes a key made of several parts unambiguously, prefixing
+// each part with its length as a big-endian uint32, so that parts such as
+// "a", "bc" and "ab", "c" never encode to the same key as they would if
+// concatenated.
+func PartsKey(parts ...[]byte) string {
+	size := 0
+	for _, part := range parts {
+		size += 4 + len(part)
+	}
+	key := make([]byte, 0, size)
+	for _, part := range parts {
+		key = binary.BigEndian.AppendUint32(key, uint32(len(part)))
+		key = append(key, part...)
+	}
+	return string(key)
+}
+
+// GetParts is Get for the key made of parts, as encoded by PartsKey. Parts
+// are binary, so key normalizers don't apply to them, and such lookups on a
+// Hash with normalizers bypass the lookup cache, statistics and sampling,
+// which follow normalized keys.
+func (h *Hash[N]) GetParts(parts ...[]byte) (N, bool) {
+	if h.normalizers == nil {
+		return h.Get(PartsKey(parts...))
+	}
+	i, _ := h.top(unsafeBytes(PartsKey(parts...)))
+	if i < 0 {
+		var zero N
+		return zero, false
+	}
+	return h.nodes[i].node, true
+}
+
+// GetNParts is GetN for the key made of parts, as encoded by PartsKey,
+// without key normalizers, as for GetParts.
+func (h *Hash[N]) GetNParts(n int, parts ...[]byte) []N {
+	if h.normalizers == nil {
+		return h.GetN(n, PartsKey(parts...))
+	}
+	return h.getNBinary(n, unsafeBytes(PartsKey(parts...)))
+}
+
+// getNBinary is GetN for a binary key, hashed as it is: without key
+// normalizers, and bypassing the lookup statistics and sampling.
+func (h *Hash[N]) getNBinary(n int, key []byte) []N {
+	if len(h.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	h.rank(key)
+	nodes := make([]N, min(n, len(h.order)))
+	for i := range nodes {
+		nodes[i] = h.nodes[h.order[i]].node
+	}
+	return nodes
+}
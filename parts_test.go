@@ -0,0 +1,30 @@
+package rendezvous
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPartsKey(t *testing.T) {
+	if PartsKey([]byte("a"), []byte("bc")) == PartsKey([]byte("ab"), []byte("c")) {
+		t.Error("got the same key for different splits of the same bytes")
+	}
+	if PartsKey([]byte("a"), nil) == PartsKey([]byte("a")) {
+		t.Error("got the same key with and without an empty part")
+	}
+	if got, expected := PartsKey([]byte("ab")), "\x00\x00\x00\x02ab"; got != expected {
+		t.Errorf("got: %q, expected: %q", got, expected)
+	}
+}
+
+func TestHashGetParts(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	parts := [][]byte{[]byte("tenant-1"), []byte("user-42")}
+	got, ok := hash.GetParts(parts...)
+	if expected, _ := hash.Get(PartsKey(parts...)); !ok || got != expected {
+		t.Errorf("got: %v, expected: %v", got, expected)
+	}
+	if got, expected := hash.GetNParts(2, parts...), hash.GetN(2, PartsKey(parts...)); !slices.Equal(got, expected) {
+		t.Errorf("got: %v, expected: %v", got, expected)
+	}
+}
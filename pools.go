@@ -0,0 +1,201 @@
+package rendezvous
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// nodePool is the pool of a node, along with the number of callers of
+// PoolFor that have yet to release it.
+type nodePool[N, P any] struct {
+	node N
+	pool P
+	refs int
+	// removed records that the node left the Hash, so the pool is closed
+	// once its last reference is released.
+	removed bool
+}
+
+// Pools maintains a connection pool, or any other per-node resource, for
+// each node of a Hash. Pools follow the Hash's membership: a pool is opened
+// for each node added, and the pool of each node removed is drained, taking
+// no new callers, and closed once every caller has released it.
+//
+// Pools sync with the Hash lazily, when PoolFor finds its epoch changed, or
+// when Sync is called. Pools is safe for concurrent use. Set Locker if the
+// Hash is modified concurrently with it.
+type Pools[N, P any] struct {
+	// Locker, if set, is held while the Hash is read.
+	Locker sync.Locker
+	// OnError, if set, is called with errors closing the pools of removed
+	// nodes.
+	OnError func(node N, err error)
+
+	hash  *Hash[N]
+	open  func(N) (P, error)
+	close func(P) error
+
+	mu    sync.Mutex
+	pools map[string]*nodePool[N, P]
+	// synced records that every node had its pool opened as of epoch.
+	synced bool
+	epoch  uint64
+}
+
+// NewPools returns Pools for the nodes of hash, opened with open and closed
+// with close. Pools are opened with the Pools' mutex held, so open should
+// not block for long, for example by dialing lazily.
+func NewPools[N, P any](hash *Hash[N], open func(N) (P, error), close func(P) error) *Pools[N, P] {
+	return &Pools[N, P]{hash: hash, open: open, close: close, pools: make(map[string]*nodePool[N, P])}
+}
+
+// PoolFor returns the pool of the node that owns key, and a function to
+// release it once the caller is done with it, which must be called exactly
+// once. It returns ErrNoNodes if the Hash is empty, or the error opening
+// the node's pool.
+func (p *Pools[N, P]) PoolFor(key string) (P, func(), error) {
+	p.mu.Lock()
+	p.lock()
+	closing, err := p.sync()
+	node, ok := p.hash.Get(key)
+	id := string(p.hash.Identity(node))
+	p.unlock()
+
+	np, found := p.pools[id]
+	if found && !np.removed {
+		np.refs++
+	}
+	p.mu.Unlock()
+	p.closePools(closing)
+
+	var zero P
+	switch {
+	case !ok:
+		return zero, nil, ErrNoNodes
+	case !found || np.removed:
+		if err == nil {
+			err = fmt.Errorf("rendezvous: no pool for node %q", id)
+		}
+		return zero, nil, err
+	}
+	var once sync.Once
+	return np.pool, func() { once.Do(func() { p.release(id, np) }) }, nil
+}
+
+// release releases a reference to np, closing it if it was the last
+// reference to the pool of a removed node.
+func (p *Pools[N, P]) release(id string, np *nodePool[N, P]) {
+	p.mu.Lock()
+	np.refs--
+	closing := np.removed && np.refs == 0
+	if closing && p.pools[id] == np {
+		delete(p.pools, id)
+	}
+	p.mu.Unlock()
+	if closing {
+		p.closePools([]*nodePool[N, P]{np})
+	}
+}
+
+// Sync opens the pools of nodes added to the Hash and drains the pools of
+// nodes removed from it. It returns the errors opening pools, which are
+// retried on the next Sync.
+func (p *Pools[N, P]) Sync() error {
+	p.mu.Lock()
+	p.lock()
+	closing, err := p.sync()
+	p.unlock()
+	p.mu.Unlock()
+	p.closePools(closing)
+	return err
+}
+
+// sync is Sync with the mutex and Locker held. It returns the pools to
+// close once the mutex is released, rather than closing them itself.
+func (p *Pools[N, P]) sync() (closing []*nodePool[N, P], err error) {
+	epoch := p.hash.Epoch()
+	if p.synced && epoch == p.epoch {
+		return nil, nil
+	}
+
+	var errs []error
+	current := make(map[string]bool)
+	for _, node := range p.hash.Nodes() {
+		id := string(p.hash.Identity(node))
+		current[id] = true
+		if np, ok := p.pools[id]; ok && !np.removed {
+			continue
+		}
+		pool, err := p.open(node)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rendezvous: opening pool for node %q: %w", id, err))
+			continue
+		}
+		if np, ok := p.pools[id]; ok {
+			// The node was removed and added back while its old pool was
+			// still in use; the old pool is closed on its last release.
+			np.removed = true
+		}
+		p.pools[id] = &nodePool[N, P]{node: node, pool: pool}
+	}
+
+	for id, np := range p.pools {
+		if current[id] || np.removed {
+			continue
+		}
+		np.removed = true
+		if np.refs == 0 {
+			delete(p.pools, id)
+			closing = append(closing, np)
+		}
+	}
+	p.synced, p.epoch = len(errs) == 0, epoch
+	return closing, errors.Join(errs...)
+}
+
+// closePools closes pools, reporting errors to OnError.
+func (p *Pools[N, P]) closePools(pools []*nodePool[N, P]) {
+	for _, np := range pools {
+		if err := p.close(np.pool); err != nil && p.OnError != nil {
+			p.OnError(np.node, err)
+		}
+	}
+}
+
+// Close drains every pool, closing those not in use, and returns the
+// errors closing them. Pools still in use are closed once released, with
+// errors reported to OnError. The Pools must not be used afterwards, other
+// than to release pools.
+func (p *Pools[N, P]) Close() error {
+	p.mu.Lock()
+	closing := make(map[string]*nodePool[N, P])
+	for id, np := range p.pools {
+		np.removed = true
+		if np.refs == 0 {
+			delete(p.pools, id)
+			closing[id] = np
+		}
+	}
+	p.mu.Unlock()
+
+	var errs []error
+	for id, np := range closing {
+		if err := p.close(np.pool); err != nil {
+			errs = append(errs, fmt.Errorf("rendezvous: closing pool for node %q: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Pools[N, P]) lock() {
+	if p.Locker != nil {
+		p.Locker.Lock()
+	}
+}
+
+func (p *Pools[N, P]) unlock() {
+	if p.Locker != nil {
+		p.Locker.Unlock()
+	}
+}
@@ -0,0 +1,92 @@
+package rendezvous
+
+import (
+	"errors"
+	"testing"
+)
+
+// testPool is a pool for tests, recording whether it was closed.
+type testPool struct {
+	node   hashableString
+	closed bool
+}
+
+func TestPools(t *testing.T) {
+	hash := New[hashableString]("a", "b")
+	opened := make(map[hashableString][]*testPool)
+	failing := hashableString("")
+	pools := NewPools(hash, func(node hashableString) (*testPool, error) {
+		if node == failing {
+			return nil, errors.New("refused")
+		}
+		pool := &testPool{node: node}
+		opened[node] = append(opened[node], pool)
+		return pool, nil
+	}, func(pool *testPool) error {
+		if pool.closed {
+			t.Errorf("node=%s - got pool closed twice", pool.node)
+		}
+		pool.closed = true
+		return nil
+	})
+
+	for _, key := range sampleKeys {
+		pool, release, err := pools.PoolFor(key)
+		if owner, _ := hash.Get(key); err != nil || pool.node != owner {
+			t.Fatalf("key=%q - got: %v, %v, expected the pool of %v", key, pool, err, owner)
+		}
+		release()
+	}
+	if len(opened["a"]) != 1 || len(opened["b"]) != 1 {
+		t.Errorf("got %v, expected one pool per node", opened)
+	}
+
+	// The pool of a removed node is drained, and closed on its last release.
+	var key string
+	for _, k := range sampleKeys {
+		if owner, _ := hash.Get(k); owner == "a" {
+			key = k
+			break
+		}
+	}
+	pool, release, _ := pools.PoolFor(key)
+	hash.Remove("a")
+	if err := pools.Sync(); err != nil || pool.closed {
+		t.Fatalf("got error %v, expected the pool in use to stay open", err)
+	}
+	if other, otherRelease, _ := pools.PoolFor(key); other.node != "b" {
+		t.Errorf("key=%q - got the pool of %s, expected b's once a is removed", key, other.node)
+	} else {
+		otherRelease()
+	}
+	release()
+	release()
+	if !pool.closed {
+		t.Errorf("got the removed node's pool open after its last release")
+	}
+
+	failing = "c"
+	hash.Add("c")
+	if err := pools.Sync(); err == nil {
+		t.Errorf("got no error, expected the pool of c to fail to open")
+	}
+	failing = ""
+	if err := pools.Sync(); err != nil || len(opened["c"]) != 1 {
+		t.Errorf("got error %v, expected the pool of c to be opened on retry", err)
+	}
+
+	if err := pools.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for node, opened := range opened {
+		for _, pool := range opened {
+			if !pool.closed {
+				t.Errorf("node=%s - got a pool left open after Close", node)
+			}
+		}
+	}
+
+	if _, _, err := NewPools[hashableString, *testPool](New[hashableString](), nil, nil).PoolFor("key"); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("got error %v, expected ErrNoNodes", err)
+	}
+}
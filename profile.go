@@ -0,0 +1,58 @@
+package rendezvous
+
+import (
+	"context"
+	"math/bits"
+	"runtime/pprof"
+	"strconv"
+)
+
+// Operations are only labelled for profiling when they are large enough to
+// matter: GetN and Apply on Hashes of at least profileMinNodes nodes, and
+// Apply of changesets of at least profileMinChanges changes. Table rebuilds
+// are always labelled.
+const (
+	profileMinNodes   = 256
+	profileMinChanges = 16
+)
+
+// WithProfileLabels tags expensive operations with pprof labels while they
+// run, so that CPU profiles attribute their time to the Hash. The label
+// "rendezvous.op" names the operation: "apply", "getn" or "table.rebuild",
+// for Tables created by NewTableWithOptions. The label "rendezvous.nodes"
+// gives the number of nodes rounded up to a power of two.
+//
+// Labels are added to those of ctx, which should carry any labels the
+// goroutines using the Hash run with: when a labelled operation returns,
+// the goroutine's labels are reset to those of ctx. Pass
+// context.Background() if the goroutines have no labels of their own.
+func WithProfileLabels(ctx context.Context) Option {
+	return func(c *config) {
+		c.profileCtx = ctx
+	}
+}
+
+// profiled runs fn with pprof labels identifying op and the size of the
+// Hash. Callers check that h.profileCtx is set first.
+func (h *Hash[N]) profiled(op string, fn func()) {
+	labels := pprof.Labels("rendezvous.op", op, "rendezvous.nodes", nodesLabel(len(h.nodes)))
+	pprof.Do(h.profileCtx, labels, func(ctx context.Context) {
+		if profiledHook != nil {
+			profiledHook(ctx)
+		}
+		fn()
+	})
+}
+
+// profiledHook, if set, is called with the labelled context of each
+// profiled operation, for tests to check its labels.
+var profiledHook func(ctx context.Context)
+
+// nodesLabel rounds n up to a power of two, keeping the number of distinct
+// label values small.
+func nodesLabel(n int) string {
+	if n <= 1 {
+		return strconv.Itoa(n)
+	}
+	return strconv.Itoa(1 << bits.Len(uint(n-1)))
+}
@@ -0,0 +1,72 @@
+package rendezvous
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"slices"
+	"testing"
+)
+
+func TestHashProfileLabels(t *testing.T) {
+	nodes := make([]hashableString, profileMinNodes)
+	for i := range nodes {
+		nodes[i] = hashableString(fmt.Sprintf("node-%d", i))
+	}
+	labelled := NewWithOptions(nodes, WithProfileLabels(context.Background()))
+	plain := NewWithOptions(nodes)
+
+	for _, key := range sampleKeys {
+		if got, expected := labelled.GetN(3, key), plain.GetN(3, key); !slices.Equal(got, expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+	if err := labelled.Apply(Changeset[hashableString]{Remove: nodes[:10]}); err != nil || len(labelled.Nodes()) != profileMinNodes-10 {
+		t.Errorf("got error %v and %d nodes, expected the labelled Apply to remove 10", err, len(labelled.Nodes()))
+	}
+}
+
+func TestProfileLabelValues(t *testing.T) {
+	var ops, sizes []string
+	profiledHook = func(ctx context.Context) {
+		op, _ := pprof.Label(ctx, "rendezvous.op")
+		size, _ := pprof.Label(ctx, "rendezvous.nodes")
+		ops, sizes = append(ops, op), append(sizes, size)
+	}
+	defer func() { profiledHook = nil }()
+
+	nodes := make([]hashableString, profileMinNodes)
+	for i := range nodes {
+		nodes[i] = hashableString(fmt.Sprintf("node-%d", i))
+	}
+	hash := NewWithOptions(nodes, WithProfileLabels(context.Background()))
+	ops, sizes = nil, nil
+	hash.GetN(3, "key")
+	if !slices.Equal(ops, []string{"getn"}) || !slices.Equal(sizes, []string{"256"}) {
+		t.Errorf("got ops %v with sizes %v, expected a getn of 256 nodes", ops, sizes)
+	}
+
+	// Adding a node of another weight rebuilds the Table.
+	members := []zonedNode{{"a", "", 1}, {"b", "", 1}, {"c", "", 1}}
+	table := NewTableWithOptions(64, members, WithProfileLabels(context.Background()))
+	ops, sizes = nil, nil
+	table.Add(zonedNode{"d", "", 2})
+	if !slices.Equal(ops, []string{"table.rebuild"}) || !slices.Equal(sizes, []string{"4"}) {
+		t.Errorf("got ops %v with sizes %v, expected a table.rebuild of 4 nodes", ops, sizes)
+	}
+	plain := NewTable(64, append(members, zonedNode{"d", "", 2})...)
+	for p := range table.Partitions() {
+		got, _ := table.Owner(p)
+		if expected, _ := plain.Owner(p); got != expected {
+			t.Errorf("partition=%d - got: %v, expected: %v", p, got, expected)
+		}
+	}
+}
+
+func TestNodesLabel(t *testing.T) {
+	for n, expected := range map[int]string{0: "0", 1: "1", 2: "2", 3: "4", 256: "256", 257: "512"} {
+		if got := nodesLabel(n); got != expected {
+			t.Errorf("n=%d - got: %v, expected: %v", n, got, expected)
+		}
+	}
+}
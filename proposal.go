@@ -0,0 +1,95 @@
+package rendezvous
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrUnknownProposal is returned by Commit for a proposal ID that was
+	// never issued, or was already committed or discarded.
+	ErrUnknownProposal = errors.New("rendezvous: unknown proposal")
+	// ErrStaleProposal is returned by Commit when the topology changed after
+	// the proposal was made, so its projected impact no longer holds.
+	ErrStaleProposal = errors.New("rendezvous: topology changed since proposal")
+)
+
+// proposalSamples is the number of keys sampled to project a proposal's
+// impact.
+const proposalSamples = 10000
+
+// Proposal describes the projected impact of a changeset without applying
+// it. Impact is estimated by sampling the same deterministic keys as
+// SampleShares.
+type Proposal[N any] struct {
+	ID      uint64
+	Changes Changeset[N]
+	// Epoch is the epoch of the Hash the proposal was made against.
+	Epoch uint64
+	// Moved is the fraction of sampled keys whose owner would change.
+	Moved float64
+	// Before and After are the sampled shares of the current and projected
+	// topologies.
+	Before, After []Share[N]
+}
+
+// Propose projects the impact of applying changes without modifying h. The
+// returned proposal can be applied with Commit until h's topology changes.
+// Propose returns an error if changes can't be applied.
+func (h *Hash[N]) Propose(changes Changeset[N]) (*Proposal[N], error) {
+	projected := h.clone()
+	if err := projected.Apply(changes); err != nil {
+		return nil, err
+	}
+
+	h.lastProposal++
+	proposal := &Proposal[N]{
+		ID:      h.lastProposal,
+		Changes: changes,
+		Epoch:   h.epoch,
+		Moved:   h.sampleMoved(projected),
+		Before:  h.SampleShares(proposalSamples),
+		After:   projected.SampleShares(proposalSamples),
+	}
+	if h.proposals == nil {
+		h.proposals = make(map[uint64]*Proposal[N])
+	}
+	h.proposals[proposal.ID] = proposal
+	return proposal, nil
+}
+
+// sampleMoved returns the fraction of sampled keys whose owner differs
+// between h and projected.
+func (h *Hash[N]) sampleMoved(projected *Hash[N]) float64 {
+	moved := 0
+	for i := 0; i < proposalSamples; i++ {
+		key := unsafeBytes("sample-" + strconv.Itoa(i))
+		before, _ := h.top(key)
+		after, _ := projected.top(key)
+		if (before < 0) != (after < 0) || (before >= 0 && !bytes.Equal(h.nodes[before].id, projected.nodes[after].id)) {
+			moved++
+		}
+	}
+	return float64(moved) / proposalSamples
+}
+
+// Commit applies the changeset of the proposal with the given ID. It returns
+// ErrStaleProposal, and discards the proposal, if h's topology changed after
+// the proposal was made.
+func (h *Hash[N]) Commit(proposalID uint64) error {
+	proposal, ok := h.proposals[proposalID]
+	if !ok {
+		return ErrUnknownProposal
+	}
+	delete(h.proposals, proposalID)
+	if proposal.Epoch != h.epoch {
+		return ErrStaleProposal
+	}
+	return h.Apply(proposal.Changes)
+}
+
+// Discard forgets the proposal with the given ID without applying it.
+func (h *Hash[N]) Discard(proposalID uint64) {
+	delete(h.proposals, proposalID)
+}
@@ -0,0 +1,47 @@
+package rendezvous
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestHashProposeCommit(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	epoch := hash.Epoch()
+
+	proposal, err := hash.Propose(Changeset[hashableString]{Remove: []hashableString{"d"}})
+	if err != nil {
+		t.Fatalf("got error %v, expected none", err)
+	}
+	if hash.Epoch() != epoch || len(hash.Nodes()) != 4 {
+		t.Errorf("Propose modified the Hash")
+	}
+	if len(proposal.Before) != 4 || len(proposal.After) != 3 {
+		t.Errorf("got %d shares before and %d after, expected 4 and 3", len(proposal.Before), len(proposal.After))
+	}
+	if expected := proposal.Before[3].Fraction; math.Abs(proposal.Moved-expected) > 1e-9 {
+		t.Errorf("got moved fraction %v, expected only d's share %v to move", proposal.Moved, expected)
+	}
+
+	if err := hash.Commit(proposal.ID); err != nil {
+		t.Fatalf("got error %v, expected none", err)
+	}
+	if got, expected := hash.Nodes(), []hashableString{"a", "b", "c"}; !slices.Equal(got, expected) {
+		t.Errorf("got: %v, expected: %v", got, expected)
+	}
+	if err := hash.Commit(proposal.ID); !errors.Is(err, ErrUnknownProposal) {
+		t.Errorf("got error %v, expected ErrUnknownProposal for a committed proposal", err)
+	}
+
+	stale, _ := hash.Propose(Changeset[hashableString]{Add: []hashableString{"e"}})
+	hash.Add("f")
+	if err := hash.Commit(stale.ID); !errors.Is(err, ErrStaleProposal) {
+		t.Errorf("got error %v, expected ErrStaleProposal", err)
+	}
+
+	if _, err := hash.Propose(Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "z", Weight: 2}}}); err == nil {
+		t.Errorf("got no error, expected one for an invalid changeset")
+	}
+}
@@ -0,0 +1,224 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// Version orders the topologies exchanged by Gossip. A topology is newer
+// than another if its Counter is higher, or if the Counters are equal and
+// its Origin sorts later, so that every member agrees on the newest.
+type Version struct {
+	Counter uint64 `json:"counter"`
+	// Origin is the member that made the change.
+	Origin string `json:"origin"`
+}
+
+// newer reports whether v is newer than other.
+func (v Version) newer(other Version) bool {
+	if v.Counter != other.Counter {
+		return v.Counter > other.Counter
+	}
+	return v.Origin > other.Origin
+}
+
+// gossipState is the message exchanged by Gossip members.
+type gossipState[N any] struct {
+	Version  Version   `json:"version"`
+	Topology Update[N] `json:"topology"`
+}
+
+// Gossip keeps the Hashes of a group of members in sync without a central
+// server. Each member periodically exchanges its topology's Version with a
+// random peer, and whichever holds the older topology adopts the newer one,
+// so a member that missed a change catches up within a few rounds.
+//
+// Gossip is an http.Handler that peers send their state to. A peer's state
+// replaces the member's whole topology, so every request must pass
+// Authorize.
+type Gossip[N any] struct {
+	// Authorize reports whether a peer's request may be served. It is
+	// required: a Gossip without one rejects every request. An authorizer
+	// such as admin.BearerToken pairs with an Authenticate adding the token.
+	Authorize func(*http.Request) bool
+	// Authenticate, if set, is called with each request sent to a peer, to
+	// add the credentials the peer's Authorize expects.
+	Authenticate func(*http.Request)
+	// Locker, if set, is held while the Hash is read or modified, for
+	// Hashes used concurrently.
+	Locker sync.Locker
+	// OnError, if set, is called with errors from exchanges with peers.
+	OnError func(error)
+	// HTTPClient makes the exchanges. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	hash  *rendezvous.Hash[N]
+	self  string
+	peers []string
+
+	mu       sync.Mutex
+	version  Version
+	topology Update[N]
+}
+
+// maxStateBytes bounds the size of the states peers send.
+const maxStateBytes = 64 << 20
+
+// NewGossip returns a member named self that keeps hash in sync with the
+// members at the URLs in peers. The Hash's current topology starts at the
+// zeroth version, so any change made by a peer supersedes it.
+func NewGossip[N any](hash *rendezvous.Hash[N], self string, peers []string) *Gossip[N] {
+	g := &Gossip[N]{hash: hash, self: self, peers: peers}
+	g.version = Version{Origin: self}
+	return g
+}
+
+// Version returns the version of the member's topology.
+func (g *Gossip[N]) Version() Version {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.version
+}
+
+// Update records a change made to the Hash locally under a version newer
+// than any the member has seen, to be spread to its peers.
+func (g *Gossip[N]) Update() {
+	topology, _ := snapshot(g.hash, g.Locker)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.version = Version{Counter: g.version.Counter + 1, Origin: g.self}
+	g.topology = topology
+}
+
+// state returns the member's current state. A member that has neither
+// updated nor adopted a topology reads its Hash.
+func (g *Gossip[N]) state() gossipState[N] {
+	g.mu.Lock()
+	state := gossipState[N]{Version: g.version, Topology: g.topology}
+	g.mu.Unlock()
+	if !state.Topology.Snapshot {
+		state.Topology, _ = snapshot(g.hash, g.Locker)
+	}
+	return state
+}
+
+// adopt applies state to the Hash if it is newer than the member's, and
+// reports whether it did.
+func (g *Gossip[N]) adopt(state gossipState[N]) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !state.Version.newer(g.version) {
+		return false, nil
+	}
+	nodes := make(map[string]Node[N], len(state.Topology.Nodes))
+	for _, node := range state.Topology.Nodes {
+		nodes[node.ID] = node
+	}
+	actor := fmt.Sprintf("gossip:%s@%d", state.Version.Origin, state.Version.Counter)
+	if err := apply(g.hash, g.Locker, actor, nodes, state.Topology.ZoneWeights, state.Topology.Canaries); err != nil {
+		return false, err
+	}
+	g.version, g.topology = state.Version, state.Topology
+	g.topology.Snapshot = true
+	return true, nil
+}
+
+// ServeHTTP handles a POST of a peer's state: the member adopts it if it is
+// newer, and otherwise replies with its own state if that is newer.
+func (g *Gossip[N]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if g.Authorize == nil || !g.Authorize(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var theirs gossipState[N]
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxStateBytes)).Decode(&theirs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := g.adopt(theirs); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	ours := g.state()
+	if !ours.Version.newer(theirs.Version) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ours)
+}
+
+// Round exchanges state with one peer chosen at random.
+func (g *Gossip[N]) Round(ctx context.Context) error {
+	if len(g.peers) == 0 {
+		return nil
+	}
+	return g.exchange(ctx, g.peers[rand.IntN(len(g.peers))])
+}
+
+// exchange sends the member's state to peer and adopts the reply, if any.
+func (g *Gossip[N]) exchange(ctx context.Context, peer string) error {
+	body, err := json.Marshal(g.state())
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, peer, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if g.Authenticate != nil {
+		g.Authenticate(request)
+	}
+	client := g.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusOK:
+		var theirs gossipState[N]
+		if err := json.NewDecoder(response.Body).Decode(&theirs); err != nil {
+			return fmt.Errorf("push: decoding state from %s: %w", peer, err)
+		}
+		_, err := g.adopt(theirs)
+		return err
+	default:
+		return fmt.Errorf("push: gossiping with %s: %s", peer, response.Status)
+	}
+}
+
+// Run calls Round every interval until ctx is done, and returns ctx's
+// error.
+func (g *Gossip[N]) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := g.Round(ctx); err != nil && g.OnError != nil && ctx.Err() == nil {
+				g.OnError(err)
+			}
+		}
+	}
+}
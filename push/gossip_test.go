@@ -0,0 +1,119 @@
+package push
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+func TestGossipConverges(t *testing.T) {
+	const members = 4
+	hashes := make([]*rendezvous.Hash[rendezvous.ConfigNode], members)
+	lockers := make([]sync.Mutex, members)
+	gossips := make([]*Gossip[rendezvous.ConfigNode], members)
+	urls := make([]string, members)
+	for i := range members {
+		hashes[i] = rendezvous.New(rendezvous.NewConfigNode("a", 1, ""))
+		gossips[i] = NewGossip(hashes[i], string(rune('0'+i)), nil)
+		gossips[i].Locker = &lockers[i]
+		gossips[i].Authorize = func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer secret" }
+		gossips[i].Authenticate = func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }
+		server := httptest.NewServer(gossips[i])
+		defer server.Close()
+		urls[i] = server.URL
+	}
+	for i := range members {
+		for j := range members {
+			if i != j {
+				gossips[i].peers = append(gossips[i].peers, urls[j])
+			}
+		}
+	}
+
+	lockers[2].Lock()
+	hashes[2].Add(rendezvous.NewConfigNode("b", 3, "west"))
+	hashes[2].SetZoneWeight("west", 2)
+	lockers[2].Unlock()
+	gossips[2].Update()
+
+	converged := func() bool {
+		for i := range members {
+			lockers[i].Lock()
+			equal := hashes[i].Equal(hashes[2])
+			lockers[i].Unlock()
+			if !equal {
+				return false
+			}
+		}
+		return true
+	}
+	for round := 0; !converged(); round++ {
+		if round == 100 {
+			t.Fatal("members did not converge after 100 rounds")
+		}
+		for _, g := range gossips {
+			if err := g.Round(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if version := gossips[0].Version(); version != (Version{Counter: 1, Origin: "2"}) {
+		t.Errorf("got version %+v, expected member 2's first change", version)
+	}
+}
+
+func TestGossipIgnoresOlderVersions(t *testing.T) {
+	hash := rendezvous.New(rendezvous.NewConfigNode("a", 1, ""))
+	g := NewGossip(hash, "1", nil)
+	g.Update()
+	g.Update()
+
+	older := gossipState[rendezvous.ConfigNode]{Version: Version{Counter: 1, Origin: "9"}}
+	if adopted, err := g.adopt(older); adopted || err != nil {
+		t.Errorf("got %v, %v, expected an older version to be ignored", adopted, err)
+	}
+	tied := gossipState[rendezvous.ConfigNode]{Version: Version{Counter: 2, Origin: "2"}}
+	if adopted, err := g.adopt(tied); !adopted || err != nil {
+		t.Errorf("got %v, %v, expected a tie to go to the later origin", adopted, err)
+	}
+	if len(hash.Nodes()) != 0 {
+		t.Errorf("got %v, expected the adopted empty topology", hash.Nodes())
+	}
+}
+
+func TestGossipAuthorize(t *testing.T) {
+	hash := rendezvous.New(rendezvous.NewConfigNode("a", 1, ""))
+	g := NewGossip(hash, "1", nil)
+	newer := `{"version":{"counter":9,"origin":"9"},"topology":{"snapshot":true}}`
+	post := func(body string, header string) int {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := post(newer, "Bearer secret"); code != http.StatusUnauthorized {
+		t.Errorf("got status %d without Authorize, expected 401", code)
+	}
+	g.Authorize = func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer secret" }
+	if code := post(newer, "Bearer wrong"); code != http.StatusUnauthorized {
+		t.Errorf("got status %d with the wrong token, expected 401", code)
+	}
+	if len(hash.Nodes()) != 1 {
+		t.Errorf("got %v, expected unauthorized states to be ignored", hash.Nodes())
+	}
+	if code := post(`{"version":{"counter":9,"origin":"`+strings.Repeat("x", maxStateBytes)+`"}}`, "Bearer secret"); code != http.StatusBadRequest {
+		t.Errorf("got status %d for an oversized state, expected 400", code)
+	}
+	if code := post(newer, "Bearer secret"); code != http.StatusNoContent || len(hash.Nodes()) != 0 {
+		t.Errorf("got status %d and %v, expected the authorized state adopted", code, hash.Nodes())
+	}
+}
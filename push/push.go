@@ -0,0 +1,411 @@
+// Package push keeps the Hashes of many routers in sync with one source of
+// truth. A Server streams topology updates over HTTP to subscribed Clients,
+// each of which applies them to a local rendezvous.Hash.
+//
+// Updates are newline-delimited JSON messages on a long-lived response. A
+// subscriber first receives a snapshot of the whole topology, then a delta
+// for each change published. Node values are encoded with encoding/json,
+// so N must round-trip through it, as rendezvous.ConfigNode does. Over
+// transports other than HTTP, such as a message bus, Updates may instead be
+// encoded compactly with the cbor package and applied with Client.Apply.
+//
+// The package has no gRPC service of its own, so that the module depends
+// on nothing outside the standard library. A gRPC server-streaming method
+// can instead be built on Server.Subscribe and Client.Apply.
+//
+// Gossip keeps a group of Hashes in sync without a central Server, with
+// each member exchanging its topology with random peers.
+package push
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// Node is a node in an Update. ID is the node's identity in the Server's
+// Hash, including one given with AddWithID, which Clients keep.
+type Node[N any] struct {
+	ID     string  `json:"id"`
+	Weight float64 `json:"weight"`
+	Value  N       `json:"value"`
+}
+
+// Update is a message streamed from a Server to its Clients.
+type Update[N any] struct {
+	// Epoch is the epoch of the Server's Hash after the update.
+	Epoch uint64 `json:"epoch"`
+	// Snapshot is true if Nodes lists every node, replacing the topology,
+	// and false if Nodes lists only added or changed nodes.
+	Snapshot bool      `json:"snapshot,omitempty"`
+	Nodes    []Node[N] `json:"nodes,omitempty"`
+	// Removed lists the IDs of nodes removed by a delta.
+	Removed     []string           `json:"removed,omitempty"`
+	ZoneWeights map[string]float64 `json:"zoneWeights,omitempty"`
+	// Canaries maps the IDs of canaries to their fractions, as set with
+	// SetCanary: every canary in a snapshot, and those set or changed in a
+	// delta. Only canaries among the Hash's nodes are sent.
+	Canaries map[string]float64 `json:"canaries,omitempty"`
+	// ClearedCanaries lists the IDs of canaries cleared by a delta.
+	ClearedCanaries []string `json:"clearedCanaries,omitempty"`
+}
+
+// Server streams the topology of a Hash to subscribers. It is an
+// http.Handler; each GET request subscribes until it is canceled.
+type Server[N any] struct {
+	hash   *rendezvous.Hash[N]
+	locker sync.Locker
+
+	mu          sync.Mutex
+	current     Update[N]
+	byID        map[string]Node[N]
+	subscribers map[chan Update[N]]bool
+}
+
+// NewServer returns a Server publishing hash's topology. locker, if not
+// nil, is held while the Hash is read, for Hashes modified concurrently.
+// Call Publish after changing the Hash to push the change.
+func NewServer[N any](hash *rendezvous.Hash[N], locker sync.Locker) *Server[N] {
+	s := &Server[N]{hash: hash, locker: locker, subscribers: make(map[chan Update[N]]bool)}
+	s.current, s.byID = s.snapshot()
+	return s
+}
+
+// snapshot reads the Hash's current topology.
+func (s *Server[N]) snapshot() (Update[N], map[string]Node[N]) {
+	return snapshot(s.hash, s.locker)
+}
+
+// snapshot reads the topology of hash, holding locker if it is not nil.
+func snapshot[N any](hash *rendezvous.Hash[N], locker sync.Locker) (Update[N], map[string]Node[N]) {
+	if locker != nil {
+		locker.Lock()
+		defer locker.Unlock()
+	}
+	update := Update[N]{Epoch: hash.Epoch(), Snapshot: true, ZoneWeights: hash.ZoneWeights()}
+	byID := make(map[string]Node[N])
+	for _, node := range hash.Nodes() {
+		weight, _ := hash.Weight(node)
+		n := Node[N]{ID: string(hash.Identity(node)), Weight: weight, Value: node}
+		update.Nodes = append(update.Nodes, n)
+		byID[n.ID] = n
+		if fraction, ok := hash.Canary(node); ok {
+			if update.Canaries == nil {
+				update.Canaries = make(map[string]float64)
+			}
+			update.Canaries[n.ID] = fraction
+		}
+	}
+	return update, byID
+}
+
+// Publish pushes the Hash's current topology to every subscriber, as a
+// delta from the topology last published. Subscribers too slow to keep up
+// are disconnected, and resynchronize from a snapshot when they reconnect.
+func (s *Server[N]) Publish() {
+	next, byID := s.snapshot()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if next.Epoch == s.current.Epoch {
+		return
+	}
+
+	delta := Update[N]{Epoch: next.Epoch}
+	for _, node := range next.Nodes {
+		previous, ok := s.byID[node.ID]
+		if !ok || previous.Weight != node.Weight || !jsonEqual(previous.Value, node.Value) {
+			delta.Nodes = append(delta.Nodes, node)
+		}
+	}
+	for _, node := range s.current.Nodes {
+		if _, ok := byID[node.ID]; !ok {
+			delta.Removed = append(delta.Removed, node.ID)
+		}
+	}
+	if !maps.Equal(next.ZoneWeights, s.current.ZoneWeights) {
+		delta.ZoneWeights = next.ZoneWeights
+	}
+	for id, fraction := range next.Canaries {
+		if previous, ok := s.current.Canaries[id]; !ok || previous != fraction {
+			if delta.Canaries == nil {
+				delta.Canaries = make(map[string]float64)
+			}
+			delta.Canaries[id] = fraction
+		}
+	}
+	for id := range s.current.Canaries {
+		if _, ok := next.Canaries[id]; !ok {
+			delta.ClearedCanaries = append(delta.ClearedCanaries, id)
+		}
+	}
+	s.current, s.byID = next, byID
+
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- delta:
+		default:
+			delete(s.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// jsonEqual reports whether a and b have the same JSON encoding.
+func jsonEqual(a, b any) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// subscriberBuffer is the number of updates a subscriber may fall behind
+// before it is disconnected.
+const subscriberBuffer = 64
+
+// ServeHTTP streams updates to the requester until it disconnects.
+func (s *Server[N]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	s.Subscribe(r.Context(), func(update Update[N]) error {
+		if err := encoder.Encode(update); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// Subscribe passes updates to send, starting with a snapshot, until ctx is
+// done, send returns an error, or the subscriber falls too far behind, and
+// returns the reason. It lets transports other than HTTP stream updates:
+// the handler of a gRPC server-streaming method, for example, can pass its
+// stream's Send, and the client apply what it receives with Client.Apply.
+func (s *Server[N]) Subscribe(ctx context.Context, send func(Update[N]) error) error {
+	updates := make(chan Update[N], subscriberBuffer)
+	s.mu.Lock()
+	snapshot := s.current
+	s.subscribers[updates] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		if s.subscribers[updates] {
+			delete(s.subscribers, updates)
+		}
+		s.mu.Unlock()
+	}()
+
+	if err := send(snapshot); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return ErrSlowSubscriber
+			}
+			if err := send(update); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ErrSlowSubscriber is returned by Subscribe when the subscriber falls too
+// far behind the updates published and is disconnected.
+var ErrSlowSubscriber = errors.New("push: subscriber fell behind")
+
+// Client keeps a local Hash in sync with a Server.
+type Client[N any] struct {
+	// Locker, if set, is held while the Hash is modified, for Hashes read
+	// concurrently.
+	Locker sync.Locker
+	// OnError, if set, is called with errors that interrupt the stream
+	// before Run reconnects.
+	OnError func(error)
+	// HTTPClient makes the subscription requests. It defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+
+	hash     *rendezvous.Hash[N]
+	url      string
+	epoch    uint64
+	nodes    map[string]Node[N]
+	canaries map[string]float64
+}
+
+// NewClient returns a Client syncing hash with the Server at url.
+func NewClient[N any](hash *rendezvous.Hash[N], url string) *Client[N] {
+	return &Client[N]{hash: hash, url: url}
+}
+
+// Epoch returns the Server epoch of the last update applied.
+func (c *Client[N]) Epoch() uint64 {
+	return c.epoch
+}
+
+// Run subscribes to the Server and applies its updates until ctx is done,
+// reconnecting after retry whenever the stream is interrupted. It returns
+// ctx's error.
+func (c *Client[N]) Run(ctx context.Context, retry time.Duration) error {
+	for {
+		err := c.subscribe(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if c.OnError != nil {
+			c.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+// subscribe applies updates from one subscription until it ends.
+func (c *Client[N]) subscribe(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("push: subscribing to %s: %s", c.url, response.Status)
+	}
+
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		var update Update[N]
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			return fmt.Errorf("push: decoding update: %w", err)
+		}
+		if err := c.Apply(update); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("push: stream ended")
+}
+
+// Apply applies update to the local Hash as a single change, followed by
+// any zone weight changes. Deltas older than the last update applied are
+// ignored. Run calls Apply for every update received; it is exported for
+// transports other than HTTP.
+func (c *Client[N]) Apply(update Update[N]) error {
+	if !update.Snapshot && update.Epoch <= c.epoch {
+		return nil
+	}
+	if update.Snapshot || c.nodes == nil {
+		c.nodes = make(map[string]Node[N], len(update.Nodes))
+		c.canaries = make(map[string]float64, len(update.Canaries))
+	}
+	for _, node := range update.Nodes {
+		c.nodes[node.ID] = node
+	}
+	for _, id := range update.Removed {
+		delete(c.nodes, id)
+	}
+	maps.Copy(c.canaries, update.Canaries)
+	for _, id := range update.ClearedCanaries {
+		delete(c.canaries, id)
+	}
+
+	if err := apply(c.hash, c.Locker, "push:"+c.url, c.nodes, update.ZoneWeights, c.canaries); err != nil {
+		return err
+	}
+	c.epoch = update.Epoch
+	return nil
+}
+
+// apply changes hash, holding locker if it is not nil, to hold exactly
+// nodes, as a single change recorded for actor, and then sets zoneWeights
+// and makes exactly the nodes in canaries canaries.
+func apply[N any](hash *rendezvous.Hash[N], locker sync.Locker, actor string, nodes map[string]Node[N], zoneWeights map[string]float64, canaries map[string]float64) error {
+	if locker != nil {
+		locker.Lock()
+		defer locker.Unlock()
+	}
+	changes := rendezvous.Changeset[N]{Actor: actor}
+	for _, node := range hash.Nodes() {
+		if _, ok := nodes[string(hash.Identity(node))]; !ok {
+			changes.Remove = append(changes.Remove, node)
+		}
+	}
+	for _, node := range nodes {
+		current, ok := hash.Find([]byte(node.ID))
+		if !ok || !reflect.DeepEqual(current, node.Value) {
+			if ok {
+				changes.Remove = append(changes.Remove, current)
+			}
+			// The ID may have been given with AddWithID, so it is kept
+			// rather than derived from the value.
+			changes.Add = append(changes.Add, node.Value)
+			changes.AddIDs = append(changes.AddIDs, []byte(node.ID))
+		} else if weight, _ := hash.Weight(current); weight == node.Weight {
+			continue
+		}
+		changes.Weights = append(changes.Weights, rendezvous.WeightChange[N]{Node: node.Value, Weight: node.Weight})
+	}
+	if err := hash.Apply(changes); err != nil {
+		return err
+	}
+
+	current := hash.ZoneWeights()
+	for zone, weight := range zoneWeights {
+		if existing, ok := current[zone]; !ok || existing != weight {
+			hash.SetZoneWeight(zone, weight)
+		}
+	}
+
+	// Canaries that change are cleared before any is set, so that the
+	// fractions never sum to 1 or more in between.
+	var set []N
+	for _, node := range hash.Nodes() {
+		fraction, canary := canaries[string(hash.Identity(node))]
+		if existing, ok := hash.Canary(node); ok && canary && existing == fraction {
+			continue
+		} else if ok {
+			hash.ClearCanary(node)
+		}
+		if canary {
+			set = append(set, node)
+		}
+	}
+	for _, node := range set {
+		if err := hash.SetCanary(node, canaries[string(hash.Identity(node))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
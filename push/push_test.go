@@ -0,0 +1,217 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+	"github.com/beam-cloud/rendezvous/cbor"
+)
+
+func TestClientSync(t *testing.T) {
+	source := rendezvous.New(rendezvous.NewConfigNode("a", 1, "east"), rendezvous.NewConfigNode("b", 2, "west"))
+	var sourceMu sync.Mutex
+	server := NewServer(source, &sourceMu)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	local := rendezvous.New[rendezvous.ConfigNode]()
+	var localMu sync.Mutex
+	client := NewClient(local, ts.URL)
+	client.Locker = &localMu
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- client.Run(ctx, 10*time.Millisecond) }()
+
+	waitInSync := func(step string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			localMu.Lock()
+			sourceMu.Lock()
+			equal := local.Equal(source)
+			sourceMu.Unlock()
+			localMu.Unlock()
+			if equal {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("%s: local Hash never matched the source", step)
+	}
+	waitInSync("snapshot")
+
+	sourceMu.Lock()
+	source.Apply(rendezvous.Changeset[rendezvous.ConfigNode]{
+		Remove: []rendezvous.ConfigNode{rendezvous.NewConfigNode("a", 0, "")},
+		Add:    []rendezvous.ConfigNode{rendezvous.NewConfigNode("c", 1, "east")},
+	})
+	source.SetWeight(rendezvous.NewConfigNode("b", 0, ""), 5)
+	source.SetZoneWeight("west", 3)
+	sourceMu.Unlock()
+	server.Publish()
+	waitInSync("delta")
+
+	localMu.Lock()
+	if weight, _ := local.Weight(rendezvous.NewConfigNode("b", 0, "")); weight != 5 {
+		t.Errorf("got weight %v for b, expected 5", weight)
+	}
+	localMu.Unlock()
+
+	// Canaries are set, changed and cleared like any other change.
+	for _, step := range []struct {
+		name     string
+		canaries map[string]float64
+	}{
+		{"canary set", map[string]float64{"b": 0.1}},
+		{"canary moved", map[string]float64{"c": 0.2}},
+		{"canaries cleared", nil},
+	} {
+		sourceMu.Lock()
+		for _, id := range []string{"b", "c"} {
+			node := rendezvous.NewConfigNode(id, 0, "")
+			if fraction, ok := step.canaries[id]; ok {
+				source.SetCanary(node, fraction)
+			} else {
+				source.ClearCanary(node)
+			}
+		}
+		sourceMu.Unlock()
+		server.Publish()
+		waitInSync(step.name)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got error %v, expected context.Canceled", err)
+	}
+}
+
+func TestServerSubscribe(t *testing.T) {
+	source := rendezvous.New(rendezvous.NewConfigNode("a", 1, "east"))
+	server := NewServer(source, nil)
+	local := rendezvous.New[rendezvous.ConfigNode]()
+	var localMu sync.Mutex
+	client := NewClient(local, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan struct{}, 2)
+	done := make(chan error)
+	go func() {
+		done <- server.Subscribe(ctx, func(update Update[rendezvous.ConfigNode]) error {
+			localMu.Lock()
+			client.Apply(update)
+			localMu.Unlock()
+			received <- struct{}{}
+			return nil
+		})
+	}()
+	<-received
+
+	source.Add(rendezvous.NewConfigNode("b", 2, "west"))
+	server.Publish()
+	<-received
+	localMu.Lock()
+	if !local.Equal(source) {
+		t.Errorf("got %v, expected %v", local.Nodes(), source.Nodes())
+	}
+	localMu.Unlock()
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got error %v, expected context.Canceled", err)
+	}
+}
+
+func TestClientKeepsAssignedIDs(t *testing.T) {
+	source := rendezvous.New(rendezvous.NewConfigNode("b", 1, ""))
+	source.AddWithID([]byte("a"), rendezvous.NewConfigNode("10.0.0.1:80", 2, ""))
+	server := NewServer(source, nil)
+	local := rendezvous.NewWithOptions[rendezvous.ConfigNode](nil, rendezvous.WithAuditLog(rendezvous.NewMemoryAuditLog(0)))
+	client := NewClient(local, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan Update[rendezvous.ConfigNode], 2)
+	go server.Subscribe(ctx, func(update Update[rendezvous.ConfigNode]) error {
+		updates <- update
+		return nil
+	})
+	if err := client.Apply(<-updates); err != nil {
+		t.Fatal(err)
+	}
+	if !local.Equal(source) || string(local.Identity(rendezvous.NewConfigNode("10.0.0.1:80", 0, ""))) != "a" {
+		t.Errorf("got %s, expected %s", local.Dump(), source.Dump())
+	}
+
+	// Later updates find the node under its assigned identity, and leave it.
+	epoch := local.Epoch()
+	source.Add(rendezvous.NewConfigNode("c", 1, ""))
+	server.Publish()
+	if err := client.Apply(<-updates); err != nil {
+		t.Fatal(err)
+	}
+	if !local.Equal(source) || local.Epoch() != epoch+1 {
+		t.Errorf("got %s at epoch %d, expected %s at %d", local.Dump(), local.Epoch(), source.Dump(), epoch+1)
+	}
+	if changes := local.History(); !slices.Equal(changes[len(changes)-1].Added, []string{"c"}) {
+		t.Errorf("got last change %+v, expected only c added", changes[len(changes)-1])
+	}
+}
+
+func TestClientIgnoresStaleDeltas(t *testing.T) {
+	local := rendezvous.New[rendezvous.ConfigNode]()
+	client := NewClient(local, "")
+	a := Node[rendezvous.ConfigNode]{ID: "a", Weight: 1, Value: rendezvous.NewConfigNode("a", 1, "")}
+	b := Node[rendezvous.ConfigNode]{ID: "b", Weight: 1, Value: rendezvous.NewConfigNode("b", 1, "")}
+
+	client.Apply(Update[rendezvous.ConfigNode]{Epoch: 5, Snapshot: true, Nodes: []Node[rendezvous.ConfigNode]{a}})
+	client.Apply(Update[rendezvous.ConfigNode]{Epoch: 4, Nodes: []Node[rendezvous.ConfigNode]{b}})
+	if nodes := local.Nodes(); len(nodes) != 1 || nodes[0].ID != "a" {
+		t.Errorf("got %v, expected only a after a stale delta", nodes)
+	}
+
+	client.Apply(Update[rendezvous.ConfigNode]{Epoch: 6, Removed: []string{"a"}, Nodes: []Node[rendezvous.ConfigNode]{b}})
+	if nodes := local.Nodes(); len(nodes) != 1 || nodes[0].ID != "b" || client.Epoch() != 6 {
+		t.Errorf("got %v at epoch %d, expected only b at epoch 6", nodes, client.Epoch())
+	}
+}
+
+func TestUpdateCBOR(t *testing.T) {
+	update := Update[rendezvous.ConfigNode]{
+		Epoch:       7,
+		Nodes:       []Node[rendezvous.ConfigNode]{{ID: "b", Weight: 2, Value: rendezvous.NewConfigNode("b", 2, "west")}},
+		Removed:     []string{"a"},
+		ZoneWeights: map[string]float64{"west": 3},
+		Canaries:    map[string]float64{"b": 0.25},
+	}
+	data, err := cbor.Marshal(update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, _ := json.Marshal(update)
+	if len(data) >= len(encoded) {
+		t.Errorf("got %d bytes of CBOR against %d of JSON, expected fewer", len(data), len(encoded))
+	}
+
+	var decoded Update[rendezvous.ConfigNode]
+	if err := cbor.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	local := rendezvous.New(rendezvous.NewConfigNode("a", 1, ""))
+	client := NewClient(local, "")
+	client.Apply(decoded)
+	if nodes := local.Nodes(); len(nodes) != 1 || nodes[0].ID != "b" || nodes[0].Zone() != "west" || local.ZoneWeights()["west"] != 3 {
+		t.Errorf("got %v with zone weights %v, expected b in west weighted 3", nodes, local.ZoneWeights())
+	}
+	if fraction, ok := local.Canary(rendezvous.NewConfigNode("b", 0, "")); !ok || fraction != 0.25 {
+		t.Errorf("got canary fraction %v, %v, expected 0.25", fraction, ok)
+	}
+}
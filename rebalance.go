@@ -0,0 +1,96 @@
+package rendezvous
+
+import (
+	"time"
+
+	"github.com/beam-cloud/rendezvous/score"
+)
+
+// transitionSeed seeds the hash that orders keys through a Transition.
+var transitionSeed = []byte("rendezvous.Transition")
+
+// Transition blends the placements of a Hash before and after a change, so
+// that keys move to their new nodes gradually instead of all at once. Each
+// key is assigned a fixed point in [0, 1) by hashing it, and follows the new
+// topology once the transition's progress passes that point. Progress
+// advances with time at the rate that moves at most a given fraction of the
+// keyspace per window, so cache hit rates degrade gradually rather than
+// falling off a cliff.
+//
+// A Transition is not safe for concurrent use, nor for use concurrently with
+// its Hash.
+type Transition[N any] struct {
+	old, hash *Hash[N]
+	start     time.Time
+	// rate is the progress made per second.
+	rate float64
+	now  func() time.Time
+}
+
+// ApplyThrottled applies changes to h at once, and returns a Transition
+// through which lookups move to the new placement over time, reassigning no
+// more than limit of the keyspace per window. The fraction of keys the
+// change moves is estimated by sampling, as by Propose. Lookups made
+// directly on h see the new placement immediately. A window of zero or
+// less completes the Transition at once.
+func (h *Hash[N]) ApplyThrottled(changes Changeset[N], limit float64, window time.Duration) (*Transition[N], error) {
+	proposal, err := h.Propose(changes)
+	if err != nil {
+		return nil, err
+	}
+	old := h.clone()
+	if err := h.Commit(proposal.ID); err != nil {
+		return nil, err
+	}
+
+	t := &Transition[N]{old: old, hash: h, now: time.Now}
+	t.start = t.now()
+	if proposal.Moved <= limit || window <= 0 {
+		t.rate = -1
+	} else {
+		t.rate = limit / proposal.Moved / window.Seconds()
+	}
+	return t, nil
+}
+
+// Progress returns the fraction of moving keys that have moved, from 0 to 1.
+func (t *Transition[N]) Progress() float64 {
+	if t.rate < 0 {
+		return 1
+	}
+	return min(t.now().Sub(t.start).Seconds()*t.rate, 1)
+}
+
+// Done reports whether every key has moved to its new placement, after
+// which the Transition places keys exactly as its Hash does.
+func (t *Transition[N]) Done() bool {
+	return t.Progress() >= 1
+}
+
+// moved reports whether key follows the new topology.
+func (t *Transition[N]) moved(key string) bool {
+	progress := t.Progress()
+	if progress >= 1 {
+		return true
+	}
+	point := float64(score.Score(transitionSeed, t.hash.keyBytes(key), nil)) / (1 << 32)
+	return point < progress
+}
+
+// Get returns the node for key under the old or new topology, depending on
+// whether key has moved yet.
+func (t *Transition[N]) Get(key string) (N, bool) {
+	if t.moved(key) {
+		return t.hash.Get(key)
+	}
+	return t.old.Get(key)
+}
+
+// GetN returns the nodes for key under the old or new topology, depending
+// on whether key has moved yet.
+func (t *Transition[N]) GetN(n int, key string) []N {
+	if t.moved(key) {
+		return t.hash.GetN(n, key)
+	}
+	return t.old.GetN(n, key)
+}
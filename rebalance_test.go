@@ -0,0 +1,78 @@
+package rendezvous
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHashApplyThrottled(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	before := make(map[string]hashableString)
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		before[keys[i]], _ = hash.Get(keys[i])
+	}
+
+	// Removing a node moves about a quarter of the keyspace; at 5% per
+	// minute the transition takes about five minutes.
+	transition, err := hash.ApplyThrottled(Changeset[hashableString]{Remove: []hashableString{"d"}}, 0.05, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	clock := start
+	transition.now = func() time.Time { return clock }
+
+	movedAt := func(elapsed time.Duration) float64 {
+		clock = start.Add(elapsed)
+		moved := 0
+		for _, key := range keys {
+			node, _ := transition.Get(key)
+			if node != before[key] {
+				moved++
+			}
+		}
+		return float64(moved) / float64(len(keys))
+	}
+	if moved := movedAt(0); moved != 0 {
+		t.Errorf("got %v moved at the start, expected none", moved)
+	}
+	for minute := 1; minute <= 4; minute++ {
+		if moved := movedAt(time.Duration(minute) * time.Minute); moved > 0.05*float64(minute)+0.01 {
+			t.Errorf("got %v moved after %d minutes, expected at most 5%% per minute", moved, minute)
+		}
+	}
+	if transition.Done() {
+		t.Error("got Done before the keyspace moved")
+	}
+	movedAt(6 * time.Minute)
+	if !transition.Done() {
+		t.Errorf("got progress %v after 6 minutes, expected the transition done", transition.Progress())
+	}
+	for _, key := range keys {
+		got, _ := transition.Get(key)
+		expected, _ := hash.Get(key)
+		if got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+
+	small, err := hash.ApplyThrottled(Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "a", Weight: 1.01}}}, 0.05, time.Minute)
+	if err != nil || !small.Done() {
+		t.Errorf("got %v, %v, expected a change within the limit to apply at once", small, err)
+	}
+
+	for _, window := range []time.Duration{0, -time.Minute} {
+		instant, err := hash.ApplyThrottled(Changeset[hashableString]{Add: []hashableString{"e"}}, 0.05, window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		instant.now = func() time.Time { return instant.start }
+		if progress := instant.Progress(); progress != 1 {
+			t.Errorf("window=%v - got progress: %v, expected: 1", window, progress)
+		}
+		hash.Remove("e")
+	}
+}
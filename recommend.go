@@ -0,0 +1,141 @@
+package rendezvous
+
+import (
+	"math"
+	"sync"
+)
+
+// Rebalancer recommends weight changes that correct persistent load skew.
+// Each node's load, such as its request rate or CPU use, is reported with
+// Observe; on each call to Recommend, which closes a window, every node's
+// load per unit of weight is compared with that of all observed nodes
+// together. A node whose load per unit of weight deviates by more than
+// Threshold for Windows consecutive windows is recommended a weight that
+// would bring it back in line, changed by at most MaxStep of its current
+// weight at a time.
+//
+// Recommendations are not applied: operators or automation apply them, for
+// example after checking their predicted key movement with Predict.
+//
+// Observe is safe for concurrent use. Recommend and Predict read the Hash,
+// so they must not run concurrently with changes to it.
+type Rebalancer[N any] struct {
+	// Threshold is the largest tolerated relative deviation of a node's load
+	// per unit of weight from the average: 0.1 tolerates nodes 10% hotter or
+	// colder than the rest.
+	Threshold float64
+	// Windows is the number of consecutive windows a deviation must last to
+	// be corrected.
+	Windows int
+	// MaxStep is the largest fraction of its current weight a node's weight
+	// is changed by in one recommendation.
+	MaxStep float64
+
+	hash *Hash[N]
+
+	mu      sync.Mutex
+	loads   map[string]*nodeLoad
+	streaks map[string]int
+}
+
+// nodeLoad is the load observed for a node in the current window.
+type nodeLoad struct {
+	sum     float64
+	samples int
+}
+
+// NewRebalancer returns a Rebalancer of hash's nodes with a Threshold of
+// threshold, Windows of 3 and MaxStep of 0.25.
+func NewRebalancer[N any](hash *Hash[N], threshold float64) *Rebalancer[N] {
+	return &Rebalancer[N]{
+		Threshold: threshold,
+		Windows:   3,
+		MaxStep:   0.25,
+		hash:      hash,
+		loads:     make(map[string]*nodeLoad),
+		streaks:   make(map[string]int),
+	}
+}
+
+// Observe records a sample of node's load. A node's load for a window is
+// the average of its samples.
+func (r *Rebalancer[N]) Observe(node N, load float64) {
+	id := string(r.hash.nodeID(node))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.loads[id]
+	if !ok {
+		l = &nodeLoad{}
+		r.loads[id] = l
+	}
+	l.sum += load
+	l.samples++
+}
+
+// Recommend closes the current window and returns weight changes for the
+// nodes whose load has deviated for Windows consecutive windows, or nil if
+// none has. Only nodes in the Hash with a positive weight that were
+// observed in the window are considered; a node missing from a window
+// starts its streak over, as does a node once a change is recommended for
+// it, so that the change can take effect before the next.
+func (r *Rebalancer[N]) Recommend() []WeightChange[N] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	loads := r.loads
+	r.loads = make(map[string]*nodeLoad, len(loads))
+
+	var totalLoad, totalWeight float64
+	for id, l := range loads {
+		i := r.hash.find([]byte(id))
+		if i < 0 || r.hash.nodes[i].weight <= 0 {
+			delete(loads, id)
+			continue
+		}
+		totalLoad += l.sum / float64(l.samples)
+		totalWeight += r.hash.nodes[i].weight
+	}
+	streaks := make(map[string]int, len(r.streaks))
+	defer func() { r.streaks = streaks }()
+	if totalLoad <= 0 {
+		return nil
+	}
+	average := totalLoad / totalWeight
+
+	var changes []WeightChange[N]
+	for _, ns := range r.hash.nodes {
+		l, ok := loads[string(ns.id)]
+		if !ok {
+			continue
+		}
+		perWeight := l.sum / float64(l.samples) / ns.weight
+		if math.Abs(perWeight/average-1) <= r.Threshold {
+			continue
+		}
+		streak := r.streaks[string(ns.id)] + 1
+		if streak < r.Windows {
+			streaks[string(ns.id)] = streak
+			continue
+		}
+
+		// Weight scaled by the inverse of the node's relative load would
+		// equalize load per unit of weight, if load follows keys.
+		factor := 1 + r.MaxStep
+		if perWeight > 0 {
+			factor = min(max(average/perWeight, 1-r.MaxStep), 1+r.MaxStep)
+		}
+		changes = append(changes, WeightChange[N]{Node: ns.node, Weight: ns.weight * factor})
+	}
+	return changes
+}
+
+// Predict returns the fraction of keys that would move to another node if
+// changes were applied, estimated by sampling the same keys as Propose,
+// without modifying the Hash. It returns an error if changes can't be
+// applied.
+func (r *Rebalancer[N]) Predict(changes []WeightChange[N]) (float64, error) {
+	projected := r.hash.clone()
+	if err := projected.Apply(Changeset[N]{Weights: changes}); err != nil {
+		return 0, err
+	}
+	return r.hash.sampleMoved(projected), nil
+}
@@ -0,0 +1,61 @@
+package rendezvous
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRebalancerRecommend(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	rebalancer := NewRebalancer(hash, 0.1)
+	observe := func() {
+		rebalancer.Observe("a", 150)
+		rebalancer.Observe("a", 250)
+		rebalancer.Observe("b", 100)
+		rebalancer.Observe("c", 100)
+	}
+
+	for window := 1; window < 3; window++ {
+		observe()
+		if changes := rebalancer.Recommend(); changes != nil {
+			t.Errorf("window=%d - got %v, expected nothing before the skew persisted", window, changes)
+		}
+	}
+	observe()
+	changes := rebalancer.Recommend()
+	expected := map[hashableString]float64{"a": 0.75, "b": 1.25, "c": 1.25}
+	if len(changes) != len(expected) {
+		t.Fatalf("got %v, expected changes for %v", changes, expected)
+	}
+	for _, change := range changes {
+		if math.Abs(change.Weight-expected[change.Node]) > 1e-9 {
+			t.Errorf("node=%v - got: %v, expected: %v", change.Node, change.Weight, expected[change.Node])
+		}
+	}
+
+	moved, err := rebalancer.Predict(changes)
+	if err != nil || moved <= 0 || moved > 0.3 {
+		t.Errorf("got %v, %v, expected a modest predicted movement", moved, err)
+	}
+	if weight, _ := hash.Weight("a"); weight != 1 {
+		t.Errorf("got weight %v, expected Recommend and Predict to leave the Hash unchanged", weight)
+	}
+	if _, err := rebalancer.Predict([]WeightChange[hashableString]{{Node: "z", Weight: 1}}); err == nil {
+		t.Error("got nil error predicting a change to a missing node")
+	}
+
+	// Streaks start over after a recommendation, and balanced load
+	// recommends nothing.
+	observe()
+	if changes := rebalancer.Recommend(); changes != nil {
+		t.Errorf("got %v, expected the streak to start over", changes)
+	}
+	for range 3 {
+		for _, node := range []hashableString{"a", "b", "c"} {
+			rebalancer.Observe(node, 100)
+		}
+		if changes := rebalancer.Recommend(); changes != nil {
+			t.Errorf("got %v for balanced load, expected nothing", changes)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package rendezvous
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// KeyMove records a registered key moving from one node to another, or to
+// no node if HasTo is false because the Hash is empty.
+type KeyMove[N any] struct {
+	Key   string
+	From  N
+	To    N
+	HasTo bool
+}
+
+// registeredKey is the owner of a registered key as of the last Sync, or
+// of its Track if later.
+type registeredKey[N any] struct {
+	owner N
+	id    string
+}
+
+// KeyRegistry indexes the live keys callers register with Track by the
+// node that owns them, so that the keys a node holds, or those remapped
+// away from it by a topology change, can be found without scanning the
+// keyspace. The index is as of the last Sync, which reindexes the keys after
+// topology changes and returns those that moved.
+//
+// KeyRegistry is safe for concurrent use. Set Locker if the Hash is
+// modified concurrently with it.
+type KeyRegistry[N any] struct {
+	// Locker, if set, is held while the Hash is read.
+	Locker sync.Locker
+
+	hash *Hash[N]
+
+	mu     sync.Mutex
+	keys   map[string]registeredKey[N]
+	byNode map[string]map[string]struct{}
+	// epoch is the epoch of the Hash at the last Sync.
+	epoch uint64
+}
+
+// NewKeyRegistry returns an empty KeyRegistry of keys placed by hash.
+func NewKeyRegistry[N any](hash *Hash[N]) *KeyRegistry[N] {
+	return &KeyRegistry[N]{
+		hash:   hash,
+		keys:   make(map[string]registeredKey[N]),
+		byNode: make(map[string]map[string]struct{}),
+		epoch:  hash.Epoch(),
+	}
+}
+
+// Track registers key against the node that owns it, and reports whether
+// the Hash has a node for it. Tracking a registered key again does nothing.
+func (r *KeyRegistry[N]) Track(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[key]; ok {
+		return true
+	}
+	r.lock()
+	defer r.unlock()
+	owner, ok := r.hash.Get(key)
+	if !ok {
+		return false
+	}
+	r.index(key, owner, string(r.hash.Identity(owner)))
+	return true
+}
+
+// Untrack unregisters key, and reports whether it was registered.
+func (r *KeyRegistry[N]) Untrack(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rk, ok := r.keys[key]
+	if ok {
+		r.unindex(key, rk.id)
+	}
+	return ok
+}
+
+// Len returns the number of registered keys.
+func (r *KeyRegistry[N]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.keys)
+}
+
+// KeysOf returns the registered keys indexed against node, matched by
+// identity, in sorted order.
+func (r *KeyRegistry[N]) KeysOf(node N) []string {
+	r.lock()
+	id := string(r.hash.Identity(node))
+	r.unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var keys []string
+	for key := range r.byNode[id] {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// MovedAway returns the registered keys indexed against node that the
+// Hash now places on another node, in sorted order, so that a cache can
+// invalidate them after a topology change. It only looks up the keys
+// indexed against node, and leaves the index as is until Sync.
+func (r *KeyRegistry[N]) MovedAway(node N) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
+	id := string(r.hash.Identity(node))
+	var keys []string
+	for key := range r.byNode[id] {
+		if owner, ok := r.hash.Get(key); !ok || string(r.hash.Identity(owner)) != id {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Sync reindexes the registered keys against the Hash's current topology,
+// and returns those that moved, sorted by key. It does nothing if the
+// topology hasn't changed since the last Sync.
+func (r *KeyRegistry[N]) Sync() []KeyMove[N] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lock()
+	defer r.unlock()
+	epoch := r.hash.Epoch()
+	if epoch == r.epoch {
+		return nil
+	}
+	r.epoch = epoch
+
+	var moves []KeyMove[N]
+	for key, rk := range r.keys {
+		owner, ok := r.hash.Get(key)
+		var id string
+		if ok {
+			id = string(r.hash.Identity(owner))
+		}
+		if ok && id == rk.id {
+			continue
+		}
+		moves = append(moves, KeyMove[N]{Key: key, From: rk.owner, To: owner, HasTo: ok})
+		r.unindex(key, rk.id)
+		if ok {
+			r.index(key, owner, id)
+		} else {
+			// Keys stay registered while the Hash is empty, indexed
+			// against no node.
+			r.keys[key] = registeredKey[N]{owner: owner}
+		}
+	}
+	slices.SortFunc(moves, func(a, b KeyMove[N]) int { return cmp.Compare(a.Key, b.Key) })
+	return moves
+}
+
+// index registers key against owner, whose identity is id.
+func (r *KeyRegistry[N]) index(key string, owner N, id string) {
+	r.keys[key] = registeredKey[N]{owner: owner, id: id}
+	keys := r.byNode[id]
+	if keys == nil {
+		keys = make(map[string]struct{})
+		r.byNode[id] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// unindex unregisters key from the node with identity id.
+func (r *KeyRegistry[N]) unindex(key, id string) {
+	delete(r.keys, key)
+	delete(r.byNode[id], key)
+	if len(r.byNode[id]) == 0 {
+		delete(r.byNode, id)
+	}
+}
+
+func (r *KeyRegistry[N]) lock() {
+	if r.Locker != nil {
+		r.Locker.Lock()
+	}
+}
+
+func (r *KeyRegistry[N]) unlock() {
+	if r.Locker != nil {
+		r.Locker.Unlock()
+	}
+}
@@ -0,0 +1,81 @@
+package rendezvous
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestKeyRegistry(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	registry := NewKeyRegistry(hash)
+	keys := make([]string, 200)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+	for _, key := range keys {
+		if !registry.Track(key) {
+			t.Fatalf("key=%q - failed to track", key)
+		}
+	}
+	if registry.Len() != len(keys) {
+		t.Errorf("got %d keys, expected: %d", registry.Len(), len(keys))
+	}
+
+	keysOf := func(node hashableString) []string {
+		var owned []string
+		for _, key := range keys {
+			if owner, _ := hash.Get(key); owner == node {
+				owned = append(owned, key)
+			}
+		}
+		slices.Sort(owned)
+		return owned
+	}
+	held := keysOf("a")
+	if got := registry.KeysOf("a"); !reflect.DeepEqual(got, held) {
+		t.Errorf("got: %v, expected: %v", got, held)
+	}
+
+	// Adding a node moves keys away from a, which stay indexed against it
+	// until Sync.
+	hash.Add("d")
+	var moved []string
+	for _, key := range held {
+		if owner, _ := hash.Get(key); owner != "a" {
+			moved = append(moved, key)
+		}
+	}
+	if got := registry.MovedAway("a"); !reflect.DeepEqual(got, moved) {
+		t.Errorf("got: %v, expected: %v", got, moved)
+	}
+	if got := registry.KeysOf("a"); !reflect.DeepEqual(got, held) {
+		t.Errorf("got: %v, expected a's keys %v before Sync", got, held)
+	}
+
+	moves := registry.Sync()
+	for _, move := range moves {
+		if !move.HasTo || move.To != "d" {
+			t.Errorf("key=%q - got move to %v, expected d", move.Key, move.To)
+		}
+	}
+	for _, node := range []hashableString{"a", "b", "c", "d"} {
+		if got, expected := registry.KeysOf(node), keysOf(node); !reflect.DeepEqual(got, expected) {
+			t.Errorf("node=%v - got: %v, expected: %v", node, got, expected)
+		}
+	}
+	if got := registry.Sync(); got != nil {
+		t.Errorf("got: %v, expected no moves without a change", got)
+	}
+	if got := registry.MovedAway("a"); got != nil {
+		t.Errorf("got: %v, expected no keys moved away after Sync", got)
+	}
+
+	if !registry.Untrack(keys[0]) || registry.Untrack(keys[0]) {
+		t.Error("expected Untrack to report the key registered once")
+	}
+	if registry.Len() != len(keys)-1 {
+		t.Errorf("got %d keys, expected: %d", registry.Len(), len(keys)-1)
+	}
+}
@@ -5,121 +5,482 @@ package rendezvous
 import (
 	"bytes"
 	"cmp"
-	"hash"
-	"hash/crc32"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"slices"
+	"time"
 	"unsafe"
 )
 
-var crc32Table = crc32.MakeTable(crc32.Castagnoli)
-
 // Hashable defines the requirements for a node type.
 // It must provide a method to get its byte representation for hashing.
 type Hashable interface {
 	Bytes() []byte
 }
 
-// Hash implements rendezvous hashing for nodes of type N
-// that satisfy the Hashable interface.
-type Hash[N Hashable] struct {
-	nodes  nodeScores[N]
-	hasher hash.Hash32
+// Hash implements rendezvous hashing for nodes of type N. Each node is
+// identified by a byte string: its Bytes() for Hashes created by New, or the
+// result of the identity function passed to NewFunc, unless it was added
+// with AddWithID. A node's identity is captured when it is added.
+//
+// Nodes are kept in canonical order, sorted by identity, so Hashes built
+// from the same membership behave identically regardless of the order in
+// which nodes were added.
+type Hash[N any] struct {
+	nodes    nodeScores[N]
+	hasher   Hasher
+	layout   Layout
+	scorer   scorer
+	identity func(N) []byte
+	// assigned maps the own identities of nodes added by AddWithID to the
+	// identities they were given.
+	assigned map[string][]byte
+	epoch    uint64
+	audit    AuditLog
+	cache    *lookupCache
+	standby  *lookupCache
+	stats    *lookupStats
+	sampler  *LookupSampler
+	// profileCtx, if set, enables pprof labels; see WithProfileLabels.
+	profileCtx context.Context
+
+	zoneWeights map[string]float64
+	// canaries maps the identities of canary nodes to the fraction of keys
+	// each is capped at.
+	canaries map[string]float64
+	// uniform is true when every node has the same effective weight.
+	uniform bool
+	// weightGen advances whenever the scores of existing nodes change.
+	weightGen uint64
+	// logScores forces the logarithmic method even when weights are
+	// uniform, so scores are comparable with those of other Hashes.
+	logScores bool
+	// scoreFunc, if set, replaces the final scoring step.
+	scoreFunc ScoreFunc
+	// normalizers canonicalize keys before they are hashed.
+	normalizers []func(string) string
+	// replication, if set, decides the replication of keys for Place.
+	replication ReplicationPolicy[N]
+
+	// workers holds a scorer for each goroutine used by parallel scoring.
+	workers []scorer
+
+	// order is scratch space holding node indexes in rank order.
+	order []int
+
+	// proposals holds changesets awaiting Commit, keyed by proposal ID.
+	proposals    map[uint64]*Proposal[N]
+	lastProposal uint64
 }
 
 // nodeScore holds a node and its calculated score for a given key.
-type nodeScore[N Hashable] struct {
+type nodeScore[N any] struct {
 	node  N
-	score uint32
+	id    []byte
+	score float64
+	zone  string
+	// weight is the node's configured weight, and effective its weight after
+	// zone weighting is applied. effective is negative until first computed.
+	weight    float64
+	effective float64
+	// digest is the hash of id alone, computed for a ScoreFunc.
+	digest uint64
 }
 
 // New returns a new Hash ready for use with the given nodes.
 // N must satisfy the Hashable interface.
 func New[N Hashable](nodes ...N) *Hash[N] {
+	return NewWithOptions(nodes)
+}
+
+// NewWithOptions returns a new Hash configured by opts, ready for use with
+// the given nodes.
+func NewWithOptions[N Hashable](nodes []N, opts ...Option) *Hash[N] {
+	return newHash(N.Bytes, nodes, opts)
+}
+
+// NewFunc returns a new Hash ready for use with the given nodes, which are
+// identified by the result of calling id on them. It allows node types that
+// don't implement Hashable, such as third-party structs, to be used directly.
+func NewFunc[N any](id func(N) []byte, nodes ...N) *Hash[N] {
+	return newHash(id, nodes, nil)
+}
+
+// newHash returns a new Hash identifying nodes with id.
+func newHash[N any](id func(N) []byte, nodes []N, opts []Option) *Hash[N] {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	hash := &Hash[N]{
-		hasher: crc32.New(crc32Table),
+		hasher:   cfg.hasher,
+		layout:   cfg.layout,
+		scorer:   scorer{digest: cfg.hasher.newDigest()},
+		identity: id,
+		audit:    cfg.audit,
+		uniform:  true,
+	}
+	hash.setParallelism(cfg.parallelism)
+	if cfg.cacheSize > 0 {
+		hash.cache = newLookupCache(cfg.cacheSize)
+	}
+	if cfg.standbySize > 0 {
+		hash.standby = newLookupCache(cfg.standbySize)
+	}
+	hash.profileCtx = cfg.profileCtx
+	hash.scoreFunc = cfg.scoreFunc
+	hash.normalizers = cfg.normalizers
+	if cfg.stats {
+		hash.stats = &lookupStats{budget: cfg.statsBudget}
+	}
+	if cfg.sampleSize > 0 {
+		hash.sampler = NewLookupSampler(cfg.sampleSize)
 	}
 	hash.Add(nodes...)
 	return hash
 }
 
+// Add adds nodes to the Hash.
 func (h *Hash[N]) Add(nodes ...N) {
-	for _, node := range nodes {
-		h.nodes = append(h.nodes, nodeScore[N]{node: node})
+	h.AddAs("", nodes...)
+}
+
+// AddAs adds nodes to the Hash, attributing the change to actor in the
+// audit log.
+func (h *Hash[N]) AddAs(actor string, nodes ...N) {
+	h.Apply(Changeset[N]{Actor: actor, Add: nodes})
+}
+
+// Nodes returns the nodes in the Hash in canonical order.
+func (h *Hash[N]) Nodes() []N {
+	nodes := make([]N, len(h.nodes))
+	for i, ns := range h.nodes {
+		nodes[i] = ns.node
 	}
+	return nodes
+}
+
+// Identity returns the identity the Hash uses for node.
+func (h *Hash[N]) Identity(node N) []byte {
+	return h.nodeID(node)
+}
+
+// Find returns the node with identity id, and false if there is none.
+func (h *Hash[N]) Find(id []byte) (N, bool) {
+	i := h.find(id)
+	if i < 0 {
+		var zero N
+		return zero, false
+	}
+	return h.nodes[i].node, true
+}
+
+// find returns the index of the first node with identity id, or -1 if there
+// is none.
+func (h *Hash[N]) find(id []byte) int {
+	return h.nodes.find(id)
+}
+
+// Epoch returns the number of membership changes applied to the Hash.
+func (h *Hash[N]) Epoch() uint64 {
+	return h.epoch
 }
 
 // Get returns the node with the highest score for the given key.
 // If this Hash has no nodes, the zero value of type N is returned along with false.
 func (h *Hash[N]) Get(key string) (N, bool) {
-	if len(h.nodes) == 0 {
+	var node N
+	var ok bool
+	if h.stats != nil {
+		start := time.Now()
+		node, ok = h.get(key)
+		h.stats.observe(&h.stats.gets, &h.stats.getTime, &h.stats.maxGet, time.Since(start))
+	} else {
+		node, ok = h.get(key)
+	}
+	if h.sampler != nil && ok {
+		h.sampler.Observe(key, string(h.nodeID(node)), h.epoch)
+	}
+	return node, ok
+}
+
+// get is Get without lookup statistics.
+func (h *Hash[N]) get(key string) (N, bool) {
+	if h.cache != nil {
+		if i, ok := h.cache.get(key, h.epoch); ok {
+			return h.nodes[i].node, true
+		}
+	}
+
+	i, _ := h.top(h.keyBytes(key))
+	if i < 0 {
 		var zero N
 		return zero, false
 	}
+	if h.cache != nil {
+		h.cache.put(key, i)
+	}
+	return h.nodes[i].node, true
+}
 
-	keyBytes := unsafeBytes(key)
+// top returns the index and score of the highest scoring node for key, or
+// -1 if the Hash has no nodes.
+func (h *Hash[N]) top(keyBytes []byte) (int, float64) {
+	if len(h.nodes) == 0 {
+		return -1, 0
+	}
+	if i, score, ok := h.topParallel(keyBytes); ok {
+		return i, score
+	}
+	h.scorer.begin(h.layout, keyBytes)
+	return h.topBegun()
+}
 
-	maxNode := h.nodes[0].node
-	maxScore := h.hash(maxNode, keyBytes)
-	maxNodeBytes := maxNode.Bytes()
+// topBegun is top for the key last passed to h.scorer.begin, scoring nodes
+// on the calling goroutine. The Hash must not be empty.
+func (h *Hash[N]) topBegun() (int, float64) {
+	maxIndex := 0
+	maxScore := h.scoreWith(&h.scorer, &h.nodes[0])
 
 	for i := 1; i < len(h.nodes); i++ {
-		currentNode := h.nodes[i].node
-		score := h.hash(currentNode, keyBytes)
+		score := h.scoreWith(&h.scorer, &h.nodes[i])
 
-		if score > maxScore || (score == maxScore && bytes.Compare(currentNode.Bytes(), maxNodeBytes) < 0) {
+		if score > maxScore || (score == maxScore && h.compareTie(h.nodes[i].id, h.nodes[maxIndex].id) < 0) {
 			maxScore = score
-			maxNode = currentNode
-			maxNodeBytes = maxNode.Bytes()
+			maxIndex = i
 		}
 	}
 
-	return maxNode, true
+	return maxIndex, maxScore
 }
 
 // GetN returns no more than n nodes for the given key, ordered by descending score.
 func (h *Hash[N]) GetN(n int, key string) []N {
+	if h.stats != nil {
+		start := time.Now()
+		nodes := h.getN(n, key)
+		h.stats.observe(&h.stats.getNs, &h.stats.getNTime, &h.stats.maxGetN, time.Since(start))
+		return nodes
+	}
+	return h.getN(n, key)
+}
+
+// getN is GetN without lookup statistics.
+func (h *Hash[N]) getN(n int, key string) []N {
 	if len(h.nodes) == 0 {
 		return nil
 	}
-	keyBytes := unsafeBytes(key)
-	for i := range h.nodes {
-		h.nodes[i].score = h.hash(h.nodes[i].node, keyBytes)
+	if h.profileCtx != nil && len(h.nodes) >= profileMinNodes {
+		h.profiled("getn", func() { h.rank(h.keyBytes(key)) })
+	} else {
+		h.rank(h.keyBytes(key))
 	}
 
-	slices.SortFunc(h.nodes, func(a, b nodeScore[N]) int {
-		if b.score != a.score {
-			return cmp.Compare(b.score, a.score)
-		}
-		return bytes.Compare(a.node.Bytes(), b.node.Bytes())
-	})
-
-	if n > len(h.nodes) {
-		n = len(h.nodes)
+	if n > len(h.order) {
+		n = len(h.order)
 	}
 
 	nodes := make([]N, n)
 	for i := range nodes {
-		nodes[i] = h.nodes[i].node
+		nodes[i] = h.nodes[h.order[i]].node
 	}
 	return nodes
 }
 
+// rank scores every node for key and fills h.order with node indexes in
+// descending score order, breaking ties by node identity.
+func (h *Hash[N]) rank(keyBytes []byte) {
+	h.order = h.order[:0]
+	for i := range h.nodes {
+		h.order = append(h.order, i)
+	}
+	h.sortOrder(keyBytes)
+}
+
+// rankOf is like rank, but only ranks the nodes at the given indexes.
+func (h *Hash[N]) rankOf(keyBytes []byte, members []int) {
+	h.order = append(h.order[:0], members...)
+	h.sortOrder(keyBytes)
+}
+
+// sortOrder scores the nodes in h.order for key and sorts h.order by
+// descending score, breaking ties by node identity.
+func (h *Hash[N]) sortOrder(keyBytes []byte) {
+	h.scorer.begin(h.layout, keyBytes)
+	if !h.scoreOrderParallel(keyBytes) {
+		h.scoreOrder()
+	}
+	h.sortScored()
+}
+
+// scoreOrder sets the score of every node in h.order for the key last
+// passed to h.scorer.begin.
+func (h *Hash[N]) scoreOrder() {
+	for _, i := range h.order {
+		h.nodes[i].score = h.scoreWith(&h.scorer, &h.nodes[i])
+	}
+}
+
+// sortScored sorts h.order by descending score, breaking ties as
+// compareTie does.
+func (h *Hash[N]) sortScored() {
+	slices.SortFunc(h.order, func(a, b int) int {
+		nodeA, nodeB := &h.nodes[a], &h.nodes[b]
+		if nodeB.score != nodeA.score {
+			return cmp.Compare(nodeB.score, nodeA.score)
+		}
+		return h.compareTie(nodeA.id, nodeB.id)
+	})
+}
+
+// Remove removes every node whose identity matches node's.
 func (h *Hash[N]) Remove(node N) {
-	nodeBytesToRemove := node.Bytes()
-	h.nodes = slices.DeleteFunc(h.nodes, func(ns nodeScore[N]) bool {
-		return bytes.Equal(ns.node.Bytes(), nodeBytesToRemove)
+	h.RemoveAs("", node)
+}
+
+// RemoveAs removes every node whose identity matches node's, attributing the
+// change to actor in the audit log.
+func (h *Hash[N]) RemoveAs(actor string, node N) {
+	h.Apply(Changeset[N]{Actor: actor, Remove: []N{node}})
+}
+
+// commit advances the epoch after a topology change and records the change
+// in the audit log, if one is configured.
+func (h *Hash[N]) commit(actor string, added, removed, reweighted []N) {
+	h.epoch++
+	if h.audit == nil {
+		return
+	}
+	h.audit.Record(Change{
+		Time:       time.Now(),
+		Actor:      actor,
+		Epoch:      h.epoch,
+		Added:      h.identities(added),
+		Removed:    h.identities(removed),
+		Reweighted: h.identities(reweighted),
 	})
 }
 
+// consistencyProbes is the number of keys CheckConsistency uses to compare
+// Get against GetN.
+const consistencyProbes = 16
+
+// CheckConsistency validates the Hash's internal invariants: node identities
+// are unique and still match each node's current identity, node and zone
+// weights are finite and no less than 0, as drained ones are, and GetN(1)
+// agrees with Get across a set of probe keys. It returns nil if every
+// invariant holds, or an error joining one error per violation otherwise. It
+// is intended as a sanity check after building a Hash from external topology
+// data. Its probes bypass the lookup cache, and are not counted in
+// LookupStats nor sampled.
+func (h *Hash[N]) CheckConsistency() error {
+	var errs []error
+
+	seen := make(map[string]int, len(h.nodes))
+	for i, ns := range h.nodes {
+		id := string(ns.id)
+		if current := h.nodeID(ns.node); !bytes.Equal(current, ns.id) {
+			errs = append(errs, fmt.Errorf("rendezvous: node %d was added as %q but now has identity %q", i, ns.id, current))
+		}
+		if j, ok := seen[id]; ok {
+			errs = append(errs, fmt.Errorf("rendezvous: nodes %d and %d share identity %q", j, i, id))
+			continue
+		}
+		seen[id] = i
+	}
+
+	for i, ns := range h.nodes {
+		if usableWeight(ns.weight) != ns.weight {
+			errs = append(errs, fmt.Errorf("rendezvous: node %d (%q) has invalid weight %v", i, ns.id, ns.weight))
+		}
+	}
+	for zone, weight := range h.zoneWeights {
+		if usableWeight(weight) != weight {
+			errs = append(errs, fmt.Errorf("rendezvous: zone %q has invalid weight %v", zone, weight))
+		}
+	}
+
+	// Probes rank nodes directly, so that they leave the lookup cache and
+	// statistics as they are.
+	for i := 0; len(h.nodes) > 0 && i < consistencyProbes; i++ {
+		key := fmt.Sprintf("consistency-probe-%d", i)
+		top, _ := h.top(h.keyBytes(key))
+		h.rank(h.keyBytes(key))
+		if first := h.order[0]; !bytes.Equal(h.nodes[first].id, h.nodes[top].id) {
+			errs = append(errs, fmt.Errorf("rendezvous: key %q: GetN(1) returned %v but Get returned %v", key, h.nodes[first].node, h.nodes[top].node))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// clone returns a copy of h that shares no mutable state with it.
+func (h *Hash[N]) clone() *Hash[N] {
+	clone := &Hash[N]{
+		nodes:       slices.Clone(h.nodes),
+		hasher:      h.hasher,
+		layout:      h.layout,
+		scorer:      scorer{digest: h.hasher.newDigest()},
+		identity:    h.identity,
+		assigned:    maps.Clone(h.assigned),
+		epoch:       h.epoch,
+		zoneWeights: maps.Clone(h.zoneWeights),
+		canaries:    maps.Clone(h.canaries),
+		uniform:     h.uniform,
+		weightGen:   h.weightGen,
+		logScores:   h.logScores,
+		scoreFunc:   h.scoreFunc,
+		normalizers: h.normalizers,
+		replication: h.replication,
+	}
+	clone.setParallelism(len(h.workers))
+	return clone
+}
+
 // nodeScores is a slice of nodeScore structs.
-type nodeScores[N Hashable] []nodeScore[N]
-
-// hash generates the score using the node's HashBytes method and the key.
-func (h *Hash[N]) hash(node N, key []byte) uint32 {
-	h.hasher.Reset()
-	h.hasher.Write(key)
-	h.hasher.Write(node.Bytes())
-	return h.hasher.Sum32()
+type nodeScores[N any] []nodeScore[N]
+
+// find returns the index of the first node with identity id, or -1 if there
+// is none.
+func (nodes nodeScores[N]) find(id []byte) int {
+	i, found := slices.BinarySearchFunc(nodes, id, func(ns nodeScore[N], id []byte) int {
+		return bytes.Compare(ns.id, id)
+	})
+	if !found {
+		return -1
+	}
+	return i
+}
+
+// pack copies the identities of nodes into a single contiguous arena, in
+// node order, and points each node's id into it. Large Hashes then hold
+// one allocation for all identities instead of one per node, and scoring
+// walks identities sequentially in memory. Arenas are never modified once
+// built, so ids handed out before a pack stay valid.
+func (nodes nodeScores[N]) pack() {
+	size := 0
+	for i := range nodes {
+		size += len(nodes[i].id)
+	}
+	arena := make([]byte, 0, size)
+	for i := range nodes {
+		start := len(arena)
+		arena = append(arena, nodes[i].id...)
+		nodes[i].id = arena[start:len(arena):len(arena)]
+	}
+}
+
+// span returns the end of the run of nodes sharing the identity of the node
+// at start.
+func (nodes nodeScores[N]) span(start int) int {
+	end := start + 1
+	for end < len(nodes) && bytes.Equal(nodes[end].id, nodes[start].id) {
+		end++
+	}
+	return end
 }
 
 // unsafeBytes converts string to byte slice without allocation.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"unsafe"
 )
 
 // hashableString implements HashableOrdered for testing purposes.
@@ -164,3 +165,126 @@ func TestHashRemove(t *testing.T) {
 		t.Errorf("Key %q still maps to removed node %v (%v)", keyForB, nodeB, newNode)
 	}
 }
+
+func TestHashCheckConsistency(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	if err := hash.CheckConsistency(); err != nil {
+		t.Errorf("got: %v, expected: nil", err)
+	}
+
+	hash.SetWeight("a", 0)
+	if err := hash.CheckConsistency(); err != nil {
+		t.Errorf("got: %v, expected a drained node to pass", err)
+	}
+
+	hash.Add("b", "c")
+	err := hash.CheckConsistency()
+	if err == nil {
+		t.Fatalf("got: nil, expected duplicate identity errors")
+	}
+	if errs := err.(interface{ Unwrap() []error }).Unwrap(); len(errs) != 2 {
+		t.Errorf("got %d errors, expected 2: %v", len(errs), err)
+	}
+
+	// Probes are not lookups.
+	observed := NewWithOptions([]hashableString{"a", "b"}, WithLookupStats(0), WithLookupSampling(16), WithLookupCache(16))
+	if err := observed.CheckConsistency(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := observed.LookupStats(); stats.Gets != 0 || stats.GetNs != 0 || observed.LookupSampler().Seen() != 0 {
+		t.Errorf("got stats %+v and %d samples, expected probes not to count", stats, observed.LookupSampler().Seen())
+	}
+	if stats := observed.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("got cache stats %+v, expected probes to bypass the cache", stats)
+	}
+	if err := New[hashableString]().CheckConsistency(); err != nil {
+		t.Errorf("got: %v, expected an empty Hash to pass", err)
+	}
+}
+
+func TestHashCanonicalOrder(t *testing.T) {
+	a := New[hashableString]("c", "a", "e", "b", "d")
+	b := New[hashableString]("e", "d", "c", "b", "a")
+
+	expected := []hashableString{"a", "b", "c", "d", "e"}
+	if got := a.Nodes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, expected: %v", got, expected)
+	}
+	if !reflect.DeepEqual(a.Nodes(), b.Nodes()) || !reflect.DeepEqual(a.SampleShares(100), b.SampleShares(100)) {
+		t.Errorf("Hashes built in different orders diverged: %v vs %v", a.Nodes(), b.Nodes())
+	}
+
+	a.Remove("c")
+	a.Add("c")
+	if !reflect.DeepEqual(a.Nodes(), expected) {
+		t.Errorf("got: %v after re-adding c, expected: %v", a.Nodes(), expected)
+	}
+}
+
+// server stands in for a third-party node type without a Bytes method.
+type server struct {
+	Name string
+	Port int
+}
+
+func TestNewFunc(t *testing.T) {
+	servers := []*server{{"a", 80}, {"b", 80}, {"c", 80}}
+	hash := NewFunc(func(s *server) []byte { return []byte(s.Name) }, servers...)
+	plain := New[hashableString]("a", "b", "c")
+
+	for _, key := range sampleKeys {
+		got, _ := hash.Get(key)
+		expected, _ := plain.Get(key)
+		if got.Name != string(expected) {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got.Name, expected)
+		}
+	}
+	if err := hash.CheckConsistency(); err != nil {
+		t.Errorf("got: %v, expected: nil", err)
+	}
+
+	// Identities are captured at Add time, so mutating a node is reported.
+	servers[1].Name = "z"
+	if err := hash.CheckConsistency(); err == nil {
+		t.Errorf("got: nil, expected an identity mismatch error")
+	}
+}
+
+func TestHashPackedIdentities(t *testing.T) {
+	hash := New[hashableString]("c", "a", "bb")
+	hash.Add("dd", "e")
+	hash.Remove("bb")
+	hash.Merge(New[hashableString]("f"), KeepOurs)
+
+	for i := 1; i < len(hash.nodes); i++ {
+		prev, ns := hash.nodes[i-1].id, hash.nodes[i].id
+		if unsafe.Add(unsafe.Pointer(unsafe.SliceData(prev)), len(prev)) != unsafe.Pointer(unsafe.SliceData(ns)) {
+			t.Errorf("identity %q does not follow %q in the arena", ns, prev)
+		}
+	}
+
+	// Weight changes leave the arena alone.
+	arena := unsafe.SliceData(hash.nodes[0].id)
+	if err := hash.Apply(Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "a", Weight: 3}}}); err != nil {
+		t.Fatal(err)
+	}
+	if weight, _ := hash.Weight("a"); weight != 3 || unsafe.SliceData(hash.nodes[0].id) != arena {
+		t.Errorf("got weight %v, expected 3 without repacking the arena", weight)
+	}
+	if err := hash.Apply(Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "a", Weight: 4}, {Node: "missing", Weight: 1}}}); err == nil {
+		t.Error("got no error setting the weight of a missing node")
+	}
+	if weight, _ := hash.Weight("a"); weight != 3 {
+		t.Errorf("got weight %v, expected a failed Apply to leave 3", weight)
+	}
+}
+
+func TestHashFind(t *testing.T) {
+	hash := NewFunc(func(s server) []byte { return []byte(s.Name) }, server{"a", 1}, server{"b", 2})
+	if got, ok := hash.Find(hash.Identity(server{Name: "b"})); !ok || got != (server{"b", 2}) {
+		t.Errorf("got: (%v, %t), expected: ({b 2}, true)", got, ok)
+	}
+	if _, ok := hash.Find([]byte("c")); ok {
+		t.Errorf("found a node that isn't in the Hash")
+	}
+}
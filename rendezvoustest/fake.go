@@ -0,0 +1,100 @@
+package rendezvoustest
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Fake is a Selector whose answers are scripted, for unit testing code that
+// routes keys with a Selector without depending on where a real Hash would
+// place them. Keys are routed with Route; other keys get the default ranking
+// set with RouteDefault, or no nodes if none is set. Fake also implements
+// rendezvous.Selector: Add, Remove and Nodes track a membership for code
+// under test that manages nodes, without changing the scripted rankings.
+//
+// A Fake is safe for concurrent use.
+type Fake[N any] struct {
+	mu       sync.Mutex
+	routes   map[string][]N
+	fallback []N
+	lookups  []string
+	nodes    []N
+}
+
+// NewFake returns a Fake with no routes.
+func NewFake[N any]() *Fake[N] {
+	return &Fake[N]{routes: make(map[string][]N)}
+}
+
+// Route scripts the ranking of key: Get returns the first of nodes, and
+// GetN(n) the first n. Routing a key with no nodes makes lookups of it find
+// nothing, even with a default ranking set.
+func (f *Fake[N]) Route(key string, nodes ...N) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[key] = slices.Clone(nodes)
+}
+
+// RouteDefault scripts the ranking of every key not routed with Route.
+func (f *Fake[N]) RouteDefault(nodes ...N) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fallback = slices.Clone(nodes)
+}
+
+// Get returns the first node routed for key.
+func (f *Fake[N]) Get(key string) (N, bool) {
+	ranking := f.lookup(key)
+	if len(ranking) == 0 {
+		var zero N
+		return zero, false
+	}
+	return ranking[0], true
+}
+
+// GetN returns the first n nodes routed for key.
+func (f *Fake[N]) GetN(n int, key string) []N {
+	ranking := f.lookup(key)
+	return slices.Clone(ranking[:max(min(n, len(ranking)), 0)])
+}
+
+// Add adds nodes to the membership.
+func (f *Fake[N]) Add(nodes ...N) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nodes = append(f.nodes, nodes...)
+}
+
+// Remove removes every node equal to node, as by reflect.DeepEqual, from
+// the membership.
+func (f *Fake[N]) Remove(node N) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nodes = slices.DeleteFunc(f.nodes, func(n N) bool { return reflect.DeepEqual(n, node) })
+}
+
+// Nodes returns the membership, in the order nodes were added.
+func (f *Fake[N]) Nodes() []N {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.nodes)
+}
+
+// Lookups returns the keys looked up with Get or GetN, in order.
+func (f *Fake[N]) Lookups() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.lookups)
+}
+
+// lookup records a lookup of key and returns its ranking.
+func (f *Fake[N]) lookup(key string) []N {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups = append(f.lookups, key)
+	if ranking, ok := f.routes[key]; ok {
+		return ranking
+	}
+	return f.fallback
+}
@@ -0,0 +1,59 @@
+package rendezvoustest_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+	"github.com/beam-cloud/rendezvous/rendezvoustest"
+)
+
+var _ rendezvous.Selector[string] = (*rendezvoustest.Fake[string])(nil)
+
+func TestFake(t *testing.T) {
+	fake := rendezvoustest.NewFake[node]()
+	var _ rendezvoustest.Selector[node] = fake
+
+	if got, ok := fake.Get("key1"); ok {
+		t.Errorf("got %v, expected no node for an unrouted key", got)
+	}
+
+	fake.Route("key1", "a", "b", "c")
+	fake.RouteDefault("z")
+	fake.Route("key2")
+
+	if got, _ := fake.Get("key1"); got != "a" {
+		t.Errorf("key=%q - got: %v, expected: %v", "key1", got, "a")
+	}
+	if got := fake.GetN(2, "key1"); !slices.Equal(got, []node{"a", "b"}) {
+		t.Errorf("key=%q - got: %v, expected: %v", "key1", got, []node{"a", "b"})
+	}
+	if got := fake.GetN(5, "key1"); len(got) != 3 {
+		t.Errorf("got %v, expected GetN to stop at the routed nodes", got)
+	}
+	if got, _ := fake.Get("other"); got != "z" {
+		t.Errorf("key=%q - got: %v, expected: %v", "other", got, "z")
+	}
+	if got, ok := fake.Get("key2"); ok {
+		t.Errorf("got %v, expected no node for a key routed to nothing", got)
+	}
+
+	rendezvoustest.CheckGetNPrefix[node](t, fake, 3, []string{"key1", "key2", "other"})
+	if lookups := fake.Lookups(); len(lookups) < 6 || lookups[0] != "key1" {
+		t.Errorf("got lookups %v, expected every lookup recorded in order", lookups)
+	}
+}
+
+func TestFakeMembership(t *testing.T) {
+	fake := rendezvoustest.NewFake[node]()
+	fake.RouteDefault("a")
+	fake.Add("a", "b", "c")
+	fake.Remove("b")
+	if got := fake.Nodes(); !slices.Equal(got, []node{"a", "c"}) {
+		t.Errorf("got: %v, expected: %v", got, []node{"a", "c"})
+	}
+	fake.Remove("a")
+	if got, _ := fake.Get("key"); got != "a" {
+		t.Errorf("got: %v, expected the scripted ranking to stay", got)
+	}
+}
@@ -0,0 +1,121 @@
+// Package rendezvoustest provides reusable property checks for rendezvous
+// hashing implementations, including wrappers around rendezvous.Hash, and a
+// scripted Selector for testing code that routes with one.
+package rendezvoustest
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+// Selector is the lookup API exercised by the checks in this package.
+// *rendezvous.Hash satisfies it.
+type Selector[N any] interface {
+	Get(key string) (N, bool)
+	GetN(n int, key string) []N
+}
+
+// Constructor builds a Selector over the given nodes.
+type Constructor[N any] func(nodes ...N) Selector[N]
+
+// Keys returns n deterministic sample keys.
+func Keys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+	return keys
+}
+
+// CheckDeterminism verifies that two independently constructed selectors over
+// the same nodes agree on every key, for both Get and GetN.
+func CheckDeterminism[N comparable](t testing.TB, newSelector Constructor[N], nodes []N, keys []string) {
+	t.Helper()
+	a, b := newSelector(nodes...), newSelector(nodes...)
+	for _, key := range keys {
+		nodeA, okA := a.Get(key)
+		nodeB, okB := b.Get(key)
+		if nodeA != nodeB || okA != okB {
+			t.Errorf("key=%q - Get disagrees across instances: (%v, %t) vs (%v, %t)", key, nodeA, okA, nodeB, okB)
+			return
+		}
+		if rankA, rankB := a.GetN(len(nodes), key), b.GetN(len(nodes), key); !slices.Equal(rankA, rankB) {
+			t.Errorf("key=%q - GetN disagrees across instances: %v vs %v", key, rankA, rankB)
+			return
+		}
+	}
+}
+
+// CheckMinimalDisruption verifies that adding node to nodes only moves keys
+// onto node. Read in reverse, this also checks that removing node only moves
+// the keys it owned.
+func CheckMinimalDisruption[N comparable](t testing.TB, newSelector Constructor[N], nodes []N, node N, keys []string) {
+	t.Helper()
+	before := newSelector(nodes...)
+	after := newSelector(append(slices.Clone(nodes), node)...)
+	for _, key := range keys {
+		old, _ := before.Get(key)
+		current, _ := after.Get(key)
+		if current != old && current != node {
+			t.Errorf("key=%q - adding %v moved it from %v to %v", key, node, old, current)
+			return
+		}
+	}
+}
+
+// CheckGetNPrefix verifies that GetN(1) returns Get's answer and that every
+// GetN(n) result is a prefix of GetN(n+1).
+func CheckGetNPrefix[N comparable](t testing.TB, s Selector[N], maxN int, keys []string) {
+	t.Helper()
+	for _, key := range keys {
+		node, ok := s.Get(key)
+		first := s.GetN(1, key)
+		if ok != (len(first) == 1) || (ok && first[0] != node) {
+			t.Errorf("key=%q - GetN(1) = %v, but Get = (%v, %t)", key, first, node, ok)
+			return
+		}
+
+		previous := first
+		for n := 2; n <= maxN; n++ {
+			current := s.GetN(n, key)
+			if len(current) < len(previous) || !slices.Equal(current[:len(previous)], previous) {
+				t.Errorf("key=%q - GetN(%d) = %v is not a prefix of GetN(%d) = %v", key, n-1, previous, n, current)
+				return
+			}
+			previous = current
+		}
+	}
+}
+
+// CheckProportional verifies that each node receives a share of keys within
+// tolerance of its expected share, where expected shares are proportional to
+// weights. Pass equal weights to check an unweighted selector for balance.
+// tolerance is relative: 0.1 allows each share to be off by 10% of its
+// expected value.
+func CheckProportional[N comparable](t testing.TB, s Selector[N], nodes []N, weights []float64, keys []string, tolerance float64) {
+	t.Helper()
+	if len(nodes) != len(weights) {
+		t.Fatalf("got %d nodes but %d weights", len(nodes), len(weights))
+	}
+
+	counts := make(map[N]int, len(nodes))
+	for _, key := range keys {
+		if node, ok := s.Get(key); ok {
+			counts[node]++
+		}
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	for i, node := range nodes {
+		expected := weights[i] / total
+		got := float64(counts[node]) / float64(len(keys))
+		if math.Abs(got-expected) > expected*tolerance {
+			t.Errorf("node=%v - got share %.4f, expected %.4f within %.0f%%", node, got, expected, tolerance*100)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package rendezvoustest_test
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+	"github.com/beam-cloud/rendezvous/rendezvoustest"
+)
+
+type node string
+
+func (n node) Bytes() []byte {
+	return []byte(n)
+}
+
+func newHash(nodes ...node) rendezvoustest.Selector[node] {
+	return rendezvous.New(nodes...)
+}
+
+// moduloSelector spreads keys evenly over its nodes without regard to
+// stability, giving CheckProportional a known distribution.
+type moduloSelector []node
+
+func (s moduloSelector) Get(key string) (node, bool) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return s[h.Sum32()%uint32(len(s))], true
+}
+
+func (s moduloSelector) GetN(n int, key string) []node {
+	first, _ := s.Get(key)
+	return []node{first}
+}
+
+// recorder is a testing.TB that records failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+}
+
+func TestHashProperties(t *testing.T) {
+	nodes := []node{"a", "b", "c", "d", "e"}
+	keys := rendezvoustest.Keys(20000)
+
+	rendezvoustest.CheckDeterminism(t, newHash, nodes, keys)
+	rendezvoustest.CheckMinimalDisruption(t, newHash, nodes, "f", keys)
+	rendezvoustest.CheckGetNPrefix(t, newHash(nodes...), len(nodes), keys[:500])
+}
+
+func TestCheckProportional(t *testing.T) {
+	nodes := []node{"a", "b", "c", "d"}
+	keys := rendezvoustest.Keys(20000)
+
+	rendezvoustest.CheckProportional(t, moduloSelector(nodes), nodes, []float64{1, 1, 1, 1}, keys, 0.05)
+
+	r := &recorder{TB: t}
+	rendezvoustest.CheckProportional(r, moduloSelector(nodes), nodes, []float64{3, 1, 1, 1}, keys, 0.05)
+	if !r.failed {
+		t.Errorf("CheckProportional accepted a 1:1:1:1 split against 3:1:1:1 weights")
+	}
+}
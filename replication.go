@@ -0,0 +1,73 @@
+package rendezvous
+
+// Replication is how a key is replicated.
+type Replication[N any] struct {
+	// Replicas is the number of nodes holding the key.
+	Replicas int
+	// Constraint, if set, restricts which nodes may hold the key together,
+	// as for GetNConstrained.
+	Constraint Constraint[N]
+}
+
+// ReplicationPolicy decides the replication of each key, such as three
+// replicas for the keys of important tenants and one for scratch data.
+type ReplicationPolicy[N any] interface {
+	Replication(key string) Replication[N]
+}
+
+// ReplicationPolicyFunc is a function implementing ReplicationPolicy.
+type ReplicationPolicyFunc[N any] func(key string) Replication[N]
+
+// Replication implements ReplicationPolicy.
+func (f ReplicationPolicyFunc[N]) Replication(key string) Replication[N] {
+	return f(key)
+}
+
+// Placement is the placement of a key.
+type Placement[N any] struct {
+	Key string
+	// Replication is the replication the policy decided for the key.
+	Replication Replication[N]
+	// Nodes holds the nodes holding the key in descending score order,
+	// starting with its primary. It holds fewer than Replication.Replicas
+	// nodes if the Hash has too few nodes to satisfy the replication.
+	Nodes []N
+}
+
+// Primary returns the primary node of the placement, or false if it has
+// none.
+func (p Placement[N]) Primary() (N, bool) {
+	if len(p.Nodes) == 0 {
+		var zero N
+		return zero, false
+	}
+	return p.Nodes[0], true
+}
+
+// UnderReplicated reports whether the placement has fewer nodes than its
+// replication requires.
+func (p Placement[N]) UnderReplicated() bool {
+	return len(p.Nodes) < p.Replication.Replicas
+}
+
+// SetReplicationPolicy sets the policy Place consults for the replication
+// of each key. A nil policy, the default, places each key on one node.
+func (h *Hash[N]) SetReplicationPolicy(policy ReplicationPolicy[N]) {
+	h.replication = policy
+}
+
+// Place returns the placement of key, with the replication decided by the
+// Hash's ReplicationPolicy.
+func (h *Hash[N]) Place(key string) Placement[N] {
+	replication := Replication[N]{Replicas: 1}
+	if h.replication != nil {
+		replication = h.replication.Replication(key)
+	}
+	placement := Placement[N]{Key: key, Replication: replication}
+	if replication.Constraint != nil {
+		placement.Nodes = h.GetNConstrained(replication.Replicas, key, replication.Constraint)
+	} else {
+		placement.Nodes = h.GetN(replication.Replicas, key)
+	}
+	return placement
+}
@@ -0,0 +1,56 @@
+package rendezvous
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHashPlace(t *testing.T) {
+	hash := New[hashableString]("h1/a", "h1/b", "h2/a", "h2/b", "h3/a")
+
+	for _, key := range sampleKeys {
+		if got, expected := hash.Place(key).Nodes, hash.GetN(1, key); !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected a single node %v without a policy", key, got, expected)
+		}
+	}
+
+	differentHost := func(selected []hashableString, candidate hashableString) bool {
+		host, _, _ := strings.Cut(string(candidate), "/")
+		for _, node := range selected {
+			if strings.HasPrefix(string(node), host+"/") {
+				return false
+			}
+		}
+		return true
+	}
+	hash.SetReplicationPolicy(ReplicationPolicyFunc[hashableString](func(key string) Replication[hashableString] {
+		switch {
+		case strings.HasPrefix(key, "tenant/"):
+			return Replication[hashableString]{Replicas: 3, Constraint: differentHost}
+		case strings.HasPrefix(key, "huge/"):
+			return Replication[hashableString]{Replicas: 4, Constraint: differentHost}
+		}
+		return Replication[hashableString]{Replicas: 1}
+	}))
+
+	for _, key := range sampleKeys {
+		placement := hash.Place("tenant/" + key)
+		if expected := hash.GetNConstrained(3, "tenant/"+key, differentHost); !reflect.DeepEqual(placement.Nodes, expected) || placement.UnderReplicated() {
+			t.Errorf("key=%q - got: %v, expected: %v", key, placement.Nodes, expected)
+		}
+		if primary, _ := placement.Primary(); primary != placement.Nodes[0] {
+			t.Errorf("key=%q - got primary %v, expected: %v", key, primary, placement.Nodes[0])
+		}
+		if placement := hash.Place("scratch/" + key); len(placement.Nodes) != 1 {
+			t.Errorf("key=%q - got: %v, expected a single node for scratch data", key, placement.Nodes)
+		}
+		if placement := hash.Place("huge/" + key); len(placement.Nodes) != 3 || !placement.UnderReplicated() {
+			t.Errorf("key=%q - got: %v, expected an under-replicated placement on 3 hosts", key, placement.Nodes)
+		}
+	}
+
+	if _, ok := New[hashableString]().Place("key").Primary(); ok {
+		t.Errorf("got a primary from an empty Hash")
+	}
+}
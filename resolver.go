@@ -0,0 +1,82 @@
+package rendezvous
+
+import (
+	"context"
+	"fmt"
+)
+
+// Resolver materializes a node value from its identity.
+type Resolver[N any] interface {
+	Resolve(ctx context.Context, id string) (N, error)
+}
+
+// ResolverFunc adapts a function into a Resolver.
+type ResolverFunc[N any] func(ctx context.Context, id string) (N, error)
+
+// Resolve calls f.
+func (f ResolverFunc[N]) Resolve(ctx context.Context, id string) (N, error) {
+	return f(ctx, id)
+}
+
+// Lazy places keys on nodes known only by their identities, resolving node
+// values through a Resolver when a lookup returns them. It suits control
+// planes that track far more nodes than any one process needs to hold:
+// membership, weights and zones are managed on the identities, through
+// Hash, and only the nodes lookups return are ever materialized.
+//
+// Lazy does not cache resolved values; wrap the Resolver to do so.
+type Lazy[N any] struct {
+	hash     *Hash[StringNode]
+	resolver Resolver[N]
+}
+
+// NewLazy returns a Lazy with the given node identities, resolving node
+// values through resolver. opts configure the underlying Hash.
+func NewLazy[N any](resolver Resolver[N], ids []string, opts ...Option) *Lazy[N] {
+	nodes := make([]StringNode, len(ids))
+	for i, id := range ids {
+		nodes[i] = StringNode(id)
+	}
+	return &Lazy[N]{hash: NewWithOptions(nodes, opts...), resolver: resolver}
+}
+
+// Hash returns the Hash of node identities, through which membership and
+// weights are managed. Placement follows it immediately.
+func (l *Lazy[N]) Hash() *Hash[StringNode] {
+	return l.hash
+}
+
+// Get resolves and returns the node with the highest score for key. It
+// returns ErrNoNodes if there are no nodes, and the Resolver's error,
+// annotated with the node's identity, if the node can't be resolved.
+func (l *Lazy[N]) Get(ctx context.Context, key string) (N, error) {
+	id, ok := l.hash.Get(key)
+	if !ok {
+		var zero N
+		return zero, ErrNoNodes
+	}
+	return l.resolve(ctx, id)
+}
+
+// GetN resolves and returns no more than n nodes for key, ordered by
+// descending score. It fails if any of them can't be resolved.
+func (l *Lazy[N]) GetN(ctx context.Context, n int, key string) ([]N, error) {
+	ids := l.hash.GetN(n, key)
+	nodes := make([]N, len(ids))
+	for i, id := range ids {
+		node, err := l.resolve(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+func (l *Lazy[N]) resolve(ctx context.Context, id StringNode) (N, error) {
+	node, err := l.resolver.Resolve(ctx, string(id))
+	if err != nil {
+		return node, fmt.Errorf("rendezvous: resolving node %q: %w", id, err)
+	}
+	return node, nil
+}
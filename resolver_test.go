@@ -0,0 +1,56 @@
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	type server struct {
+		name string
+		addr string
+	}
+	var resolved []string
+	unavailable := errors.New("unavailable")
+	resolver := ResolverFunc[server](func(_ context.Context, id string) (server, error) {
+		resolved = append(resolved, id)
+		if id == "broken" {
+			return server{}, unavailable
+		}
+		return server{name: id, addr: id + ":8080"}, nil
+	})
+
+	ids := []string{"a", "b", "c"}
+	lazy := NewLazy(resolver, ids)
+	reference := New[hashableString]("a", "b", "c")
+	ctx := context.Background()
+
+	for _, key := range sampleKeys {
+		resolved = nil
+		expected, _ := reference.Get(key)
+		got, err := lazy.Get(ctx, key)
+		if err != nil || got.name != string(expected) || got.addr != string(expected)+":8080" {
+			t.Errorf("key=%q - got: (%+v, %v), expected: %v", key, got, err, expected)
+		}
+		if len(resolved) != 1 {
+			t.Errorf("key=%q - resolved %v, expected only the returned node", key, resolved)
+		}
+
+		nodes, err := lazy.GetN(ctx, 2, key)
+		if expectedN := reference.GetN(2, key); err != nil || len(nodes) != 2 || nodes[1].name != string(expectedN[1]) {
+			t.Errorf("key=%q - got: (%+v, %v), expected: %v", key, nodes, err, expectedN)
+		}
+	}
+
+	lazy.Hash().SetWeight("a", 0)
+	lazy.Hash().SetWeight("b", 0)
+	lazy.Hash().SetWeight("c", 0)
+	lazy.Hash().Add("broken")
+	if _, err := lazy.Get(ctx, "key"); !errors.Is(err, unavailable) {
+		t.Errorf("got error %v, expected the Resolver's error", err)
+	}
+	if _, err := NewLazy(resolver, nil).Get(ctx, "key"); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("got error %v, expected ErrNoNodes", err)
+	}
+}
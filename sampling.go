@@ -0,0 +1,117 @@
+package rendezvous
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// LookupSample is a lookup observed by a LookupSampler: a key and the
+// identity of the node it was routed to, at an epoch of the router's Hash.
+type LookupSample struct {
+	Key   string
+	Node  string
+	Epoch uint64
+}
+
+// LookupSampler keeps a uniform random sample of a bounded number of the
+// lookups it observes, by reservoir sampling, so that auditors can check
+// live traffic against the expected topology with AuditSamples. A Hash
+// created with WithLookupSampling samples its own lookups; other routers,
+// such as proxies in other processes, can observe theirs with a
+// LookupSampler of their own and ship its samples to the auditor.
+//
+// A LookupSampler is safe for concurrent use.
+type LookupSampler struct {
+	mu      sync.Mutex
+	size    int
+	seen    uint64
+	samples []LookupSample
+}
+
+// NewLookupSampler returns a LookupSampler keeping up to size samples.
+func NewLookupSampler(size int) *LookupSampler {
+	return &LookupSampler{size: size}
+}
+
+// Observe records a lookup of key routed to the node with identity node, at
+// epoch. Once the sampler is full, the lookup replaces a random sample with
+// probability size/seen, so every lookup observed is equally likely to be
+// kept.
+func (s *LookupSampler) Observe(key, node string, epoch uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen++
+	sample := LookupSample{Key: key, Node: node, Epoch: epoch}
+	if len(s.samples) < s.size {
+		s.samples = append(s.samples, sample)
+		return
+	}
+	if i := rand.Uint64N(s.seen); i < uint64(s.size) {
+		s.samples[i] = sample
+	}
+}
+
+// Samples returns the samples kept, in no particular order.
+func (s *LookupSampler) Samples() []LookupSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.samples)
+}
+
+// Seen returns the number of lookups observed.
+func (s *LookupSampler) Seen() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seen
+}
+
+// Reset discards the samples and the count of lookups observed, to start a
+// new audit window.
+func (s *LookupSampler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen = 0
+	s.samples = nil
+}
+
+// WithLookupSampling keeps a sample of up to size of the lookups made with
+// Get, for LookupSampler to return. Sampling adds the cost of computing the
+// chosen node's identity and taking a lock to each lookup.
+func WithLookupSampling(size int) Option {
+	return func(c *config) {
+		c.sampleSize = size
+	}
+}
+
+// LookupSampler returns the sampler of the Hash's lookups, or nil if the
+// Hash was created without WithLookupSampling.
+func (h *Hash[N]) LookupSampler() *LookupSampler {
+	return h.sampler
+}
+
+// SampleMismatch is a LookupSample routed to a node other than the one the
+// Hash expects. Expected is the identity of the expected node, or "" if the
+// Hash is empty.
+type SampleMismatch struct {
+	LookupSample
+	Expected string
+}
+
+// AuditSamples checks samples against the Hash's current topology, and
+// returns those routed to a node other than the one Get returns, in the
+// order given. Mismatches concentrated on one router, or at an old epoch,
+// point to a router still using an old membership.
+func (h *Hash[N]) AuditSamples(samples []LookupSample) []SampleMismatch {
+	var mismatches []SampleMismatch
+	for _, sample := range samples {
+		var expected string
+		if node, ok := h.get(sample.Key); ok {
+			expected = string(h.nodeID(node))
+		}
+		if expected != sample.Node {
+			mismatches = append(mismatches, SampleMismatch{LookupSample: sample, Expected: expected})
+		}
+	}
+	return mismatches
+}
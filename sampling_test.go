@@ -0,0 +1,72 @@
+package rendezvous
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLookupSampler(t *testing.T) {
+	sampler := NewLookupSampler(10)
+	for i := range 1000 {
+		sampler.Observe(fmt.Sprint(i), "a", 1)
+	}
+	if got := sampler.Seen(); got != 1000 {
+		t.Errorf("got seen: %d, expected: 1000", got)
+	}
+	samples := sampler.Samples()
+	if len(samples) != 10 {
+		t.Fatalf("got %d samples, expected 10", len(samples))
+	}
+	late := 0
+	for _, sample := range samples {
+		if sample.Key >= "1" && len(sample.Key) == 3 {
+			late++
+		}
+	}
+	if late == 0 {
+		t.Errorf("got samples %v, expected some of the later lookups to be kept", samples)
+	}
+
+	sampler.Reset()
+	if got := sampler.Samples(); len(got) != 0 || sampler.Seen() != 0 {
+		t.Errorf("got: %v, expected no samples after Reset", got)
+	}
+}
+
+func TestHashLookupSampling(t *testing.T) {
+	if New[hashableString]("a").LookupSampler() != nil {
+		t.Error("got a sampler without WithLookupSampling")
+	}
+
+	hash := NewWithOptions([]hashableString{"a", "b", "c"}, WithLookupSampling(len(sampleKeys)))
+	for _, key := range sampleKeys {
+		hash.Get(key)
+	}
+	samples := hash.LookupSampler().Samples()
+	if len(samples) != len(sampleKeys) {
+		t.Fatalf("got %d samples, expected: %d", len(samples), len(sampleKeys))
+	}
+	if got := hash.AuditSamples(samples); got != nil {
+		t.Errorf("got mismatches: %v, expected none against the same topology", got)
+	}
+
+	// A router still using the old membership routes keys to "c".
+	hash.Remove("c")
+	for _, mismatch := range hash.AuditSamples(samples) {
+		if mismatch.Node != "c" || mismatch.Epoch != 1 {
+			t.Errorf("key=%q - got mismatch on %q at epoch %d, expected only c's keys", mismatch.Key, mismatch.Node, mismatch.Epoch)
+		}
+		if expected, _ := hash.Get(mismatch.Key); mismatch.Expected != string(expected) {
+			t.Errorf("key=%q - got expected: %q, expected: %q", mismatch.Key, mismatch.Expected, expected)
+		}
+	}
+	owned := 0
+	for _, sample := range samples {
+		if sample.Node == "c" {
+			owned++
+		}
+	}
+	if got := len(hash.AuditSamples(samples)); got != owned {
+		t.Errorf("got %d mismatches, expected: %d", got, owned)
+	}
+}
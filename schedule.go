@@ -0,0 +1,206 @@
+package rendezvous
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ScheduledChange is a changeset staged to take effect at a given time.
+type ScheduledChange[N any] struct {
+	ID      uint64
+	At      time.Time
+	Changes Changeset[N]
+}
+
+// Schedule applies staged changesets to a Hash at the times they were
+// scheduled for, so that routers sharing a schedule and a synchronized clock
+// switch topologies in the same instant without out-of-band orchestration.
+// Changes scheduled for the same time are applied in the order they were
+// staged.
+//
+// A Schedule is safe for concurrent use. It modifies its Hash, so set
+// Locker if the Hash is used concurrently with it.
+type Schedule[N any] struct {
+	// Locker, if set, is held while the Hash is modified.
+	Locker sync.Locker
+	// OnError, if set, is called with the error of each change that fails
+	// to apply, such as a weight change for a node removed in the meantime.
+	OnError func(ScheduledChange[N], error)
+
+	hash *Hash[N]
+	now  func() time.Time
+
+	mu      sync.Mutex
+	pending []ScheduledChange[N]
+	lastID  uint64
+	// wake is signalled when a change is staged, so Run can reset its timer.
+	wake chan struct{}
+}
+
+// NewSchedule returns an empty Schedule for hash.
+func NewSchedule[N any](hash *Hash[N]) *Schedule[N] {
+	return &Schedule[N]{hash: hash, now: time.Now, wake: make(chan struct{}, 1)}
+}
+
+// ApplyAt stages changes to take effect at at, and returns the ID of the
+// scheduled change. Changes are validated against the Hash's current
+// topology, and ApplyAt returns an error if they can't be applied to it.
+func (s *Schedule[N]) ApplyAt(at time.Time, changes Changeset[N]) (uint64, error) {
+	if s.Locker != nil {
+		s.Locker.Lock()
+	}
+	err := s.hash.clone().Apply(changes)
+	if s.Locker != nil {
+		s.Locker.Unlock()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.lastID++
+	change := ScheduledChange[N]{ID: s.lastID, At: at, Changes: changes}
+	// Insert after every change due at or before at, so that changes due at
+	// the same time keep the order they were staged in.
+	i := slices.IndexFunc(s.pending, func(c ScheduledChange[N]) bool { return c.At.After(at) })
+	if i < 0 {
+		i = len(s.pending)
+	}
+	s.pending = slices.Insert(s.pending, i, change)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return change.ID, nil
+}
+
+// Cancel unstages the change with the given ID, and reports whether it was
+// still pending.
+func (s *Schedule[N]) Cancel(id uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.pending, func(c ScheduledChange[N]) bool { return c.ID == id })
+	if i < 0 {
+		return false
+	}
+	s.pending = slices.Delete(s.pending, i, i+1)
+	return true
+}
+
+// Pending returns the changes yet to be applied, in the order they will be.
+func (s *Schedule[N]) Pending() []ScheduledChange[N] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.pending)
+}
+
+// ApplyDue applies every pending change whose time has come, and returns
+// how many it applied. Run calls it as changes fall due; callers that don't
+// use Run may call it before lookups instead.
+func (s *Schedule[N]) ApplyDue() int {
+	now := s.now()
+	s.mu.Lock()
+	due := 0
+	for due < len(s.pending) && !s.pending[due].At.After(now) {
+		due++
+	}
+	changes := slices.Clone(s.pending[:due])
+	s.pending = slices.Delete(s.pending, 0, due)
+	s.mu.Unlock()
+
+	if s.Locker != nil {
+		s.Locker.Lock()
+		defer s.Locker.Unlock()
+	}
+	applied := 0
+	for _, change := range changes {
+		if change.Changes.Actor == "" {
+			change.Changes.Actor = fmt.Sprintf("schedule:%d", change.ID)
+		}
+		if err := s.hash.Apply(change.Changes); err != nil {
+			if s.OnError != nil {
+				s.OnError(change, err)
+			}
+			continue
+		}
+		applied++
+	}
+	return applied
+}
+
+// Run applies changes as they fall due until ctx is done, and returns ctx's
+// error.
+func (s *Schedule[N]) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		s.ApplyDue()
+		wait := time.Hour
+		s.mu.Lock()
+		if len(s.pending) > 0 {
+			wait = max(s.pending[0].At.Sub(s.now()), 0)
+		}
+		s.mu.Unlock()
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// WeightRamp is a plan to dial the weights of a group of nodes linearly from
+// one fraction of their full weight to another over a window of time, such
+// as from 0 to 1 between 02:00 and 03:00 to migrate traffic onto them
+// overnight. A node's full weight is the one it reports by implementing
+// Weighted, or 1.
+type WeightRamp[N any] struct {
+	Nodes []N
+	// From and To are the fractions of their full weight the nodes have at
+	// Start and End. A fraction of zero drains them.
+	From, To   float64
+	Start, End time.Time
+	// Steps is the number of increments from From to To, at evenly spaced
+	// times after the weights are set to From at Start. A Steps of zero or
+	// less sets the weights to To at End only.
+	Steps int
+}
+
+// Ramp stages the weight changes of ramp, one changeset per step from Start
+// to End, and returns their IDs in order, for Cancel to abandon the ramp.
+// Each step sets the weights of every node in the ramp at once. If any step
+// can't be applied to the Hash's current topology, none is staged, so the
+// nodes must already be in the Hash, such as added with a weight of zero.
+func (s *Schedule[N]) Ramp(ramp WeightRamp[N]) ([]uint64, error) {
+	steps := max(ramp.Steps, 1)
+	var ids []uint64
+	for step := 0; step <= steps; step++ {
+		if ramp.Steps <= 0 && step == 0 {
+			continue
+		}
+		progress := float64(step) / float64(steps)
+		fraction := ramp.From + (ramp.To-ramp.From)*progress
+		at := ramp.Start.Add(time.Duration(float64(ramp.End.Sub(ramp.Start)) * progress))
+		changes := Changeset[N]{Weights: make([]WeightChange[N], len(ramp.Nodes))}
+		for i, node := range ramp.Nodes {
+			changes.Weights[i] = WeightChange[N]{Node: node, Weight: initialWeight(node) * fraction}
+		}
+		id, err := s.ApplyAt(at, changes)
+		if err != nil {
+			for _, id := range ids {
+				s.Cancel(id)
+			}
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,159 @@
+package rendezvous
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduleApplyAt(t *testing.T) {
+	start := time.Now()
+	clock := start
+	routers := []*Hash[hashableString]{
+		NewWithOptions([]hashableString{"a", "b"}, WithAuditLog(NewMemoryAuditLog(10))),
+		NewWithOptions([]hashableString{"a", "b"}, WithAuditLog(NewMemoryAuditLog(10))),
+	}
+	var schedules []*Schedule[hashableString]
+	for _, router := range routers {
+		schedule := NewSchedule(router)
+		schedule.now = func() time.Time { return clock }
+		schedules = append(schedules, schedule)
+	}
+
+	cutover := start.Add(time.Minute)
+	for _, schedule := range schedules {
+		if _, err := schedule.ApplyAt(cutover, Changeset[hashableString]{Add: []hashableString{"c"}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := schedule.ApplyAt(cutover, Changeset[hashableString]{Remove: []hashableString{"a"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := schedules[0].ApplyAt(cutover, Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "z", Weight: 2}}}); err == nil {
+		t.Error("got no error staging a weight change for a missing node")
+	}
+
+	clock = cutover.Add(-time.Second)
+	for i, schedule := range schedules {
+		if applied := schedule.ApplyDue(); applied != 0 {
+			t.Errorf("router=%d - got %d changes applied before the cutover, expected none", i, applied)
+		}
+	}
+
+	clock = cutover
+	for i, schedule := range schedules {
+		if applied := schedule.ApplyDue(); applied != 2 {
+			t.Errorf("router=%d - got %d changes applied at the cutover, expected 2", i, applied)
+		}
+		if len(schedule.Pending()) != 0 {
+			t.Errorf("router=%d - got %v pending, expected none", i, schedule.Pending())
+		}
+	}
+	if !routers[0].Equal(routers[1]) {
+		t.Error("got routers with different topologies after the cutover")
+	}
+	if got, expected := routers[0].Nodes(), []hashableString{"b", "c"}; len(got) != 2 || got[0] != expected[0] || got[1] != expected[1] {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+	history := routers[0].History()
+	if got := history[len(history)-1].Actor; got != "schedule:2" {
+		t.Errorf("got actor %q, expected %q", got, "schedule:2")
+	}
+}
+
+func TestScheduleCancel(t *testing.T) {
+	hash := New[hashableString]("a", "b")
+	schedule := NewSchedule(hash)
+	later := time.Now().Add(time.Hour)
+	id, _ := schedule.ApplyAt(later, Changeset[hashableString]{Add: []hashableString{"c"}})
+	schedule.ApplyAt(later.Add(-time.Minute), Changeset[hashableString]{Add: []hashableString{"d"}})
+
+	if pending := schedule.Pending(); len(pending) != 2 || pending[0].Changes.Add[0] != "d" {
+		t.Errorf("got %v, expected the earlier change first", pending)
+	}
+	if !schedule.Cancel(id) {
+		t.Error("got false cancelling a pending change")
+	}
+	if schedule.Cancel(id) {
+		t.Error("got true cancelling a change twice")
+	}
+	schedule.now = func() time.Time { return later }
+	schedule.ApplyDue()
+	if _, ok := hash.Weight("c"); ok {
+		t.Error("got a cancelled change applied")
+	}
+	if _, ok := hash.Weight("d"); !ok {
+		t.Error("got a pending change not applied")
+	}
+}
+
+func TestScheduleRun(t *testing.T) {
+	var mu sync.Mutex
+	hash := New[hashableString]("a", "b")
+	schedule := NewSchedule(hash)
+	schedule.Locker = &mu
+	var failed error
+	schedule.OnError = func(_ ScheduledChange[hashableString], err error) { failed = err }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- schedule.Run(ctx) }()
+
+	schedule.ApplyAt(time.Now().Add(10*time.Millisecond), Changeset[hashableString]{Add: []hashableString{"c"}})
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		mu.Lock()
+		_, ok := hash.Weight("c")
+		mu.Unlock()
+		if ok {
+			break
+		}
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, expected %v", err, context.Canceled)
+	}
+	if _, ok := hash.Weight("c"); !ok {
+		t.Error("got the scheduled change not applied by Run")
+	}
+	if failed != nil {
+		t.Error(failed)
+	}
+}
+
+func TestScheduleRamp(t *testing.T) {
+	start := time.Now()
+	clock := start
+	hash := New(zonedNode{"a", "", 1}, zonedNode{"b1", "", 0}, zonedNode{"b2", "", 0})
+	schedule := NewSchedule(hash)
+	schedule.now = func() time.Time { return clock }
+
+	group := []zonedNode{{"b1", "", 2}, {"b2", "", 4}}
+	ids, err := schedule.Ramp(WeightRamp[zonedNode]{Nodes: group, From: 0, To: 1, Start: start, End: start.Add(time.Hour), Steps: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 5 {
+		t.Fatalf("got %d changes staged, expected 5", len(ids))
+	}
+
+	for step := range 5 {
+		clock = start.Add(time.Duration(step) * 15 * time.Minute)
+		if applied := schedule.ApplyDue(); applied != 1 {
+			t.Errorf("step=%d - got %d changes applied, expected 1", step, applied)
+		}
+		for _, node := range group {
+			got, _ := hash.Weight(node)
+			if expected := node.weight * float64(step) / 4; got != expected {
+				t.Errorf("step=%d node=%v - got weight: %v, expected: %v", step, node.id, got, expected)
+			}
+		}
+	}
+
+	if _, err := schedule.Ramp(WeightRamp[zonedNode]{Nodes: []zonedNode{{"c", "", 1}}, To: 1, End: start}); err == nil {
+		t.Error("got no error ramping a missing node")
+	}
+	if len(schedule.Pending()) != 0 {
+		t.Errorf("got %v pending, expected none after a failed ramp", schedule.Pending())
+	}
+}
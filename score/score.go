@@ -0,0 +1,102 @@
+// Package score computes the scores of rendezvous hashing without a Hash.
+// Its functions are stateless and allocate little, so that embedded and
+// WebAssembly programs can compute placements directly, and so that other
+// tools can verify the placements of a Hash independently.
+//
+// A node's raw score for a key is a hash of the key followed by the node's
+// identity; the node with the highest score owns the key. Each function
+// takes a seed, hashed before the key, to derive independent placements
+// from the same keys and nodes. Hashes place keys with an empty seed under
+// the default Layout.
+package score
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+	"hash/fnv"
+	"math"
+)
+
+// crc32Table is the CRC-32C table.
+var crc32Table = crc32.MakeTable(crc32.Castagnoli)
+
+// Score returns the 32-bit raw score of the CRC32C hasher: the CRC-32C of
+// seed, key and node.
+func Score(seed, key, node []byte) uint64 {
+	crc := crc32.Update(0, crc32Table, seed)
+	crc = crc32.Update(crc, crc32Table, key)
+	return uint64(crc32.Update(crc, crc32Table, node))
+}
+
+// Hash128 returns the 64-bit raw score and tie-breaking word of the Hash128
+// hasher.
+func Hash128(seed, key, node []byte) (score, tiebreak uint64) {
+	h := fnv.New128a()
+	h.Write(seed)
+	h.Write(key)
+	h.Write(node)
+	var buf [16]byte
+	return Finish128(h.Sum(buf[:0]))
+}
+
+// Finish128 derives the raw score and tie-breaking word of Hash128 from a
+// 128-bit FNV-1a sum, for callers hashing their input incrementally.
+func Finish128(sum []byte) (score, tiebreak uint64) {
+	hi, lo := binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])
+	lo = mix64(lo)
+	return mix64(hi ^ lo), lo
+}
+
+// SHA256 returns the 64-bit raw score and tie-breaking word of the SHA256
+// hasher.
+func SHA256(seed, key, node []byte) (score, tiebreak uint64) {
+	h := sha256.New()
+	h.Write(seed)
+	h.Write(key)
+	h.Write(node)
+	var buf [sha256.Size]byte
+	return FinishSHA256(h.Sum(buf[:0]))
+}
+
+// FinishSHA256 derives the raw score and tie-breaking word of SHA256 from a
+// SHA-256 sum, for callers hashing their input incrementally.
+func FinishSHA256(sum []byte) (score, tiebreak uint64) {
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])
+}
+
+// Weighted scales raw, a bits-wide raw score, by weight using the
+// logarithmic method: mapped onto (0, 1) as u, it becomes weight / -ln(u),
+// under which a node wins a key with probability proportional to its
+// weight. bits must be at most 53.
+func Weighted(raw uint64, bits int, weight float64) float64 {
+	u := (float64(raw) + 0.5) / float64(uint64(1)<<bits)
+	return weight / -math.Log(u)
+}
+
+// Top returns the index of the node that owns key among nodes, given as
+// identities, under the CRC32C hasher with uniform weights, or -1 if nodes
+// is empty. Nodes with equal scores are ordered by identity, lowest first.
+func Top(seed, key []byte, nodes [][]byte) int {
+	top := -1
+	var topScore uint64
+	for i, node := range nodes {
+		s := Score(seed, key, node)
+		if top < 0 || s > topScore || (s == topScore && bytes.Compare(node, nodes[top]) < 0) {
+			top, topScore = i, s
+		}
+	}
+	return top
+}
+
+// mix64 is the 64-bit finalizer of MurmurHash3, a bijection under which
+// every input bit affects every output bit.
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,54 @@
+package score
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	// The CRC-32C check value, split across every argument.
+	if got := Score([]byte("123"), []byte("456"), []byte("789")); got != 0xe3069283 {
+		t.Errorf("got: %#x, expected: %#x", got, 0xe3069283)
+	}
+	if Score(nil, []byte("key"), []byte("node")) == Score([]byte("seed"), []byte("key"), []byte("node")) {
+		t.Error("got the same score with and without a seed")
+	}
+}
+
+func TestHash128AndSHA256(t *testing.T) {
+	for _, fn := range []func(seed, key, node []byte) (uint64, uint64){Hash128, SHA256} {
+		a, tieA := fn(nil, []byte("key"), []byte("node"))
+		b, tieB := fn([]byte("ke"), []byte("y"), []byte("node"))
+		if a != b || tieA != tieB {
+			t.Errorf("got %#x/%#x and %#x/%#x, expected the input to be hashed as one string", a, tieA, b, tieB)
+		}
+	}
+}
+
+func TestTop(t *testing.T) {
+	nodes := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		top := Top(nil, key, nodes)
+		for j, node := range nodes {
+			if Score(nil, key, node) > Score(nil, key, nodes[top]) {
+				t.Errorf("key=%q - got %s, expected %s to outscore it", key, nodes[top], nodes[j])
+			}
+		}
+	}
+	if got := Top(nil, []byte("key"), [][]byte{[]byte("x"), []byte("x")}); got != 0 {
+		t.Errorf("got: %d, expected: 0 for tied identical nodes", got)
+	}
+	if got := Top(nil, []byte("key"), nil); got != -1 {
+		t.Errorf("got: %d, expected: -1", got)
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	if low, high := Weighted(1<<31, 32, 1), Weighted(1<<31, 32, 2); high != 2*low {
+		t.Errorf("got %v and %v, expected scores proportional to weight", low, high)
+	}
+	if a, b := Weighted(1<<30, 32, 1), Weighted(1<<31, 32, 1); a >= b {
+		t.Errorf("got %v and %v, expected scores increasing with raw", a, b)
+	}
+}
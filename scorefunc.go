@@ -0,0 +1,26 @@
+package rendezvous
+
+// ScoreFunc combines the hash of a key and the hash of a node's identity,
+// each computed alone by the Hash's Hasher, with the node's effective
+// weight into the node's score for the key. The node with the highest score
+// wins the key; ties are broken as usual.
+type ScoreFunc func(keyDigest, nodeDigest uint64, weight float64) float64
+
+// WithScoreFunc replaces the final scoring step with score, for custom
+// weighting curves or research variants. Digests are as wide as the
+// Hasher's raw scores: 32 bits for CRC32C and 64 bits otherwise. The Layout
+// doesn't apply to them.
+//
+// A ScoreFunc must mix the two digests itself: combining them with XOR or
+// addition alone leaves scores correlated across keys, and placement
+// unbalanced and unstable.
+func WithScoreFunc(score ScoreFunc) Option {
+	return func(c *config) {
+		c.scoreFunc = score
+	}
+}
+
+// customScore is scoreWith for a Hash with a ScoreFunc.
+func (h *Hash[N]) customScore(s *scorer, ns *nodeScore[N]) float64 {
+	return h.scoreFunc(s.digestKey(), ns.digest, ns.effective)
+}
@@ -0,0 +1,47 @@
+package rendezvous
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous/score"
+)
+
+func TestHashWithScoreFunc(t *testing.T) {
+	// Highest node digest wins, regardless of the key.
+	byNode := NewWithOptions([]hashableString{"a", "b", "c"}, WithScoreFunc(func(_, nodeDigest uint64, _ float64) float64 {
+		return float64(nodeDigest)
+	}))
+	var expected hashableString
+	var best uint64
+	for _, node := range []hashableString{"a", "b", "c"} {
+		if digest, _ := (crc32Digest{}).sum(node.Bytes(), nil); digest >= best {
+			best, expected = digest, node
+		}
+	}
+	for _, key := range sampleKeys {
+		if got, _ := byNode.Get(key); got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+
+	// A well-mixed weighted variant splits keys by weight.
+	weighted := NewWithOptions([]hashableString{}, WithHasher(Hash128), WithScoreFunc(func(keyDigest, nodeDigest uint64, weight float64) float64 {
+		x := keyDigest ^ (nodeDigest * 0x9e3779b97f4a7c15)
+		x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+		x = (x ^ x>>27) * 0x94d049bb133111eb
+		return score.Weighted((x^x>>31)>>11, 53, weight)
+	}))
+	weighted.Add("a", "b")
+	weighted.SetWeight("a", 3)
+	counts := make(map[hashableString]int)
+	const samples = 20000
+	for i := 0; i < samples; i++ {
+		node, _ := weighted.Get(fmt.Sprintf("key-%d", i))
+		counts[node]++
+	}
+	if share := float64(counts["a"]) / samples; math.Abs(share-0.75) > 0.02 {
+		t.Errorf("got share %v for a, expected 0.75", share)
+	}
+}
@@ -0,0 +1,26 @@
+package rendezvous
+
+// Selector is the interface of a placement engine: it selects nodes for
+// keys, and tracks which nodes there are to select from. Hash and
+// ShardedHash implement it, as does rendezvoustest.Fake, so that
+// applications can depend on Selector and swap in alternative engines, such
+// as table-based or hierarchical ones or test fakes, without changing call
+// sites.
+type Selector[N any] interface {
+	// Get returns the node selected for key, and false if there are no
+	// nodes.
+	Get(key string) (N, bool)
+	// GetN returns up to n distinct nodes for key, in order of preference.
+	GetN(n int, key string) []N
+	// Add adds nodes.
+	Add(nodes ...N)
+	// Remove removes node.
+	Remove(node N)
+	// Nodes returns the nodes.
+	Nodes() []N
+}
+
+var (
+	_ Selector[StringNode] = (*Hash[StringNode])(nil)
+	_ Selector[StringNode] = (*ShardedHash[StringNode])(nil)
+)
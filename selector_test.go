@@ -0,0 +1,29 @@
+package rendezvous
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelector(t *testing.T) {
+	selectors := map[string]Selector[hashableString]{
+		"hash":    New[hashableString](),
+		"sharded": NewSharded[hashableString](4, nil),
+	}
+	for name, selector := range selectors {
+		selector.Add("a", "b", "c")
+		selector.Remove("b")
+		nodes := selector.Nodes()
+		slices.Sort(nodes)
+		if !slices.Equal(nodes, []hashableString{"a", "c"}) {
+			t.Errorf("selector=%s - got nodes %v, expected [a c]", name, nodes)
+		}
+		for _, key := range sampleKeys {
+			node, ok := selector.Get(key)
+			ranked := selector.GetN(2, key)
+			if !ok || len(ranked) != 2 || ranked[0] != node {
+				t.Errorf("selector=%s key=%q - got Get %v and GetN %v, expected GetN to start with Get", name, key, node, ranked)
+			}
+		}
+	}
+}
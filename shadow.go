@@ -0,0 +1,32 @@
+package rendezvous
+
+// GetWithShadow returns the node Get returns for key, and a deterministic
+// shadow to mirror its traffic to: the second highest scoring node. If the
+// Hash has a single node, the shadow is the primary. It returns false if h
+// is empty.
+func (h *Hash[N]) GetWithShadow(key string) (primary, shadow N, ok bool) {
+	first, second := h.topTwo(h.keyBytes(key))
+	if first < 0 {
+		return primary, shadow, false
+	}
+	if second < 0 {
+		second = first
+	}
+	return h.nodes[first].node, h.nodes[second].node, true
+}
+
+// GetWithShadowPool is GetWithShadow with the shadow chosen from pool, a
+// Hash of nodes that take no traffic of their own, such as new cache nodes
+// being dark launched. Each key's shadow is the node pool.Get returns, so
+// every pool node mirrors a stable share of the keyspace. If pool is empty,
+// the shadow is chosen as by GetWithShadow.
+func (h *Hash[N]) GetWithShadowPool(key string, pool *Hash[N]) (primary, shadow N, ok bool) {
+	primary, ok = h.Get(key)
+	if !ok {
+		return primary, shadow, false
+	}
+	if shadow, found := pool.Get(key); found {
+		return primary, shadow, true
+	}
+	return h.GetWithShadow(key)
+}
@@ -0,0 +1,39 @@
+package rendezvous
+
+import "testing"
+
+func TestHashGetWithShadow(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	for _, key := range sampleKeys {
+		primary, shadow, ok := hash.GetWithShadow(key)
+		ranked := hash.GetN(2, key)
+		if !ok || primary != ranked[0] || shadow != ranked[1] {
+			t.Errorf("key=%q - got: %v %v, expected: %v", key, primary, shadow, ranked)
+		}
+	}
+
+	if primary, shadow, ok := New[hashableString]("a").GetWithShadow("foo"); !ok || primary != "a" || shadow != "a" {
+		t.Errorf("got %v %v %v, expected a single node to shadow itself", primary, shadow, ok)
+	}
+	if _, _, ok := New[hashableString]().GetWithShadow("foo"); ok {
+		t.Error("got ok from an empty Hash")
+	}
+}
+
+func TestHashGetWithShadowPool(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	pool := New[hashableString]("new-1", "new-2")
+	for _, key := range sampleKeys {
+		primary, shadow, ok := hash.GetWithShadowPool(key, pool)
+		expectedPrimary, _ := hash.Get(key)
+		expectedShadow, _ := pool.Get(key)
+		if !ok || primary != expectedPrimary || shadow != expectedShadow {
+			t.Errorf("key=%q - got: %v %v, expected: %v %v", key, primary, shadow, expectedPrimary, expectedShadow)
+		}
+	}
+
+	_, shadow, _ := hash.GetWithShadowPool("foo", New[hashableString]())
+	if _, expected, _ := hash.GetWithShadow("foo"); shadow != expected {
+		t.Errorf("got shadow %v, expected %v from an empty pool", shadow, expected)
+	}
+}
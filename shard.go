@@ -0,0 +1,37 @@
+package rendezvous
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// ShardFor maps key to a logical shard in [0, shards) using rendezvous
+// hashing over virtual shard IDs. Each shard's identity is its index encoded
+// as a big-endian uint64, so a key's shard depends only on the key and the
+// shard count and never on the physical nodes serving the shards. Changing
+// shards from M to M+1 moves only the keys that land on the new shard.
+//
+// ShardFor panics if shards is not positive.
+func ShardFor(key string, shards int) int {
+	if shards <= 0 {
+		panic("rendezvous: ShardFor requires a positive shard count")
+	}
+
+	keySum := crc32.Update(0, crc32Table, unsafeBytes(key))
+
+	var id [8]byte
+	maxShard := 0
+	maxScore := crc32.Update(keySum, crc32Table, id[:])
+
+	for shard := 1; shard < shards; shard++ {
+		binary.BigEndian.PutUint64(id[:], uint64(shard))
+		// Shard IDs are big-endian, so on equal scores the lowest index
+		// already has the lowest bytes and wins the tie.
+		if score := crc32.Update(keySum, crc32Table, id[:]); score > maxScore {
+			maxScore = score
+			maxShard = shard
+		}
+	}
+
+	return maxShard
+}
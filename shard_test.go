@@ -0,0 +1,37 @@
+package rendezvous
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardFor(t *testing.T) {
+	for _, key := range sampleKeys {
+		shard := ShardFor(key, 16)
+		if shard < 0 || shard >= 16 {
+			t.Fatalf("key=%q - got shard %d, expected [0, 16)", key, shard)
+		}
+		if again := ShardFor(key, 16); again != shard {
+			t.Errorf("key=%q - got: %d, expected: %d", key, again, shard)
+		}
+	}
+
+	if shard := ShardFor("foo", 1); shard != 0 {
+		t.Errorf("got: %d, expected: 0", shard)
+	}
+
+	// Growing the shard count must only move keys onto the new shard.
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before, after := ShardFor(key, 8), ShardFor(key, 9)
+		if before != after && after != 8 {
+			t.Errorf("key=%q moved from shard %d to %d", key, before, after)
+		}
+	}
+}
+
+func BenchmarkShardFor_64shards(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ShardFor(sampleKeys[i%len(sampleKeys)], 64)
+	}
+}
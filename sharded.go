@@ -0,0 +1,195 @@
+package rendezvous
+
+import (
+	"bytes"
+	"cmp"
+	"hash/crc32"
+	"slices"
+	"sync"
+)
+
+// ShardedHash spreads its nodes across a fixed number of internal shards,
+// each a Hash with its own lock. Lookups score each shard independently,
+// optionally in parallel, and merge the results, so they return the same
+// nodes as a single Hash with the same nodes and weights. Membership
+// changes lock only the shard that holds the node, so updates to different
+// shards proceed concurrently with each other and with lookups of other
+// shards.
+//
+// Zone weights are not supported: they are normalized across all nodes,
+// which no single shard can see. A ShardedHash is safe for concurrent use.
+type ShardedHash[N Hashable] struct {
+	shards   []shard[N]
+	parallel bool
+}
+
+// shard is one of a ShardedHash's shards.
+type shard[N any] struct {
+	mu   sync.Mutex
+	hash *Hash[N]
+}
+
+// candidate is a node returned by a shard, with what's needed to rank it
+// against the candidates of other shards.
+type candidate[N any] struct {
+	node     N
+	id       []byte
+	score    float64
+	tiebreak uint64
+}
+
+// NewSharded returns a ShardedHash with the given number of shards and
+// nodes. opts configure every shard; WithParallelism scores shards in
+// parallel rather than splitting the scoring of each shard. Lookup caches
+// are per shard. NewSharded panics if shards is not positive.
+func NewSharded[N Hashable](shards int, nodes []N, opts ...Option) *ShardedHash[N] {
+	if shards <= 0 {
+		panic("rendezvous: shard count must be positive")
+	}
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	opts = append(slices.Clip(opts), WithParallelism(0))
+
+	s := &ShardedHash[N]{
+		shards:   make([]shard[N], shards),
+		parallel: cfg.parallelism > 1,
+	}
+	for i := range s.shards {
+		s.shards[i].hash = NewWithOptions[N](nil, opts...)
+		s.shards[i].hash.logScores = true
+	}
+	s.Add(nodes...)
+	return s
+}
+
+// shardOf returns the shard holding node.
+func (s *ShardedHash[N]) shardOf(node N) *shard[N] {
+	return &s.shards[crc32.Checksum(node.Bytes(), crc32Table)%uint32(len(s.shards))]
+}
+
+// Add adds nodes, locking each node's shard in turn.
+func (s *ShardedHash[N]) Add(nodes ...N) {
+	for _, node := range nodes {
+		sh := s.shardOf(node)
+		sh.mu.Lock()
+		sh.hash.Add(node)
+		sh.mu.Unlock()
+	}
+}
+
+// Remove removes every node whose identity matches node's.
+func (s *ShardedHash[N]) Remove(node N) {
+	sh := s.shardOf(node)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.hash.Remove(node)
+}
+
+// SetWeight sets the weight of node, as Hash.SetWeight does.
+func (s *ShardedHash[N]) SetWeight(node N, weight float64) bool {
+	sh := s.shardOf(node)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.hash.SetWeight(node, weight)
+}
+
+// Nodes returns the nodes in canonical order.
+func (s *ShardedHash[N]) Nodes() []N {
+	var nodes []nodeScore[N]
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		nodes = append(nodes, sh.hash.nodes...)
+		sh.mu.Unlock()
+	}
+	slices.SortStableFunc(nodes, func(a, b nodeScore[N]) int {
+		return bytes.Compare(a.id, b.id)
+	})
+
+	result := make([]N, len(nodes))
+	for i := range nodes {
+		result[i] = nodes[i].node
+	}
+	return result
+}
+
+// Get returns the node with the highest score for key, and false if there
+// are no nodes.
+func (s *ShardedHash[N]) Get(key string) (N, bool) {
+	candidates := s.collect(1, key)
+	if len(candidates) == 0 {
+		var zero N
+		return zero, false
+	}
+	return candidates[0].node, true
+}
+
+// GetN returns no more than n nodes for key, ordered by descending score.
+func (s *ShardedHash[N]) GetN(n int, key string) []N {
+	candidates := s.collect(n, key)
+	nodes := make([]N, len(candidates))
+	for i := range candidates {
+		nodes[i] = candidates[i].node
+	}
+	return nodes
+}
+
+// collect returns the n highest ranked nodes for key across all shards, in
+// rank order.
+func (s *ShardedHash[N]) collect(n int, key string) []candidate[N] {
+	if n <= 0 {
+		return nil
+	}
+
+	perShard := make([][]candidate[N], len(s.shards))
+	if s.parallel {
+		var wg sync.WaitGroup
+		for i := range s.shards {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				perShard[i] = s.shards[i].top(n, key)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range s.shards {
+			perShard[i] = s.shards[i].top(n, key)
+		}
+	}
+
+	candidates := slices.Concat(perShard...)
+	slices.SortFunc(candidates, func(a, b candidate[N]) int {
+		if a.score != b.score {
+			return cmp.Compare(b.score, a.score)
+		}
+		if a.tiebreak != b.tiebreak {
+			return cmp.Compare(b.tiebreak, a.tiebreak)
+		}
+		return bytes.Compare(a.id, b.id)
+	})
+	return candidates[:min(n, len(candidates))]
+}
+
+// top returns the shard's n highest ranked nodes for key.
+func (sh *shard[N]) top(n int, key string) []candidate[N] {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	h := sh.hash
+	if len(h.nodes) == 0 {
+		return nil
+	}
+	keyBytes := h.keyBytes(key)
+	h.rank(keyBytes)
+
+	candidates := make([]candidate[N], min(n, len(h.order)))
+	for i := range candidates {
+		ns := &h.nodes[h.order[i]]
+		_, tiebreak := h.scorer.sum(h.layout, keyBytes, ns.id)
+		candidates[i] = candidate[N]{node: ns.node, id: ns.id, score: ns.score, tiebreak: tiebreak}
+	}
+	return candidates
+}
@@ -0,0 +1,60 @@
+package rendezvous
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestShardedHash(t *testing.T) {
+	nodes := make([]hashableString, 50)
+	for i := range nodes {
+		nodes[i] = hashableString(fmt.Sprintf("node-%d", i))
+	}
+
+	for _, opts := range [][]Option{nil, {WithParallelism(4)}, {WithHasher(Hash128)}} {
+		sharded := NewSharded(8, nodes, opts...)
+		reference := NewWithOptions(nodes, opts...)
+		sharded.SetWeight(nodes[3], 3)
+		reference.SetWeight(nodes[3], 3)
+		sharded.Remove(nodes[7])
+		reference.Remove(nodes[7])
+
+		if got, expected := sharded.Nodes(), reference.Nodes(); !slices.Equal(got, expected) {
+			t.Errorf("got nodes %v, expected: %v", got, expected)
+		}
+		for _, key := range sampleKeys {
+			expected, _ := reference.Get(key)
+			if got, _ := sharded.Get(key); got != expected {
+				t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+			}
+			if got, expected := sharded.GetN(5, key), reference.GetN(5, key); !slices.Equal(got, expected) {
+				t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+			}
+		}
+	}
+}
+
+func TestShardedHashConcurrent(t *testing.T) {
+	sharded := NewSharded[hashableString](4, nil)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				node := hashableString(fmt.Sprintf("node-%d-%d", w, i))
+				sharded.Add(node)
+				sharded.GetN(3, sampleKeys[i%len(sampleKeys)])
+				if i%2 == 0 {
+					sharded.Remove(node)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(sharded.Nodes()); got != 200 {
+		t.Errorf("got %d nodes, expected 200", got)
+	}
+}
@@ -0,0 +1,292 @@
+// Package sim replays scripted sequences of node joins, failures and flaps
+// against a rendezvous.Hash, and reports how keys move and how long they are
+// unavailable, so that operational policies such as drains, slow starts and
+// bounded-load lookups can be validated before they are enabled in
+// production.
+//
+// A script is a list of Events in simulated time. Topology changes, such as
+// a join or a weight change, are applied to the Hash as changesets; failures
+// and recoveries only change whether a node answers, so lookups keep routing
+// to a failed node until the script, or the simulated health checker enabled
+// by DetectAfter, removes it.
+package sim
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// Event is a scripted change at a point in simulated time. Events at the
+// same time are applied in script order before keys are looked up.
+type Event[N any] struct {
+	At time.Duration
+	// Changes is applied to the Hash.
+	Changes rendezvous.Changeset[N]
+	// Down lists nodes that fail, and Up nodes that recover. Neither
+	// changes the topology by itself.
+	Down, Up []N
+}
+
+// Step is the state of the keyspace after the events at a point in time.
+type Step struct {
+	At    time.Duration
+	Epoch uint64
+	// Moved is the number of keys whose primary changed since the previous
+	// step, or since the start of the simulation.
+	Moved int
+	// Violations is the number of keys with fewer than Replicas healthy
+	// replicas.
+	Violations int
+	// Unavailable is the number of keys none of whose replicas is healthy.
+	Unavailable int
+}
+
+// Window is a period during which some keys were unavailable.
+type Window struct {
+	Start, End time.Duration
+	// Peak is the largest number of keys unavailable at once.
+	Peak int
+	// Open is true if the window had not closed by the last step, in which
+	// case End is the time of the last step.
+	Open bool
+}
+
+// Report summarizes a simulation.
+type Report struct {
+	Steps []Step
+	// Moved is the total number of primary moves across all steps.
+	Moved int
+	// MaxViolations and MaxUnavailable are the largest Violations and
+	// Unavailable of any step.
+	MaxViolations, MaxUnavailable int
+	Windows                       []Window
+}
+
+// Simulation replays scripts against a Hash, which it modifies.
+type Simulation[N any] struct {
+	// Replicas is the number of replicas each key should have. It defaults
+	// to 1.
+	Replicas int
+	// Lookup returns the replicas of key, and defaults to the Hash's GetN.
+	// Set it to simulate a lookup policy such as bounded load.
+	Lookup func(hash *rendezvous.Hash[N], n int, key string) []N
+	// DetectAfter, if positive, simulates a health checker: a node that
+	// stays down for DetectAfter is removed from the Hash, and added back
+	// with its former weight when it recovers.
+	DetectAfter time.Duration
+
+	hash *rendezvous.Hash[N]
+	keys []string
+}
+
+// New returns a Simulation of keys placed by hash.
+func New[N any](hash *rendezvous.Hash[N], keys []string) *Simulation[N] {
+	return &Simulation[N]{hash: hash, keys: keys}
+}
+
+// downNode is a node that is down, and when it went down.
+type downNode[N any] struct {
+	node  N
+	since time.Duration
+	// removed is true once the health checker removed the node, and
+	// weight is the weight it had.
+	removed bool
+	weight  float64
+}
+
+// primary is the identity of a key's primary, if it has one.
+type primary struct {
+	id string
+	ok bool
+}
+
+// Run replays events, which need not be sorted, and reports the state of
+// the keyspace at the start and after each point in time at which events
+// happen. It returns an error if a changeset fails to apply.
+func (s *Simulation[N]) Run(events []Event[N]) (Report, error) {
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b Event[N]) int { return cmp.Compare(a.At, b.At) })
+
+	var report Report
+	down := make(map[string]*downNode[N])
+	primaries := make([]primary, len(s.keys))
+	s.observe(&report, 0, down, primaries, true)
+
+	for len(events) > 0 || s.nextDetection(down) >= 0 {
+		at := s.nextDetection(down)
+		if len(events) > 0 && (at < 0 || events[0].At <= at) {
+			at = events[0].At
+		}
+		for len(events) > 0 && events[0].At == at {
+			if err := s.apply(events[0], down); err != nil {
+				return report, err
+			}
+			events = events[1:]
+		}
+		if err := s.detect(at, down); err != nil {
+			return report, err
+		}
+		s.observe(&report, at, down, primaries, false)
+	}
+
+	if n := len(report.Windows); n > 0 && report.Windows[n-1].Open {
+		report.Windows[n-1].End = report.Steps[len(report.Steps)-1].At
+	}
+	return report, nil
+}
+
+// apply applies event, recording the nodes it takes down or brings up.
+func (s *Simulation[N]) apply(event Event[N], down map[string]*downNode[N]) error {
+	if err := s.hash.Apply(event.Changes); err != nil {
+		return err
+	}
+	for _, node := range event.Down {
+		id := string(s.hash.Identity(node))
+		if _, ok := down[id]; !ok {
+			down[id] = &downNode[N]{node: node, since: event.At}
+		}
+	}
+	var recovered rendezvous.Changeset[N]
+	for _, node := range event.Up {
+		id := string(s.hash.Identity(node))
+		if d, ok := down[id]; ok && d.removed {
+			recovered.Add = append(recovered.Add, d.node)
+			recovered.Weights = append(recovered.Weights, rendezvous.WeightChange[N]{Node: d.node, Weight: d.weight})
+		}
+		delete(down, id)
+	}
+	recovered.Actor = "sim:recover"
+	return s.hash.Apply(recovered)
+}
+
+// nextDetection returns when the health checker next removes a node, or -1
+// if it has nothing to remove.
+func (s *Simulation[N]) nextDetection(down map[string]*downNode[N]) time.Duration {
+	next := time.Duration(-1)
+	if s.DetectAfter <= 0 {
+		return next
+	}
+	for _, d := range down {
+		if at := d.since + s.DetectAfter; !d.removed && (next < 0 || at < next) {
+			next = at
+		}
+	}
+	return next
+}
+
+// detect removes the nodes that have been down for DetectAfter by at.
+func (s *Simulation[N]) detect(at time.Duration, down map[string]*downNode[N]) error {
+	if s.DetectAfter <= 0 {
+		return nil
+	}
+	removed := rendezvous.Changeset[N]{Actor: "sim:detect"}
+	for _, d := range down {
+		if d.removed || d.since+s.DetectAfter > at {
+			continue
+		}
+		weight, ok := s.hash.Weight(d.node)
+		if !ok {
+			continue
+		}
+		d.removed, d.weight = true, weight
+		removed.Remove = append(removed.Remove, d.node)
+	}
+	return s.hash.Apply(removed)
+}
+
+// observe looks up every key, and records the resulting step in report.
+func (s *Simulation[N]) observe(report *Report, at time.Duration, down map[string]*downNode[N], primaries []primary, first bool) {
+	replicas := max(s.Replicas, 1)
+	lookup := s.Lookup
+	if lookup == nil {
+		lookup = func(hash *rendezvous.Hash[N], n int, key string) []N { return hash.GetN(n, key) }
+	}
+
+	step := Step{At: at, Epoch: s.hash.Epoch()}
+	for i, key := range s.keys {
+		nodes := lookup(s.hash, replicas, key)
+		healthy := 0
+		for _, node := range nodes {
+			if _, ok := down[string(s.hash.Identity(node))]; !ok {
+				healthy++
+			}
+		}
+		if healthy < replicas {
+			step.Violations++
+		}
+		if healthy == 0 {
+			step.Unavailable++
+		}
+
+		var p primary
+		if len(nodes) > 0 {
+			p = primary{id: string(s.hash.Identity(nodes[0])), ok: true}
+		}
+		if !first && p != primaries[i] {
+			step.Moved++
+		}
+		primaries[i] = p
+	}
+
+	report.Steps = append(report.Steps, step)
+	report.Moved += step.Moved
+	report.MaxViolations = max(report.MaxViolations, step.Violations)
+	report.MaxUnavailable = max(report.MaxUnavailable, step.Unavailable)
+
+	n := len(report.Windows)
+	open := n > 0 && report.Windows[n-1].Open
+	switch {
+	case step.Unavailable > 0 && !open:
+		report.Windows = append(report.Windows, Window{Start: at, Peak: step.Unavailable, Open: true})
+	case step.Unavailable > 0:
+		report.Windows[n-1].Peak = max(report.Windows[n-1].Peak, step.Unavailable)
+	case open:
+		report.Windows[n-1].End, report.Windows[n-1].Open = at, false
+	}
+}
+
+// Flap returns the events of node failing count times, starting at start:
+// each time it stays down for downFor, then up for upFor.
+func Flap[N any](node N, start time.Duration, count int, downFor, upFor time.Duration) []Event[N] {
+	var events []Event[N]
+	at := start
+	for range count {
+		events = append(events, Event[N]{At: at, Down: []N{node}}, Event[N]{At: at + downFor, Up: []N{node}})
+		at += downFor + upFor
+	}
+	return events
+}
+
+// SlowStart returns the events of node joining at start with a small weight
+// that ramps up in steps equal increments to weight over ramp.
+func SlowStart[N any](node N, start time.Duration, weight float64, ramp time.Duration, steps int) []Event[N] {
+	steps = max(steps, 1)
+	events := make([]Event[N], steps)
+	for i := range events {
+		events[i] = Event[N]{
+			At:      start + ramp*time.Duration(i)/time.Duration(steps),
+			Changes: rendezvous.Changeset[N]{Actor: "sim:slow-start", Weights: []rendezvous.WeightChange[N]{{Node: node, Weight: weight * float64(i+1) / float64(steps)}}},
+		}
+	}
+	events[0].Changes.Add = []N{node}
+	return events
+}
+
+// Drain returns the events of node, of the given weight, draining from
+// start: its weight falls in steps equal decrements to 0 over drain, after
+// which it is removed.
+func Drain[N any](node N, start time.Duration, weight float64, drain time.Duration, steps int) []Event[N] {
+	steps = max(steps, 1)
+	events := make([]Event[N], steps+1)
+	for i := range steps {
+		events[i] = Event[N]{
+			At:      start + drain*time.Duration(i)/time.Duration(steps),
+			Changes: rendezvous.Changeset[N]{Actor: "sim:drain", Weights: []rendezvous.WeightChange[N]{{Node: node, Weight: weight * float64(steps-i-1) / float64(steps)}}},
+		}
+	}
+	events[steps] = Event[N]{At: start + drain, Changes: rendezvous.Changeset[N]{Actor: "sim:drain", Remove: []N{node}}}
+	return events
+}
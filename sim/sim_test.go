@@ -0,0 +1,118 @@
+package sim_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+	"github.com/beam-cloud/rendezvous/sim"
+)
+
+type node string
+
+func (n node) Bytes() []byte {
+	return []byte(n)
+}
+
+// newHash returns a Hash of nodes scored with Hash128, whose shares are
+// closer to even than CRC32C's for short node identities.
+func newHash(nodes ...node) *rendezvous.Hash[node] {
+	return rendezvous.NewWithOptions(nodes, rendezvous.WithHasher(rendezvous.Hash128))
+}
+
+func keys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+	return keys
+}
+
+func TestSimulationFailure(t *testing.T) {
+	simulation := sim.New(newHash("a", "b", "c", "d"), keys(4000))
+	simulation.Replicas = 2
+	simulation.DetectAfter = 10 * time.Second
+
+	report, err := simulation.Run([]sim.Event[node]{
+		{At: time.Minute, Down: []node{"a"}},
+		{At: 2 * time.Minute, Up: []node{"a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The start, the failure, its detection, and the recovery.
+	if len(report.Steps) != 4 {
+		t.Fatalf("got steps %+v, expected 4", report.Steps)
+	}
+	failed, detected, recovered := report.Steps[1], report.Steps[2], report.Steps[3]
+	if failed.Moved != 0 || failed.Violations < 1500 || failed.Violations > 2500 {
+		t.Errorf("got %+v on failure, expected about half the keys short of a replica and none moved", failed)
+	}
+	if failed.Unavailable != 0 {
+		t.Errorf("got %d keys unavailable with 2 replicas and a single failure", failed.Unavailable)
+	}
+	if detected.At != time.Minute+10*time.Second || detected.Violations != 0 || detected.Moved < 800 || detected.Moved > 1200 {
+		t.Errorf("got %+v after detection, expected a's quarter of primaries moved and no violations", detected)
+	}
+	if recovered.Moved != detected.Moved {
+		t.Errorf("got %d moved on recovery, expected the %d moved away to return", recovered.Moved, detected.Moved)
+	}
+	if report.MaxViolations != failed.Violations || len(report.Windows) != 0 {
+		t.Errorf("got %+v, expected no unavailability windows", report)
+	}
+}
+
+func TestSimulationUnavailability(t *testing.T) {
+	simulation := sim.New(newHash("a", "b", "c", "d"), keys(4000))
+	report, err := simulation.Run(sim.Flap[node]("a", time.Second, 2, 5*time.Second, 10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Windows) != 2 {
+		t.Fatalf("got windows %+v, expected one per flap", report.Windows)
+	}
+	for i, window := range report.Windows {
+		start := time.Second + time.Duration(i)*15*time.Second
+		if window.Start != start || window.End != start+5*time.Second || window.Open || window.Peak < 800 || window.Peak > 1200 {
+			t.Errorf("got window %+v, expected a quarter of keys down from %v for 5s", window, start)
+		}
+	}
+	if report.Moved != 0 {
+		t.Errorf("got %d moved, expected flaps without detection to move nothing", report.Moved)
+	}
+
+	report, _ = simulation.Run([]sim.Event[node]{{At: time.Second, Down: []node{"b"}}})
+	if len(report.Windows) != 1 || !report.Windows[0].Open || report.Windows[0].End != time.Second {
+		t.Errorf("got windows %+v, expected one still open at the last step", report.Windows)
+	}
+}
+
+func TestSimulationSlowStartAndDrain(t *testing.T) {
+	hash := newHash("a", "b", "c")
+	simulation := sim.New(hash, keys(4000))
+
+	events := append(sim.SlowStart[node]("d", 0, 1, time.Minute, 4), sim.Drain[node]("a", 2*time.Minute, 1, time.Minute, 4)...)
+	report, err := simulation.Run(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Steps) != 10 {
+		t.Fatalf("got %d steps, expected the start and 9 events", len(report.Steps))
+	}
+	for _, step := range report.Steps {
+		if step.Moved > 500 {
+			t.Errorf("got %d moved at %v, expected gradual steps to move at most about a sixteenth each", step.Moved, step.At)
+		}
+		if step.Violations != 0 {
+			t.Errorf("got %d violations at %v, expected none without failures", step.Violations, step.At)
+		}
+	}
+	if _, ok := hash.Weight("a"); ok {
+		t.Error("got a still present after its drain")
+	}
+	if weight, _ := hash.Weight("d"); weight != 1 {
+		t.Errorf("got d with weight %v after its slow start, expected 1", weight)
+	}
+}
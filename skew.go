@@ -0,0 +1,117 @@
+package rendezvous
+
+import (
+	"math"
+	"sync"
+)
+
+// SkewAlarm reports a node whose observed share of selections deviated from
+// its expected share for a sustained period.
+type SkewAlarm[N any] struct {
+	// Share holds the node's observed selections over the last window.
+	Share Share[N]
+	// Windows is the number of consecutive windows the deviation lasted.
+	Windows int
+}
+
+// SkewMonitor compares how often each node is actually selected against its
+// expected share of keys, to catch hot spots such as those caused by a
+// misconfigured weight. Selections are counted with Observe and compared on
+// each call to Check, which closes a window. A node whose share deviates by
+// more than Threshold for Windows consecutive windows raises an alarm.
+//
+// Observe is safe for concurrent use. Check reads the Hash, so it must not
+// run concurrently with changes to it.
+type SkewMonitor[N any] struct {
+	// Threshold is the largest tolerated absolute Share.Deviation.
+	Threshold float64
+	// Windows is the number of consecutive windows a deviation must last
+	// to raise an alarm.
+	Windows int
+	// MinSamples is the fewest selections a window needs to be checked.
+	// Check leaves a window with fewer open, so that its selections carry
+	// over into the next.
+	MinSamples int
+	// OnSkew, if set, is called with each alarm raised. A node raises one
+	// alarm per episode: it must come back within Threshold before it
+	// alarms again.
+	OnSkew func(SkewAlarm[N])
+
+	hash *Hash[N]
+
+	mu     sync.Mutex
+	counts map[string]int
+	total  int
+	// streaks counts each node's consecutive deviating windows.
+	streaks map[string]int
+}
+
+// NewSkewMonitor returns a SkewMonitor of hash's selections with a
+// Threshold of threshold, Windows of 3 and MinSamples of 1000.
+func NewSkewMonitor[N any](hash *Hash[N], threshold float64) *SkewMonitor[N] {
+	return &SkewMonitor[N]{
+		Threshold:  threshold,
+		Windows:    3,
+		MinSamples: 1000,
+		hash:       hash,
+		counts:     make(map[string]int),
+		streaks:    make(map[string]int),
+	}
+}
+
+// Observe records that node was selected for a key.
+func (m *SkewMonitor[N]) Observe(node N) {
+	id := m.hash.nodeID(node)
+	m.mu.Lock()
+	m.counts[string(id)]++
+	m.total++
+	m.mu.Unlock()
+}
+
+// Check closes the current window and returns the share of every node
+// whose deviation currently exceeds Threshold, calling OnSkew for those
+// deviating for Windows consecutive windows. If the window has fewer than
+// MinSamples selections, Check leaves it open and returns nil.
+func (m *SkewMonitor[N]) Check() []Share[N] {
+	m.mu.Lock()
+	counts, total := m.counts, m.total
+	if total < m.MinSamples || total == 0 {
+		m.mu.Unlock()
+		return nil
+	}
+	m.counts, m.total = make(map[string]int, len(counts)), 0
+	m.mu.Unlock()
+
+	var weights float64
+	for _, ns := range m.hash.nodes {
+		weights += ns.effective
+	}
+
+	var deviating []Share[N]
+	var alarms []SkewAlarm[N]
+	streaks := make(map[string]int, len(m.streaks))
+	for _, ns := range m.hash.nodes {
+		keys := counts[string(ns.id)]
+		share := Share[N]{Node: ns.node, Keys: keys, Fraction: float64(keys) / float64(total)}
+		if weights > 0 {
+			share.Expected = ns.effective / weights
+		}
+		if math.Abs(share.Deviation()) <= m.Threshold {
+			continue
+		}
+		deviating = append(deviating, share)
+		streak := m.streaks[string(ns.id)] + 1
+		streaks[string(ns.id)] = streak
+		if streak == m.Windows {
+			alarms = append(alarms, SkewAlarm[N]{Share: share, Windows: streak})
+		}
+	}
+	m.streaks = streaks
+
+	if m.OnSkew != nil {
+		for _, alarm := range alarms {
+			m.OnSkew(alarm)
+		}
+	}
+	return deviating
+}
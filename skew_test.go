@@ -0,0 +1,48 @@
+package rendezvous
+
+import "testing"
+
+func TestSkewMonitor(t *testing.T) {
+	hash := New[hashableString]("a", "b")
+	monitor := NewSkewMonitor(hash, 0.2)
+	monitor.Windows = 2
+	monitor.MinSamples = 100
+	var alarms []SkewAlarm[hashableString]
+	monitor.OnSkew = func(alarm SkewAlarm[hashableString]) {
+		alarms = append(alarms, alarm)
+	}
+
+	window := func(a, b int) []Share[hashableString] {
+		for i := 0; i < a; i++ {
+			monitor.Observe("a")
+		}
+		for i := 0; i < b; i++ {
+			monitor.Observe("b")
+		}
+		return monitor.Check()
+	}
+
+	if deviating := window(50, 50); len(deviating) != 0 {
+		t.Errorf("got %v, expected a balanced window to pass", deviating)
+	}
+	if deviating := window(10, 10); deviating != nil {
+		t.Errorf("got %v, expected a window below MinSamples to be skipped", deviating)
+	}
+	if deviating := window(90, 20); len(deviating) != 2 || len(alarms) != 0 {
+		t.Errorf("got %v and alarms %v, expected both nodes deviating without an alarm yet", deviating, alarms)
+	}
+	window(90, 10)
+	if len(alarms) != 2 || alarms[0].Share.Node != "a" || alarms[0].Share.Deviation() < 0.7 || alarms[0].Windows != 2 {
+		t.Errorf("got alarms %+v, expected one for each node after two windows", alarms)
+	}
+	window(90, 10)
+	if len(alarms) != 2 {
+		t.Errorf("got %d alarms, expected no repeat while the skew continues", len(alarms))
+	}
+	window(50, 50)
+	window(90, 10)
+	window(90, 10)
+	if len(alarms) != 4 {
+		t.Errorf("got %d alarms, expected the alarm to re-arm after recovering", len(alarms))
+	}
+}
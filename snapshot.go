@@ -0,0 +1,201 @@
+package rendezvous
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/beam-cloud/rendezvous/cbor"
+)
+
+// snapshotVersion is the version of the format written by SaveSnapshot.
+const snapshotVersion = 1
+
+// snapshotData is the format written by SaveSnapshot and SaveSnapshotCBOR.
+type snapshotData struct {
+	Version     int                `json:"version"`
+	Epoch       uint64             `json:"epoch"`
+	Hasher      Hasher             `json:"hasher"`
+	Layout      Layout             `json:"layout"`
+	ZoneWeights map[string]float64 `json:"zoneWeights,omitempty"`
+	Canaries    map[string]float64 `json:"canaries,omitempty"`
+	Nodes       []snapshotNode     `json:"nodes"`
+}
+
+// snapshotNode is a node in a snapshot.
+type snapshotNode struct {
+	ID     string        `json:"id"`
+	Weight float64       `json:"weight"`
+	Zone   string        `json:"zone,omitempty"`
+	Value  snapshotValue `json:"value"`
+	// Assigned is true for nodes added with an identity of their own by
+	// AddWithID.
+	Assigned bool `json:"assigned,omitempty"`
+}
+
+// snapshotValue is a node value, already encoded in the snapshot's format.
+type snapshotValue []byte
+
+func (v snapshotValue) MarshalJSON() ([]byte, error) { return v, nil }
+
+func (v *snapshotValue) UnmarshalJSON(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
+
+func (v snapshotValue) MarshalCBOR() ([]byte, error) { return v, nil }
+
+func (v *snapshotValue) UnmarshalCBOR(data []byte) error {
+	*v = append((*v)[:0], data...)
+	return nil
+}
+
+// SaveSnapshot writes the Hash's topology to w as JSON: its nodes with
+// their weights and zones, its zone weights and canaries, its epoch, and
+// the Hasher and Layout it scores with. A node's state, such as a drain, is captured
+// through its weight. Node values are encoded with encoding/json, so N must
+// round-trip through it for LoadSnapshot to restore them.
+func (h *Hash[N]) SaveSnapshot(w io.Writer) error {
+	snapshot, err := h.snapshot(json.Marshal)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(snapshot)
+}
+
+// SaveSnapshotCBOR is SaveSnapshot writing CBOR, which is several times
+// more compact than JSON. Node values are encoded with the cbor package, so
+// N must round-trip through it for LoadSnapshotCBOR to restore them.
+func (h *Hash[N]) SaveSnapshotCBOR(w io.Writer) error {
+	snapshot, err := h.snapshot(cbor.Marshal)
+	if err != nil {
+		return err
+	}
+	data, err := cbor.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// snapshot returns the Hash's topology, with node values encoded by marshal.
+func (h *Hash[N]) snapshot(marshal func(any) ([]byte, error)) (snapshotData, error) {
+	snapshot := snapshotData{
+		Version:     snapshotVersion,
+		Epoch:       h.epoch,
+		Hasher:      h.hasher,
+		Layout:      h.layout,
+		ZoneWeights: h.zoneWeights,
+		Canaries:    h.canaries,
+		Nodes:       make([]snapshotNode, len(h.nodes)),
+	}
+	for i, ns := range h.nodes {
+		value, err := marshal(ns.node)
+		if err != nil {
+			return snapshot, fmt.Errorf("rendezvous: encoding node %q: %w", ns.id, err)
+		}
+		snapshot.Nodes[i] = snapshotNode{
+			ID:       string(ns.id),
+			Weight:   ns.weight,
+			Zone:     ns.zone,
+			Value:    value,
+			Assigned: !bytes.Equal(h.identity(ns.node), ns.id),
+		}
+	}
+	return snapshot, nil
+}
+
+// LoadSnapshot replaces the Hash's topology with that of a snapshot written
+// by SaveSnapshot, so a restarted process can serve the last known topology
+// until discovery catches up. The epoch advances to the snapshot's, or by
+// one if the Hash's epoch is already at or past the snapshot's, so it never
+// goes backward. It returns an error, and leaves the Hash unchanged, if the
+// snapshot is malformed, was taken with a different Hasher or Layout, or
+// holds a node whose identity no longer matches the one recorded.
+// Identities given by AddWithID are restored. The load is recorded in the
+// audit log as a change by the actor "snapshot".
+func (h *Hash[N]) LoadSnapshot(r io.Reader) error {
+	var snapshot snapshotData
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("rendezvous: decoding snapshot: %w", err)
+	}
+	return h.restore(snapshot, json.Unmarshal)
+}
+
+// LoadSnapshotCBOR is LoadSnapshot for a snapshot written by
+// SaveSnapshotCBOR.
+func (h *Hash[N]) LoadSnapshotCBOR(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var snapshot snapshotData
+	if err := cbor.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("rendezvous: decoding snapshot: %w", err)
+	}
+	return h.restore(snapshot, cbor.Unmarshal)
+}
+
+// restore replaces the Hash's topology with snapshot's, decoding node
+// values with unmarshal.
+func (h *Hash[N]) restore(snapshot snapshotData, unmarshal func([]byte, any) error) error {
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("rendezvous: unsupported snapshot version %d", snapshot.Version)
+	}
+	if snapshot.Hasher != h.hasher || snapshot.Layout != h.layout {
+		return fmt.Errorf("rendezvous: snapshot hasher %d and layout %+v don't match the Hash", snapshot.Hasher, snapshot.Layout)
+	}
+
+	nodes := make(nodeScores[N], len(snapshot.Nodes))
+	var assigned map[string][]byte
+	for i, entry := range snapshot.Nodes {
+		var node N
+		if err := unmarshal(entry.Value, &node); err != nil {
+			return fmt.Errorf("rendezvous: decoding node %q: %w", entry.ID, err)
+		}
+		id := []byte(entry.ID)
+		if natural := h.identity(node); entry.Assigned {
+			if assigned == nil {
+				assigned = make(map[string][]byte)
+			}
+			assigned[string(natural)] = id
+		} else if !bytes.Equal(natural, id) {
+			return fmt.Errorf("rendezvous: snapshot node %q has identity %q", entry.ID, natural)
+		}
+		nodes[i] = nodeScore[N]{node: node, id: id, zone: entry.Zone, weight: entry.Weight, effective: -1}
+	}
+	slices.SortStableFunc(nodes, func(a, b nodeScore[N]) int {
+		return bytes.Compare(a.id, b.id)
+	})
+	nodes.pack()
+
+	removed := h.Nodes()
+	h.nodes = nodes
+	h.zoneWeights = maps.Clone(snapshot.ZoneWeights)
+	h.canaries = maps.Clone(snapshot.Canaries)
+	h.assigned = assigned
+	h.reweigh()
+	h.weightGen++
+	clear(h.proposals)
+	if h.cache != nil {
+		h.cache.invalidate()
+	}
+	if h.standby != nil {
+		h.standby.invalidate()
+	}
+
+	// commit advances the epoch to the snapshot's, unless the Hash is
+	// already at or past it: the epoch never repeats, as Views and other
+	// helpers take an unchanged epoch to mean an unchanged topology.
+	if snapshot.Epoch > h.epoch+1 {
+		h.epoch = snapshot.Epoch - 1
+	}
+	h.commit("snapshot", h.Nodes(), removed, nil)
+	return nil
+}
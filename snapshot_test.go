@@ -0,0 +1,106 @@
+package rendezvous
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHashSnapshot(t *testing.T) {
+	hash := New(NewConfigNode("a", 1, "east"), NewConfigNode("b", 2, "west"), NewConfigNode("c", 1, "west"))
+	hash.SetWeight(NewConfigNode("c", 0, ""), 0)
+	hash.SetZoneWeight("west", 3)
+
+	var buf bytes.Buffer
+	if err := hash.SaveSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	log := NewMemoryAuditLog(0)
+	restored := NewWithOptions([]ConfigNode{NewConfigNode("stale", 1, "")}, WithAuditLog(log), WithLookupCache(16))
+	restored.Get("key")
+	if err := restored.LoadSnapshot(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	if !restored.Equal(hash) || restored.Epoch() != hash.Epoch() {
+		t.Errorf("got %s at epoch %d, expected %s at epoch %d", restored.Dump(), restored.Epoch(), hash.Dump(), hash.Epoch())
+	}
+	for _, key := range sampleKeys {
+		got, _ := restored.Get(key)
+		expected, _ := hash.Get(key)
+		if got.ID != expected.ID {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+	history := log.History()
+	if last := history[len(history)-1]; last.Actor != "snapshot" || len(last.Added) != 3 || len(last.Removed) != 1 {
+		t.Errorf("got change %+v, expected the load recorded", last)
+	}
+
+	mismatched := NewWithOptions([]ConfigNode{NewConfigNode("stale", 1, "")}, WithHasher(Hash128))
+	if err := mismatched.LoadSnapshot(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("got no error, expected one for a snapshot taken with another hasher")
+	}
+	if err := mismatched.LoadSnapshot(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("got no error, expected one for an unsupported version")
+	}
+	if nodes := mismatched.Nodes(); len(nodes) != 1 || nodes[0].ID != "stale" {
+		t.Errorf("got %v, expected the Hash unchanged after failed loads", nodes)
+	}
+}
+
+func TestHashSnapshotCBOR(t *testing.T) {
+	hash := New(NewConfigNode("a", 1, "east"), NewConfigNode("b", 2, "west"), NewConfigNode("c", 1, "west"))
+	hash.SetZoneWeight("west", 3)
+	hash.AddWithID([]byte("d-alias"), NewConfigNode("d", 1, "east"))
+
+	var compact, verbose bytes.Buffer
+	if err := hash.SaveSnapshotCBOR(&compact); err != nil {
+		t.Fatal(err)
+	}
+	hash.SaveSnapshot(&verbose)
+	if compact.Len() >= verbose.Len()/2 {
+		t.Errorf("got %d bytes of CBOR against %d of JSON, expected less than half", compact.Len(), verbose.Len())
+	}
+
+	restored := New[ConfigNode]()
+	if err := restored.LoadSnapshotCBOR(&compact); err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Equal(hash) || restored.Epoch() != hash.Epoch() {
+		t.Errorf("got %s at epoch %d, expected %s at epoch %d", restored.Dump(), restored.Epoch(), hash.Dump(), hash.Epoch())
+	}
+	if node, ok := restored.Find([]byte("b")); !ok || node.Weight() != 2 || node.Zone() != "west" {
+		t.Errorf("got %+v, expected b's value with its weight and zone", node)
+	}
+	if _, ok := restored.Find([]byte("d-alias")); !ok {
+		t.Error("got d's assigned identity lost")
+	}
+
+	if err := restored.LoadSnapshotCBOR(strings.NewReader("not cbor")); err == nil {
+		t.Error("got no error, expected one for malformed input")
+	}
+}
+
+func TestHashSnapshotEpochAdvances(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New[ConfigNode](NewConfigNode("a", 1, "")).SaveSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Hash is at the snapshot's epoch already, with other nodes.
+	hash := New(NewConfigNode("x", 1, ""), NewConfigNode("y", 1, ""), NewConfigNode("z", 1, ""))
+	view := hash.View(func(ConfigNode) bool { return true })
+	view.GetN(3, "key")
+	epoch := hash.Epoch()
+	if err := hash.LoadSnapshot(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	if hash.Epoch() <= epoch {
+		t.Errorf("got epoch %d, expected it to advance past %d", hash.Epoch(), epoch)
+	}
+	if got := view.GetN(3, "key"); len(got) != 1 || got[0].ID != "a" {
+		t.Errorf("got: %v, expected the snapshot's only node a", got)
+	}
+}
@@ -0,0 +1,89 @@
+package rendezvous
+
+// WithStandbyCache caches, for up to size recently used keys, the node
+// ranked second for the key along with the primary, its warm standby, so
+// that GetFailover and Breaker lookups fail over from a primary that is
+// down without ranking every node. Keys are evicted least recently used
+// first, and the cache is cleared whenever the Hash's epoch changes.
+func WithStandbyCache(size int) Option {
+	return func(c *config) {
+		c.standbySize = size
+	}
+}
+
+// standbyPair returns the indexes of the primary and standby nodes for
+// key, from the standby cache if the Hash has one. The standby is -1 if
+// the Hash has a single node, and both are -1 if it is empty.
+func (h *Hash[N]) standbyPair(key string) (int, int) {
+	if h.standby != nil {
+		if primary, standby, ok := h.standby.getPair(key, h.epoch); ok {
+			return primary, standby
+		}
+	}
+	if len(h.nodes) == 0 {
+		return -1, -1
+	}
+	primary, standby := h.topTwo(h.keyBytes(key))
+	if h.standby != nil {
+		h.standby.putPair(key, primary, standby)
+	}
+	return primary, standby
+}
+
+// GetStandby returns the node ranked second for key, which takes over the
+// key if its primary is removed. It returns false if the Hash has fewer
+// than two nodes.
+func (h *Hash[N]) GetStandby(key string) (N, bool) {
+	_, standby := h.standbyPair(key)
+	if standby < 0 {
+		var zero N
+		return zero, false
+	}
+	return h.nodes[standby].node, true
+}
+
+// GetFailover returns the highest scoring node for key that down doesn't
+// report as down, and false if every node is down or the Hash is empty.
+// With WithStandbyCache, a key whose primary or standby is up is served
+// from the cache; only keys whose two best nodes are both down rank every
+// node. down is called at most once per node, in rank order.
+func (h *Hash[N]) GetFailover(key string, down func(N) bool) (N, bool) {
+	return h.failover(key, func(ns *nodeScore[N]) bool { return down(ns.node) })
+}
+
+// failover is GetFailover with down reporting on nodes' scores.
+func (h *Hash[N]) failover(key string, down func(*nodeScore[N]) bool) (N, bool) {
+	var zero N
+	primary, standby := h.standbyPair(key)
+	if primary < 0 {
+		return zero, false
+	}
+	if !down(&h.nodes[primary]) {
+		return h.nodes[primary].node, true
+	}
+	if standby < 0 {
+		return zero, false
+	}
+	if !down(&h.nodes[standby]) {
+		return h.nodes[standby].node, true
+	}
+
+	h.rank(h.keyBytes(key))
+	for _, i := range h.order[2:] {
+		if !down(&h.nodes[i]) {
+			return h.nodes[i].node, true
+		}
+	}
+	return zero, false
+}
+
+// StandbyCacheStats returns the standby cache's statistics, or the zero
+// value if the Hash has no standby cache.
+func (h *Hash[N]) StandbyCacheStats() CacheStats {
+	if h.standby == nil {
+		return CacheStats{}
+	}
+	stats := h.standby.stats
+	stats.Size = len(h.standby.entries)
+	return stats
+}
@@ -0,0 +1,60 @@
+package rendezvous
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHashStandbyCache(t *testing.T) {
+	hash := NewWithOptions([]hashableString{"a", "b", "c", "d"}, WithStandbyCache(len(sampleKeys)))
+
+	check := func() {
+		t.Helper()
+		for _, key := range sampleKeys {
+			ranked := hash.GetN(3, key)
+			if got, _ := hash.GetStandby(key); got != ranked[1] {
+				t.Errorf("key=%q - got standby: %v, expected: %v", key, got, ranked[1])
+			}
+			down := map[hashableString]bool{ranked[0]: true}
+			if got, _ := hash.GetFailover(key, func(node hashableString) bool { return down[node] }); got != ranked[1] {
+				t.Errorf("key=%q - got: %v, expected the standby %v", key, got, ranked[1])
+			}
+			down[ranked[1]] = true
+			if got, _ := hash.GetFailover(key, func(node hashableString) bool { return down[node] }); got != ranked[2] {
+				t.Errorf("key=%q - got: %v, expected: %v", key, got, ranked[2])
+			}
+		}
+	}
+	check()
+	if stats := hash.StandbyCacheStats(); stats.Size != len(sampleKeys) || stats.Hits != uint64(2*len(sampleKeys)) {
+		t.Errorf("got stats %+v, expected every key cached and hit twice", stats)
+	}
+
+	// The cache is cleared when the topology changes.
+	hash.Remove("a")
+	check()
+	if stats := hash.StandbyCacheStats(); stats.Invalidations != 1 {
+		t.Errorf("got %d invalidations, expected 1", stats.Invalidations)
+	}
+
+	if _, ok := hash.GetFailover(sampleKeys[0], func(hashableString) bool { return true }); ok {
+		t.Error("got a node with every node down")
+	}
+	single := NewWithOptions([]hashableString{"a"}, WithStandbyCache(1))
+	if _, ok := single.GetStandby("key"); ok {
+		t.Error("got a standby from a Hash with a single node")
+	}
+}
+
+func TestBreakerStandbyCache(t *testing.T) {
+	hash := NewWithOptions([]hashableString{"a", "b", "c"}, WithStandbyCache(16))
+	breaker := NewBreaker(hash)
+	key := sampleKeys[0]
+	ranked := hash.GetN(2, key)
+	for range 10 {
+		breaker.Report(ranked[0], errors.New("down"))
+	}
+	if got, _ := breaker.Get(key); got != ranked[1] {
+		t.Errorf("got: %v, expected the standby %v", got, ranked[1])
+	}
+}
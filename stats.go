@@ -0,0 +1,70 @@
+package rendezvous
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LookupStats reports the number and cost of a Hash's lookups, to show when
+// topology growth pushes lookup cost past a latency budget.
+type LookupStats struct {
+	// Gets and GetNs count calls to Get and GetN.
+	Gets, GetNs uint64
+	// GetTime and GetNTime are the total time spent in Get and GetN.
+	GetTime, GetNTime time.Duration
+	// MaxGet and MaxGetN are the slowest single Get and GetN.
+	MaxGet, MaxGetN time.Duration
+	// OverBudget counts lookups that took longer than the budget passed to
+	// WithLookupStats.
+	OverBudget uint64
+}
+
+// WithLookupStats times every Get and GetN, for LookupStats to report.
+// Lookups taking longer than budget are counted as over budget; a budget of
+// zero or less disables the count. Timing adds the cost of reading the
+// clock twice to each lookup.
+func WithLookupStats(budget time.Duration) Option {
+	return func(c *config) {
+		c.stats = true
+		c.statsBudget = budget
+	}
+}
+
+// lookupStats accumulates LookupStats. Its counters are atomic so that
+// LookupStats may be called while the Hash is in use.
+type lookupStats struct {
+	budget            time.Duration
+	gets, getNs       atomic.Uint64
+	getTime, getNTime atomic.Int64
+	maxGet, maxGetN   atomic.Int64
+	overBudget        atomic.Uint64
+}
+
+// observe records a lookup that took elapsed into count, total and max.
+func (s *lookupStats) observe(count *atomic.Uint64, total, max *atomic.Int64, elapsed time.Duration) {
+	count.Add(1)
+	total.Add(int64(elapsed))
+	if int64(elapsed) > max.Load() {
+		max.Store(int64(elapsed))
+	}
+	if s.budget > 0 && elapsed > s.budget {
+		s.overBudget.Add(1)
+	}
+}
+
+// LookupStats returns the Hash's lookup statistics, or the zero value if
+// the Hash was created without WithLookupStats.
+func (h *Hash[N]) LookupStats() LookupStats {
+	if h.stats == nil {
+		return LookupStats{}
+	}
+	return LookupStats{
+		Gets:       h.stats.gets.Load(),
+		GetNs:      h.stats.getNs.Load(),
+		GetTime:    time.Duration(h.stats.getTime.Load()),
+		GetNTime:   time.Duration(h.stats.getNTime.Load()),
+		MaxGet:     time.Duration(h.stats.maxGet.Load()),
+		MaxGetN:    time.Duration(h.stats.maxGetN.Load()),
+		OverBudget: h.stats.overBudget.Load(),
+	}
+}
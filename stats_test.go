@@ -0,0 +1,29 @@
+package rendezvous
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHashLookupStats(t *testing.T) {
+	hash := NewWithOptions([]hashableString{"a", "b", "c"}, WithLookupStats(time.Nanosecond))
+	for _, key := range sampleKeys {
+		hash.Get(key)
+	}
+	hash.GetN(2, "foo")
+
+	stats := hash.LookupStats()
+	if stats.Gets != uint64(len(sampleKeys)) || stats.GetNs != 1 {
+		t.Errorf("got %d Gets and %d GetNs, expected %d and 1", stats.Gets, stats.GetNs, len(sampleKeys))
+	}
+	if stats.GetTime <= 0 || stats.MaxGet <= 0 || stats.MaxGet > stats.GetTime || stats.MaxGetN != stats.GetNTime {
+		t.Errorf("got %+v, expected consistent timings", stats)
+	}
+	if stats.OverBudget == 0 {
+		t.Errorf("got no lookups over a 1ns budget")
+	}
+
+	if stats := New[hashableString]("a").LookupStats(); stats != (LookupStats{}) {
+		t.Errorf("got %+v, expected no stats without WithLookupStats", stats)
+	}
+}
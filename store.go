@@ -0,0 +1,116 @@
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ErrStoreConflict is returned by MembershipStore.Append when another
+// writer appended first.
+var ErrStoreConflict = errors.New("rendezvous: membership store has newer entries")
+
+// MembershipStore durably records topology changes in a single order shared
+// by every replica of a control plane. Implementations may be backed by a
+// consensus log such as Raft or etcd, or by a database.
+type MembershipStore[N any] interface {
+	// Append records changes as the entry following seq, the sequence
+	// number of the last entry the caller has replayed, and returns the new
+	// entry's sequence number. If the store already holds entries after
+	// seq, Append returns ErrStoreConflict without recording changes.
+	Append(ctx context.Context, seq uint64, changes Changeset[N]) (uint64, error)
+	// Replay calls fn with each entry after seq in order, stopping at the
+	// first error fn returns. Sequence numbers start at 1.
+	Replay(ctx context.Context, seq uint64, fn func(seq uint64, changes Changeset[N]) error) error
+}
+
+// MemoryStore is a MembershipStore that keeps entries in memory, for tests
+// and single-process deployments. It is safe for concurrent use.
+type MemoryStore[N any] struct {
+	mu      sync.Mutex
+	entries []Changeset[N]
+}
+
+// Append implements MembershipStore.
+func (s *MemoryStore[N]) Append(ctx context.Context, seq uint64, changes Changeset[N]) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seq != uint64(len(s.entries)) {
+		return 0, ErrStoreConflict
+	}
+	s.entries = append(s.entries, changes)
+	return uint64(len(s.entries)), nil
+}
+
+// Replay implements MembershipStore.
+func (s *MemoryStore[N]) Replay(ctx context.Context, seq uint64, fn func(seq uint64, changes Changeset[N]) error) error {
+	s.mu.Lock()
+	entries := slices.Clone(s.entries[min(seq, uint64(len(s.entries))):])
+	s.mu.Unlock()
+	for i, changes := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(seq+uint64(i)+1, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Durable applies changes to a Hash through a MembershipStore, so that
+// every replica sharing the store applies the same changes in the same
+// order. A Durable is not safe for concurrent use.
+type Durable[N any] struct {
+	hash  *Hash[N]
+	store MembershipStore[N]
+	seq   uint64
+}
+
+// NewDurable returns a Durable applying the changes recorded in store to
+// hash. Call Sync to replay the changes already recorded.
+func NewDurable[N any](hash *Hash[N], store MembershipStore[N]) *Durable[N] {
+	return &Durable[N]{hash: hash, store: store}
+}
+
+// Seq returns the sequence number of the last entry applied to the Hash.
+func (d *Durable[N]) Seq() uint64 {
+	return d.seq
+}
+
+// Sync applies the entries recorded since the last one applied.
+func (d *Durable[N]) Sync(ctx context.Context) error {
+	return d.store.Replay(ctx, d.seq, func(seq uint64, changes Changeset[N]) error {
+		if err := d.hash.Apply(changes); err != nil {
+			return err
+		}
+		d.seq = seq
+		return nil
+	})
+}
+
+// Apply syncs, records changes in the store and then applies them to the
+// Hash. Changes that can't be applied are rejected before they are
+// recorded. If another replica appends in the meantime, Apply syncs and
+// tries again, so changes always apply on top of every change recorded
+// before them.
+func (d *Durable[N]) Apply(ctx context.Context, changes Changeset[N]) error {
+	for {
+		if err := d.Sync(ctx); err != nil {
+			return err
+		}
+		if err := d.hash.clone().Apply(changes); err != nil {
+			return err
+		}
+		seq, err := d.store.Append(ctx, d.seq, changes)
+		if errors.Is(err, ErrStoreConflict) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		d.seq = seq
+		return d.hash.Apply(changes)
+	}
+}
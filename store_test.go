@@ -0,0 +1,40 @@
+package rendezvous
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestDurable(t *testing.T) {
+	ctx := context.Background()
+	store := &MemoryStore[hashableString]{}
+	first := NewDurable(New[hashableString](), store)
+	second := NewDurable(New[hashableString](), store)
+
+	if err := first.Apply(ctx, Changeset[hashableString]{Add: []hashableString{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	// second hasn't seen the first change, so it syncs before applying.
+	if err := second.Apply(ctx, Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "a", Weight: 3}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !first.hash.Equal(second.hash) || first.Seq() != 2 || second.Seq() != 2 {
+		t.Errorf("got %v at %d and %v at %d, expected equal Hashes at 2", first.hash.Dump(), first.Seq(), second.hash.Dump(), second.Seq())
+	}
+
+	err := first.Apply(ctx, Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "missing", Weight: 1}}})
+	if err == nil {
+		t.Error("got no error, expected one for reweighting a missing node")
+	}
+	replica := NewDurable(New[hashableString](), store)
+	if err := replica.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := replica.hash.Nodes(), []hashableString{"a", "b"}; !slices.Equal(got, expected) || replica.Seq() != 2 {
+		t.Errorf("got: %v at %d, expected: %v at 2 without the rejected change", got, replica.Seq(), expected)
+	}
+}
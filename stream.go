@@ -0,0 +1,88 @@
+package rendezvous
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrStreamingLayout is returned when streaming a key under a Layout that
+// needs the whole key before hashing it: NodeFirst or LengthPrefix.
+var ErrStreamingLayout = errors.New("rendezvous: layout can't hash keys incrementally")
+
+// KeyWriter hashes a key incrementally as it is written, so that keys too
+// large to hold in memory, such as content bodies or file streams, can be
+// placed. Placement is the same as passing the whole key to the Hash's Get
+// or GetN.
+type KeyWriter[N any] struct {
+	hash   *Hash[N]
+	stream keyStream
+}
+
+// NewKeyWriter returns a KeyWriter for an empty key. It returns
+// ErrStreamingLayout if the Hash's Layout can't hash keys incrementally.
+func (h *Hash[N]) NewKeyWriter() (*KeyWriter[N], error) {
+	if h.layout.NodeFirst || h.layout.LengthPrefix {
+		return nil, ErrStreamingLayout
+	}
+	return &KeyWriter[N]{hash: h, stream: h.scorer.digest.stream()}, nil
+}
+
+// Write appends p to the key. It never returns an error.
+func (w *KeyWriter[N]) Write(p []byte) (int, error) {
+	return w.stream.Write(p)
+}
+
+// Get returns the node with the highest score for the key written so far.
+// If the Hash has no nodes, the zero value of type N is returned along
+// with false. Get can be called again after writing more of the key.
+func (w *KeyWriter[N]) Get() (N, bool) {
+	h := w.hash
+	if len(h.nodes) == 0 {
+		var zero N
+		return zero, false
+	}
+	h.scorer.beginStream(h.layout, w.stream)
+	i, _ := h.topBegun()
+	return h.nodes[i].node, true
+}
+
+// GetN returns no more than n nodes for the key written so far, ordered by
+// descending score.
+func (w *KeyWriter[N]) GetN(n int) []N {
+	h := w.hash
+	if len(h.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	h.order = h.order[:0]
+	for i := range h.nodes {
+		h.order = append(h.order, i)
+	}
+	h.scorer.beginStream(h.layout, w.stream)
+	h.scoreOrder()
+	h.sortScored()
+
+	nodes := make([]N, min(n, len(h.order)))
+	for i := range nodes {
+		nodes[i] = h.nodes[h.order[i]].node
+	}
+	return nodes
+}
+
+// GetReader reads r to the end and returns the node with the highest score
+// for its contents as the key, without holding them in memory. It returns
+// ErrNoNodes if the Hash is empty.
+func (h *Hash[N]) GetReader(r io.Reader) (N, error) {
+	var zero N
+	w, err := h.NewKeyWriter()
+	if err != nil {
+		return zero, err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return zero, err
+	}
+	node, ok := w.Get()
+	if !ok {
+		return zero, ErrNoNodes
+	}
+	return node, nil
+}
@@ -0,0 +1,45 @@
+package rendezvous
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestKeyWriter(t *testing.T) {
+	nodes := []hashableString{"a", "b", "c", "d", "e"}
+	for _, hasher := range []Hasher{CRC32C, Hash128, SHA256} {
+		hash := NewWithOptions(nodes, WithHasher(hasher), WithLayout(Layout{Separator: "/"}))
+		hash.SetWeight("b", 2)
+
+		for _, key := range sampleKeys {
+			w, err := hash.NewKeyWriter()
+			if err != nil {
+				t.Fatalf("got error %v, expected none", err)
+			}
+			half := len(key) / 2
+			w.Write([]byte(key[:half]))
+			w.Write([]byte(key[half:]))
+
+			expected, _ := hash.Get(key)
+			if got, _ := w.Get(); got != expected {
+				t.Errorf("hasher=%d key=%q - got: %v, expected: %v", hasher, key, got, expected)
+			}
+			if got, expected := w.GetN(3), hash.GetN(3, key); !slices.Equal(got, expected) {
+				t.Errorf("hasher=%d key=%q - got: %v, expected: %v", hasher, key, got, expected)
+			}
+			if got, err := hash.GetReader(iotest.OneByteReader(strings.NewReader(key))); err != nil || got != expected {
+				t.Errorf("hasher=%d key=%q - got: (%v, %v), expected: %v", hasher, key, got, err, expected)
+			}
+		}
+	}
+
+	if _, err := NewWithOptions(nodes, WithLayout(Layout{NodeFirst: true})).NewKeyWriter(); !errors.Is(err, ErrStreamingLayout) {
+		t.Errorf("got error %v, expected ErrStreamingLayout", err)
+	}
+	if _, err := New[hashableString]().GetReader(strings.NewReader("key")); !errors.Is(err, ErrNoNodes) {
+		t.Errorf("got error %v, expected ErrNoNodes", err)
+	}
+}
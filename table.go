@@ -0,0 +1,195 @@
+package rendezvous
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Table assigns a fixed number of partitions to nodes using rendezvous
+// hashing. Membership changes only recompute the partitions they can
+// affect, and report the resulting ownership changes as a list of Moves.
+//
+// A Table is not safe for concurrent use.
+type Table[N any] struct {
+	hash   *Hash[N]
+	keys   []string
+	owners []partitionOwner[N]
+}
+
+// partitionOwner holds the current owner of a partition and its score.
+type partitionOwner[N any] struct {
+	node     N
+	id       []byte
+	score    float64
+	assigned bool
+}
+
+// Move describes a partition changing owner. HasFrom is false when the
+// partition was previously unassigned, and HasTo is false when no nodes
+// remain to take it.
+type Move[N any] struct {
+	Partition int
+	From      N
+	To        N
+	HasFrom   bool
+	HasTo     bool
+}
+
+// NewTable returns a Table of the given number of partitions assigned to
+// nodes. It panics if partitions is not positive.
+func NewTable[N Hashable](partitions int, nodes ...N) *Table[N] {
+	return NewTableWithOptions(partitions, nodes)
+}
+
+// NewTableWithOptions is NewTable with the Table's Hash configured by opts,
+// such as WithProfileLabels to label rebuilds.
+func NewTableWithOptions[N Hashable](partitions int, nodes []N, opts ...Option) *Table[N] {
+	if partitions <= 0 {
+		panic("rendezvous: NewTable requires a positive partition count")
+	}
+
+	t := &Table[N]{
+		hash:   NewWithOptions[N](nil, opts...),
+		keys:   make([]string, partitions),
+		owners: make([]partitionOwner[N], partitions),
+	}
+	for p := range t.keys {
+		t.keys[p] = partitionKey(p)
+	}
+	t.Add(nodes...)
+	return t
+}
+
+// partitionKey returns the key a partition is hashed under: its index
+// encoded as a big-endian uint64, matching the virtual IDs used by ShardFor.
+func partitionKey(p int) string {
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], uint64(p))
+	return string(id[:])
+}
+
+// Partitions returns the number of partitions in the table.
+func (t *Table[N]) Partitions() int {
+	return len(t.owners)
+}
+
+// Epoch returns the number of membership changes applied to the table.
+func (t *Table[N]) Epoch() uint64 {
+	return t.hash.Epoch()
+}
+
+// PartitionFor returns the partition that key belongs to.
+func (t *Table[N]) PartitionFor(key string) int {
+	return ShardFor(key, len(t.owners))
+}
+
+// Owner returns the node that owns partition p. If the table has no nodes,
+// or p is out of range, the zero value of type N is returned along with false.
+func (t *Table[N]) Owner(p int) (N, bool) {
+	if p < 0 || p >= len(t.owners) || !t.owners[p].assigned {
+		var zero N
+		return zero, false
+	}
+	return t.owners[p].node, true
+}
+
+// Add adds nodes to the table and returns the partitions that moved to them.
+// Unless the addition changes the scores of existing nodes, as zone weighting
+// can, only a new node can take a partition from its current owner, so each
+// partition is scored against the new nodes alone.
+func (t *Table[N]) Add(nodes ...N) []Move[N] {
+	if len(nodes) == 0 {
+		return nil
+	}
+	weightGen := t.hash.weightGen
+	t.hash.Add(nodes...)
+	if t.hash.weightGen != weightGen {
+		return t.rebuild()
+	}
+
+	added := make([]*nodeScore[N], len(nodes))
+	for i, node := range nodes {
+		added[i] = &t.hash.nodes[t.hash.find(t.hash.nodeID(node))]
+	}
+
+	var moves []Move[N]
+	for p, key := range t.keys {
+		current := &t.owners[p]
+		move := Move[N]{Partition: p, From: current.node, HasFrom: current.assigned}
+		moved := false
+
+		t.hash.scorer.begin(t.hash.layout, unsafeBytes(key))
+		for _, ns := range added {
+			score := t.hash.scoreWith(&t.hash.scorer, ns)
+			if !current.assigned || score > current.score ||
+				(score == current.score && t.hash.compareTie(ns.id, current.id) < 0) {
+				*current = partitionOwner[N]{node: ns.node, id: ns.id, score: score, assigned: true}
+				moved = true
+			}
+		}
+
+		if moved {
+			move.To, move.HasTo = current.node, true
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// Remove removes node from the table and returns the partitions it owned,
+// each reassigned to its next-highest scoring node.
+func (t *Table[N]) Remove(node N) []Move[N] {
+	weightGen := t.hash.weightGen
+	t.hash.Remove(node)
+	if t.hash.weightGen != weightGen {
+		return t.rebuild()
+	}
+
+	nodeBytes := t.hash.nodeID(node)
+	var moves []Move[N]
+	for p := range t.keys {
+		if current := t.owners[p]; current.assigned && bytes.Equal(current.id, nodeBytes) {
+			if move, moved := t.assign(p); moved {
+				moves = append(moves, move)
+			}
+		}
+	}
+	return moves
+}
+
+// rebuild recomputes the owner of every partition.
+func (t *Table[N]) rebuild() []Move[N] {
+	var moves []Move[N]
+	reassign := func() {
+		for p := range t.keys {
+			if move, moved := t.assign(p); moved {
+				moves = append(moves, move)
+			}
+		}
+	}
+	if t.hash.profileCtx != nil {
+		t.hash.profiled("table.rebuild", reassign)
+	} else {
+		reassign()
+	}
+	return moves
+}
+
+// assign recomputes the owner of partition p from the full node set, and
+// returns the resulting move if the owner changed.
+func (t *Table[N]) assign(p int) (Move[N], bool) {
+	current := &t.owners[p]
+	move := Move[N]{Partition: p, From: current.node, HasFrom: current.assigned}
+
+	i, score := t.hash.top(unsafeBytes(t.keys[p]))
+	if i < 0 {
+		*current = partitionOwner[N]{}
+		return move, move.HasFrom
+	}
+
+	previous := current.id
+	owner := &t.hash.nodes[i]
+	*current = partitionOwner[N]{node: owner.node, id: owner.id, score: score, assigned: true}
+	move.To, move.HasTo = owner.node, true
+	return move, !move.HasFrom || !bytes.Equal(previous, owner.id)
+}
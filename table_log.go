@@ -0,0 +1,223 @@
+package rendezvous
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// AssignmentSnapshot records the owner of every partition of a Table at a
+// version. Owners are identified by their identity bytes, as strings, with
+// "" for unassigned partitions.
+type AssignmentSnapshot struct {
+	Version uint64
+	Owners  []string
+}
+
+// AssignmentDelta records the partitions that changed owner from one
+// version of a Table's assignments to the next.
+type AssignmentDelta struct {
+	Version uint64
+	Moves   []PartitionMove
+}
+
+// PartitionMove records a partition changing owner. From is "" for a
+// partition that was unassigned, and To is "" for one left unassigned.
+type PartitionMove struct {
+	Partition int
+	From, To  string
+}
+
+// AssignmentStore durably records the assignments of a Table as a snapshot
+// followed by an append-only log of deltas, for a DurableTable.
+type AssignmentStore interface {
+	// SaveSnapshot replaces the store's snapshot with snapshot, and
+	// discards the deltas recorded before it.
+	SaveSnapshot(ctx context.Context, snapshot AssignmentSnapshot) error
+	// AppendDelta appends delta to the log.
+	AppendDelta(ctx context.Context, delta AssignmentDelta) error
+	// Load returns the snapshot and the deltas appended since, in order. An
+	// empty store returns a snapshot with a Version of 0.
+	Load(ctx context.Context) (AssignmentSnapshot, []AssignmentDelta, error)
+}
+
+// MemoryAssignmentStore is an AssignmentStore that keeps its snapshot and
+// log in memory, for tests. It is safe for concurrent use.
+type MemoryAssignmentStore struct {
+	mu       sync.Mutex
+	snapshot AssignmentSnapshot
+	deltas   []AssignmentDelta
+}
+
+// SaveSnapshot implements AssignmentStore.
+func (s *MemoryAssignmentStore) SaveSnapshot(ctx context.Context, snapshot AssignmentSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = AssignmentSnapshot{Version: snapshot.Version, Owners: slices.Clone(snapshot.Owners)}
+	s.deltas = nil
+	return nil
+}
+
+// AppendDelta implements AssignmentStore.
+func (s *MemoryAssignmentStore) AppendDelta(ctx context.Context, delta AssignmentDelta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deltas = append(s.deltas, AssignmentDelta{Version: delta.Version, Moves: slices.Clone(delta.Moves)})
+	return nil
+}
+
+// Load implements AssignmentStore.
+func (s *MemoryAssignmentStore) Load(ctx context.Context) (AssignmentSnapshot, []AssignmentDelta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return AssignmentSnapshot{Version: s.snapshot.Version, Owners: slices.Clone(s.snapshot.Owners)}, slices.Clone(s.deltas), nil
+}
+
+// DurableTable is a Table whose assignments are recorded in an
+// AssignmentStore, each change of owners under a new version, so that
+// stateful consumers can tell exactly which assignments they last acted on,
+// across restarts. Membership changes that move no partition keep the
+// version.
+//
+// A DurableTable is not safe for concurrent use.
+type DurableTable[N any] struct {
+	// SnapshotEvery, if positive, is the number of deltas after which the
+	// log is compacted into a new snapshot.
+	SnapshotEvery int
+
+	table   *Table[N]
+	store   AssignmentStore
+	version uint64
+	// deltas is the number of deltas recorded since the last snapshot.
+	deltas int
+	// dirty records that a change failed to be recorded, so the next one
+	// must be recorded as a snapshot.
+	dirty bool
+}
+
+// OpenDurableTable returns a DurableTable of the given number of partitions
+// assigned to nodes, recorded in store. It replays the store's snapshot and
+// deltas to recover the last recorded assignments and version, and if
+// nodes now place any partition elsewhere, as when membership changed
+// while the process was down, records the difference as a new version. An
+// empty store is initialized with a snapshot at version 1.
+func OpenDurableTable[N Hashable](ctx context.Context, store AssignmentStore, partitions int, nodes ...N) (*DurableTable[N], error) {
+	snapshot, deltas, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &DurableTable[N]{table: NewTable(partitions, nodes...), store: store}
+	current := t.table.ownerIDs()
+	if snapshot.Version == 0 {
+		t.version = 1
+		if err := store.SaveSnapshot(ctx, AssignmentSnapshot{Version: t.version, Owners: current}); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	if len(snapshot.Owners) != partitions {
+		return nil, fmt.Errorf("rendezvous: assignment snapshot has %d partitions, expected %d", len(snapshot.Owners), partitions)
+	}
+	owners := slices.Clone(snapshot.Owners)
+	t.version = snapshot.Version
+	for _, delta := range deltas {
+		for _, move := range delta.Moves {
+			if move.Partition < 0 || move.Partition >= partitions {
+				return nil, fmt.Errorf("rendezvous: assignment delta %d moves partition %d of %d", delta.Version, move.Partition, partitions)
+			}
+			owners[move.Partition] = move.To
+		}
+		t.version = delta.Version
+	}
+	t.deltas = len(deltas)
+
+	var moves []PartitionMove
+	for p, owner := range current {
+		if owner != owners[p] {
+			moves = append(moves, PartitionMove{Partition: p, From: owners[p], To: owner})
+		}
+	}
+	if err := t.record(ctx, moves); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Table returns the underlying Table, for lookups. Changing its membership
+// directly bypasses the store.
+func (t *DurableTable[N]) Table() *Table[N] {
+	return t.table
+}
+
+// Version returns the version of the current assignments.
+func (t *DurableTable[N]) Version() uint64 {
+	return t.version
+}
+
+// Add adds nodes to the table, records the partitions that moved to them
+// under a new version, and returns them. If recording fails, the nodes stay
+// added, and the next change is recorded as a snapshot.
+func (t *DurableTable[N]) Add(ctx context.Context, nodes ...N) ([]Move[N], error) {
+	moves := t.table.Add(nodes...)
+	return moves, t.record(ctx, t.partitionMoves(moves))
+}
+
+// Remove removes node from the table, records the partitions it owned
+// under a new version, and returns them. If recording fails, the node stays
+// removed, and the next change is recorded as a snapshot.
+func (t *DurableTable[N]) Remove(ctx context.Context, node N) ([]Move[N], error) {
+	moves := t.table.Remove(node)
+	return moves, t.record(ctx, t.partitionMoves(moves))
+}
+
+// partitionMoves returns moves with their nodes as identities.
+func (t *DurableTable[N]) partitionMoves(moves []Move[N]) []PartitionMove {
+	recorded := make([]PartitionMove, len(moves))
+	for i, move := range moves {
+		recorded[i].Partition = move.Partition
+		if move.HasFrom {
+			recorded[i].From = string(t.table.hash.nodeID(move.From))
+		}
+		if move.HasTo {
+			recorded[i].To = string(t.table.hash.nodeID(move.To))
+		}
+	}
+	return recorded
+}
+
+// record records moves under a new version, as a delta, or as a snapshot
+// if the log is due for compaction or a change failed to be recorded.
+func (t *DurableTable[N]) record(ctx context.Context, moves []PartitionMove) error {
+	if len(moves) == 0 && !t.dirty {
+		return nil
+	}
+	t.version++
+	var err error
+	if t.dirty || (t.SnapshotEvery > 0 && t.deltas >= t.SnapshotEvery) {
+		err = t.store.SaveSnapshot(ctx, AssignmentSnapshot{Version: t.version, Owners: t.table.ownerIDs()})
+		if err == nil {
+			t.deltas = 0
+		}
+	} else {
+		err = t.store.AppendDelta(ctx, AssignmentDelta{Version: t.version, Moves: moves})
+		if err == nil {
+			t.deltas++
+		}
+	}
+	t.dirty = err != nil
+	return err
+}
+
+// ownerIDs returns the identity of the owner of each partition, or "" for
+// unassigned partitions.
+func (t *Table[N]) ownerIDs() []string {
+	ids := make([]string, len(t.owners))
+	for p, owner := range t.owners {
+		if owner.assigned {
+			ids[p] = string(owner.id)
+		}
+	}
+	return ids
+}
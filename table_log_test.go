@@ -0,0 +1,103 @@
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// failingAssignmentStore is an AssignmentStore whose writes fail while
+// failing is set.
+type failingAssignmentStore struct {
+	MemoryAssignmentStore
+	failing bool
+}
+
+func (s *failingAssignmentStore) SaveSnapshot(ctx context.Context, snapshot AssignmentSnapshot) error {
+	if s.failing {
+		return errors.New("unavailable")
+	}
+	return s.MemoryAssignmentStore.SaveSnapshot(ctx, snapshot)
+}
+
+func (s *failingAssignmentStore) AppendDelta(ctx context.Context, delta AssignmentDelta) error {
+	if s.failing {
+		return errors.New("unavailable")
+	}
+	return s.MemoryAssignmentStore.AppendDelta(ctx, delta)
+}
+
+func TestDurableTable(t *testing.T) {
+	ctx := context.Background()
+	store := &failingAssignmentStore{}
+	table, err := OpenDurableTable[hashableString](ctx, store, 64, "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot, deltas, _ := store.Load(ctx); table.Version() != 1 || snapshot.Version != 1 || len(deltas) != 0 {
+		t.Fatalf("got version %d and snapshot %+v, expected an initial snapshot at version 1", table.Version(), snapshot)
+	}
+
+	moves, err := table.Add(ctx, "c")
+	if err != nil || len(moves) == 0 || table.Version() != 2 {
+		t.Fatalf("got %d moves, version %d and error %v, expected moves at version 2", len(moves), table.Version(), err)
+	}
+	if _, err := table.Add(ctx); err != nil || table.Version() != 2 {
+		t.Errorf("got version %d, expected a change moving nothing to keep it", table.Version())
+	}
+	table.Remove(ctx, "a")
+	_, deltas, _ := store.Load(ctx)
+	if len(deltas) != 2 || deltas[0].Version != 2 || deltas[1].Version != 3 {
+		t.Fatalf("got deltas %+v, expected versions 2 and 3", deltas)
+	}
+	for _, move := range deltas[1].Moves {
+		if move.From != "a" || move.To == "a" || move.To == "" {
+			t.Errorf("got move %+v, expected a partition moved off a", move)
+		}
+	}
+
+	// Replaying recovers the version, and the current nodes place every
+	// partition as recorded.
+	reopened, err := OpenDurableTable[hashableString](ctx, store, 64, "b", "c")
+	if err != nil || reopened.Version() != 3 {
+		t.Fatalf("got version %d and error %v, expected version 3", reopened.Version(), err)
+	}
+
+	// Membership changes while down are recorded as a new version.
+	reopened, err = OpenDurableTable[hashableString](ctx, store, 64, "b", "c", "d")
+	if err != nil || reopened.Version() != 4 {
+		t.Fatalf("got version %d and error %v, expected version 4", reopened.Version(), err)
+	}
+	_, deltas, _ = store.Load(ctx)
+	for _, move := range deltas[len(deltas)-1].Moves {
+		if move.To != "d" {
+			t.Errorf("got move %+v, expected partitions moved to d", move)
+		}
+	}
+
+	// A failed write is caught up with a snapshot.
+	store.failing = true
+	if _, err := reopened.Remove(ctx, "d"); err == nil {
+		t.Fatal("got no error from a failing store")
+	}
+	store.failing = false
+	reopened.Add(ctx, "e")
+	snapshot, deltas, _ := store.Load(ctx)
+	if snapshot.Version != reopened.Version() || len(deltas) != 0 || !reflect.DeepEqual(snapshot.Owners, reopened.table.ownerIDs()) {
+		t.Errorf("got snapshot at version %d with %d deltas, expected a snapshot of version %d", snapshot.Version, len(deltas), reopened.Version())
+	}
+
+	// The log is compacted every SnapshotEvery deltas.
+	reopened.SnapshotEvery = 2
+	reopened.Add(ctx, "f")
+	reopened.Add(ctx, "g")
+	reopened.Add(ctx, "h")
+	if snapshot, deltas, _ := store.Load(ctx); snapshot.Version != reopened.Version() || len(deltas) != 0 {
+		t.Errorf("got snapshot at version %d with %d deltas, expected a compacted log", snapshot.Version, len(deltas))
+	}
+
+	if _, err := OpenDurableTable[hashableString](ctx, store, 32, "a"); err == nil {
+		t.Errorf("got no error, expected a partition count mismatch")
+	}
+}
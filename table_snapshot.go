@@ -0,0 +1,64 @@
+package rendezvous
+
+import (
+	"iter"
+	"slices"
+)
+
+// Assignment describes the placement of a single partition.
+type Assignment[N any] struct {
+	Partition int
+	Owner     N
+	// Replicas holds the partition's replica set in rank order, starting
+	// with Owner.
+	Replicas []N
+}
+
+// TableSnapshot is an immutable view of a Table at a single epoch. Changes
+// made to the Table after the snapshot is taken are not visible through it.
+type TableSnapshot[N any] struct {
+	epoch    uint64
+	replicas int
+	hash     *Hash[N]
+	keys     []string
+	owners   []partitionOwner[N]
+}
+
+// Snapshot returns a snapshot of the table's current assignments, with
+// replica sets of up to replicas nodes per partition.
+func (t *Table[N]) Snapshot(replicas int) *TableSnapshot[N] {
+	return &TableSnapshot[N]{
+		epoch:    t.hash.Epoch(),
+		replicas: replicas,
+		hash:     t.hash.clone(),
+		keys:     t.keys,
+		owners:   slices.Clone(t.owners),
+	}
+}
+
+// Epoch returns the table epoch the snapshot was taken at.
+func (s *TableSnapshot[N]) Epoch() uint64 {
+	return s.epoch
+}
+
+// All returns an iterator over the assignments of every assigned partition,
+// in partition order. Replica sets are computed as the iteration proceeds.
+//
+// A TableSnapshot's iterators are not safe for concurrent use.
+func (s *TableSnapshot[N]) All() iter.Seq[Assignment[N]] {
+	return func(yield func(Assignment[N]) bool) {
+		for p, owner := range s.owners {
+			if !owner.assigned {
+				continue
+			}
+			assignment := Assignment[N]{
+				Partition: p,
+				Owner:     owner.node,
+				Replicas:  s.hash.GetN(s.replicas, s.keys[p]),
+			}
+			if !yield(assignment) {
+				return
+			}
+		}
+	}
+}
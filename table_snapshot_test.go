@@ -0,0 +1,37 @@
+package rendezvous
+
+import "testing"
+
+func TestTableSnapshot(t *testing.T) {
+	table := NewTable(8, hashableString("a"), hashableString("b"), hashableString("c"))
+	snapshot := table.Snapshot(2)
+	epoch := snapshot.Epoch()
+
+	table.Remove("a")
+	table.Add("d", "e")
+
+	if snapshot.Epoch() != epoch || table.Epoch() == epoch {
+		t.Errorf("got epochs (snapshot=%d, table=%d), expected snapshot to stay at %d", snapshot.Epoch(), table.Epoch(), epoch)
+	}
+
+	hash := New[hashableString]("a", "b", "c")
+	count := 0
+	for assignment := range snapshot.All() {
+		count++
+		key := partitionKey(assignment.Partition)
+		if expected, _ := hash.Get(key); assignment.Owner != expected {
+			t.Errorf("partition=%d - got owner %v, expected %v", assignment.Partition, assignment.Owner, expected)
+		}
+		if len(assignment.Replicas) != 2 || assignment.Replicas[0] != assignment.Owner {
+			t.Errorf("partition=%d - got replicas %v, expected 2 replicas led by %v", assignment.Partition, assignment.Replicas, assignment.Owner)
+		}
+		for _, replica := range assignment.Replicas {
+			if replica == "d" || replica == "e" {
+				t.Errorf("partition=%d - snapshot observed node %v added after it was taken", assignment.Partition, replica)
+			}
+		}
+	}
+	if count != 8 {
+		t.Errorf("got %d assignments, expected 8", count)
+	}
+}
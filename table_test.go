@@ -0,0 +1,59 @@
+package rendezvous
+
+import "testing"
+
+// assertTableMatchesHash checks every partition owner against a freshly
+// built Hash with the same nodes.
+func assertTableMatchesHash(t *testing.T, table *Table[hashableString], nodes ...hashableString) {
+	t.Helper()
+	hash := New(nodes...)
+	for p := 0; p < table.Partitions(); p++ {
+		expected, expectedOk := hash.Get(partitionKey(p))
+		got, ok := table.Owner(p)
+		if ok != expectedOk || got != expected {
+			t.Errorf("partition=%d - got: (%v, %t), expected: (%v, %t)", p, got, ok, expected, expectedOk)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	table := NewTable[hashableString](32)
+	if _, ok := table.Owner(0); ok {
+		t.Errorf("empty table assigned partition 0")
+	}
+
+	moves := table.Add("a", "b", "c")
+	if len(moves) != 32 {
+		t.Errorf("got %d moves, expected 32", len(moves))
+	}
+	for _, move := range moves {
+		if move.HasFrom || !move.HasTo {
+			t.Errorf("partition=%d - got move %+v, expected an initial assignment", move.Partition, move)
+		}
+	}
+	assertTableMatchesHash(t, table, "a", "b", "c")
+
+	for _, move := range table.Add("d") {
+		if !move.HasFrom || move.To != "d" {
+			t.Errorf("partition=%d - got move %+v, expected a move to d", move.Partition, move)
+		}
+	}
+	assertTableMatchesHash(t, table, "a", "b", "c", "d")
+
+	for _, move := range table.Remove("b") {
+		if move.From != "b" || !move.HasTo || move.To == "b" {
+			t.Errorf("partition=%d - got move %+v, expected a move away from b", move.Partition, move)
+		}
+	}
+	assertTableMatchesHash(t, table, "a", "c", "d")
+
+	table.Remove("a")
+	table.Remove("c")
+	moves = table.Remove("d")
+	for _, move := range moves {
+		if move.HasTo {
+			t.Errorf("partition=%d - got move %+v, expected unassignment", move.Partition, move)
+		}
+	}
+	assertTableMatchesHash(t, table)
+}
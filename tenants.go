@@ -0,0 +1,54 @@
+package rendezvous
+
+// Tenants restricts tenants to subsets of a Hash's nodes, so that noisy
+// tenants can be isolated on dedicated hardware while every tenant shares
+// one membership. Each tenant's subset is a View of the Hash, so it follows
+// membership changes, and places keys by the nodes' scores in the Hash;
+// see View for how that differs from a Hash holding only the subset.
+//
+// Tenants is not safe for concurrent use, nor for use concurrently with the
+// Hash.
+type Tenants[N any] struct {
+	hash  *Hash[N]
+	views map[string]*View[N]
+	// all is the View of tenants without a subset of their own.
+	all *View[N]
+}
+
+// NewTenants returns Tenants sharing hash's nodes. Tenants without a
+// configured subset may use every node.
+func NewTenants[N any](hash *Hash[N]) *Tenants[N] {
+	return &Tenants[N]{
+		hash:  hash,
+		views: make(map[string]*View[N]),
+		all:   hash.View(func(N) bool { return true }),
+	}
+}
+
+// Restrict restricts tenant to the nodes for which filter returns true.
+func (t *Tenants[N]) Restrict(tenant string, filter func(N) bool) {
+	t.views[tenant] = t.hash.View(filter)
+}
+
+// RestrictTo restricts tenant to the given nodes, matched by identity.
+// Nodes added to the Hash later with a listed identity join the subset.
+func (t *Tenants[N]) RestrictTo(tenant string, nodes ...N) {
+	allowed := t.hash.identitySet(nodes)
+	t.Restrict(tenant, func(node N) bool {
+		_, ok := allowed[string(t.hash.nodeID(node))]
+		return ok
+	})
+}
+
+// Unrestrict lets tenant use every node again.
+func (t *Tenants[N]) Unrestrict(tenant string) {
+	delete(t.views, tenant)
+}
+
+// ForTenant returns the View of the nodes tenant may use.
+func (t *Tenants[N]) ForTenant(tenant string) *View[N] {
+	if view, ok := t.views[tenant]; ok {
+		return view
+	}
+	return t.all
+}
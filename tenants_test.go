@@ -0,0 +1,44 @@
+package rendezvous
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTenants(t *testing.T) {
+	hash := New[hashableString]("shared-a", "shared-b", "dedicated-c", "dedicated-d")
+	tenants := NewTenants(hash)
+	tenants.Restrict("noisy", func(node hashableString) bool {
+		return strings.HasPrefix(string(node), "dedicated-")
+	})
+	tenants.RestrictTo("small", "shared-a", "shared-b")
+
+	for tenant, expected := range map[string][]hashableString{
+		"noisy": {"dedicated-c", "dedicated-d"},
+		"small": {"shared-a", "shared-b"},
+		"other": {"dedicated-c", "dedicated-d", "shared-a", "shared-b"},
+	} {
+		view := tenants.ForTenant(tenant)
+		if got := view.Nodes(); !slices.Equal(got, expected) {
+			t.Errorf("tenant=%q - got: %v, expected: %v", tenant, got, expected)
+		}
+		subset := New(expected...)
+		for _, key := range sampleKeys {
+			got, _ := view.Get(key)
+			node, _ := subset.Get(key)
+			if got != node {
+				t.Errorf("tenant=%q key=%q - got: %v, expected: %v", tenant, key, got, node)
+			}
+		}
+	}
+
+	hash.Add("dedicated-e")
+	if got := tenants.ForTenant("noisy").Nodes(); len(got) != 3 {
+		t.Errorf("got %v, expected the new dedicated node in the noisy tenant's subset", got)
+	}
+	tenants.Unrestrict("noisy")
+	if got := tenants.ForTenant("noisy").Nodes(); len(got) != 5 {
+		t.Errorf("got %v, expected every node after Unrestrict", got)
+	}
+}
@@ -0,0 +1,96 @@
+package rendezvous
+
+import "slices"
+
+// Tiers groups a Hash's nodes into ordered tiers, such as a local SSD tier
+// ahead of a remote tier, so that lookups exhaust the ranking of the first
+// tier before overflowing to the next. Within a tier, nodes are ranked as
+// the Hash ranks them. As with the Hash, nodes with a weight of zero or
+// less are only selected after every node with a positive weight, whatever
+// their tier.
+//
+// Like a View, Tiers share the Hash's storage and follow its membership
+// changes, re-evaluating tiers only when the Hash's epoch changes. Tiers
+// are not safe for concurrent use, nor for use concurrently with the Hash.
+type Tiers[N any] struct {
+	hash   *Hash[N]
+	tier   func(N) int
+	synced bool
+	epoch  uint64
+	// tiers holds the tier of each node of the Hash, by index.
+	tiers []int
+}
+
+// Tiers returns Tiers of the nodes in h, with tier reporting the tier of a
+// node. Lower tiers come first.
+func (h *Hash[N]) Tiers(tier func(N) int) *Tiers[N] {
+	return &Tiers[N]{hash: h, tier: tier}
+}
+
+// sync recomputes the tier of every node if the Hash has changed since
+// they were last computed.
+func (t *Tiers[N]) sync() {
+	if t.synced && t.epoch == t.hash.epoch {
+		return
+	}
+	t.tiers = t.tiers[:0]
+	for _, ns := range t.hash.nodes {
+		t.tiers = append(t.tiers, t.tier(ns.node))
+	}
+	t.synced, t.epoch = true, t.hash.epoch
+}
+
+// compare orders the nodes at indexes a and b by whether they are drained
+// and then by tier.
+func (t *Tiers[N]) compare(a, b int) int {
+	if drainedA, drainedB := t.hash.nodes[a].effective <= 0, t.hash.nodes[b].effective <= 0; drainedA != drainedB {
+		if drainedA {
+			return 1
+		}
+		return -1
+	}
+	return t.tiers[a] - t.tiers[b]
+}
+
+// Get returns the highest scoring node for key in the first tier with a
+// node. If the Hash has no nodes, the zero value of type N is returned
+// along with false.
+func (t *Tiers[N]) Get(key string) (N, bool) {
+	t.sync()
+	h := t.hash
+	if len(h.nodes) == 0 {
+		var zero N
+		return zero, false
+	}
+
+	h.scorer.begin(h.layout, h.keyBytes(key))
+	best := 0
+	bestScore := h.scoreWith(&h.scorer, &h.nodes[0])
+	for i := 1; i < len(h.nodes); i++ {
+		score := h.scoreWith(&h.scorer, &h.nodes[i])
+		c := t.compare(i, best)
+		if c < 0 || (c == 0 && (score > bestScore || (score == bestScore && h.compareTie(h.nodes[i].id, h.nodes[best].id) < 0))) {
+			best, bestScore = i, score
+		}
+	}
+	return h.nodes[best].node, true
+}
+
+// GetN returns no more than n nodes for key: the nodes of the first tier
+// in descending score order, followed by those of the next tier, and so
+// on.
+func (t *Tiers[N]) GetN(n int, key string) []N {
+	t.sync()
+	h := t.hash
+	if len(h.nodes) == 0 || n <= 0 {
+		return nil
+	}
+	h.rank(h.keyBytes(key))
+	slices.SortStableFunc(h.order, t.compare)
+
+	nodes := make([]N, min(n, len(h.order)))
+	for i := range nodes {
+		nodes[i] = h.nodes[h.order[i]].node
+	}
+	return nodes
+}
@@ -0,0 +1,55 @@
+package rendezvous
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTiers(t *testing.T) {
+	hash := New[hashableString]("ssd-1", "ssd-2", "ssd-3", "remote-1", "remote-2", "remote-3")
+	tiers := hash.Tiers(func(node hashableString) int {
+		if strings.HasPrefix(string(node), "ssd-") {
+			return 0
+		}
+		return 1
+	})
+	ssd := hash.View(func(node hashableString) bool { return strings.HasPrefix(string(node), "ssd-") })
+	remote := hash.View(func(node hashableString) bool { return strings.HasPrefix(string(node), "remote-") })
+
+	check := func() {
+		t.Helper()
+		for _, key := range sampleKeys {
+			expected := append(ssd.GetN(6, key), remote.GetN(6, key)...)
+			if got := tiers.GetN(6, key); !reflect.DeepEqual(got, expected) {
+				t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+			}
+			if got, _ := tiers.Get(key); got != expected[0] {
+				t.Errorf("key=%q - got: %v, expected: %v", key, got, expected[0])
+			}
+			if got := tiers.GetN(2, key); !reflect.DeepEqual(got, expected[:2]) {
+				t.Errorf("key=%q - got: %v, expected: %v", key, got, expected[:2])
+			}
+		}
+	}
+	check()
+
+	// Keys overflow to the next tier once a tier is drained or empty.
+	hash.SetWeight("ssd-1", 0)
+	hash.SetWeight("ssd-2", 0)
+	hash.SetWeight("ssd-3", 0)
+	for _, key := range sampleKeys {
+		got := tiers.GetN(6, key)
+		if !reflect.DeepEqual(got[:3], remote.GetN(3, key)) {
+			t.Errorf("key=%q - got: %v, expected the remote tier first", key, got)
+		}
+	}
+	for _, node := range []hashableString{"ssd-1", "ssd-2", "ssd-3"} {
+		hash.Remove(node)
+	}
+	check()
+
+	if _, ok := New[hashableString]().Tiers(func(hashableString) int { return 0 }).Get("key"); ok {
+		t.Errorf("got a node from an empty Hash")
+	}
+}
@@ -0,0 +1,190 @@
+package rendezvous
+
+import (
+	"bytes"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Equal reports whether h and other hold the same topology: the same node
+// identities with the same weights and zones, and the same zone weights and
+// canaries, scored by the same Hasher, Layout and weighting method. Epochs
+// and audit logs are not compared, so two Hashes that reached the same
+// topology through different histories are equal.
+//
+// Functions can't be compared, so Equal only checks that both Hashes have a
+// ScoreFunc or neither does, and that they have as many key normalizers.
+// Hashes whose ScoreFuncs or normalizers differ may compare equal yet place
+// keys differently.
+func (h *Hash[N]) Equal(other *Hash[N]) bool {
+	if h == other {
+		return true
+	}
+	if h.hasher != other.hasher || h.layout != other.layout || h.logScores != other.logScores ||
+		(h.scoreFunc == nil) != (other.scoreFunc == nil) || len(h.normalizers) != len(other.normalizers) {
+		return false
+	}
+	if len(h.nodes) != len(other.nodes) || !maps.Equal(h.zoneWeights, other.zoneWeights) || !maps.Equal(h.canaries, other.canaries) {
+		return false
+	}
+	for i := range h.nodes {
+		a, b := &h.nodes[i], &other.nodes[i]
+		if !bytes.Equal(a.id, b.id) || a.weight != b.weight || a.zone != b.zone {
+			return false
+		}
+	}
+	return true
+}
+
+// ConflictPolicy decides which side wins when Merge finds a node, or a zone
+// weight, present in both Hashes with different settings.
+type ConflictPolicy int
+
+const (
+	// KeepOurs keeps the receiving Hash's node and settings.
+	KeepOurs ConflictPolicy = iota
+	// KeepTheirs takes the other Hash's node and settings.
+	KeepTheirs
+	// KeepHigherWeight keeps whichever side has the higher weight, and the
+	// receiving Hash's side on a tie.
+	KeepHigherWeight
+	// KeepLowerWeight keeps whichever side has the lower weight, and the
+	// receiving Hash's side on a tie.
+	KeepLowerWeight
+	// KeepNewer keeps whichever side's node has the higher version, as
+	// reported by nodes implementing Versioned, so that Hashes fed by
+	// discovery sources that disagree converge on the latest state whichever
+	// order they merge in. Between nodes of the same version it keeps the
+	// higher weight, then the lexically greater zone. Zone weights carry no
+	// version, so conflicting zone weights keep the higher weight.
+	KeepNewer
+)
+
+// Versioned may be implemented by a node type to carry a version, such as a
+// revision or a timestamp from the discovery source that reported the node,
+// for KeepNewer to resolve conflicts by.
+type Versioned interface {
+	Version() uint64
+}
+
+// resolve reports whether policy picks theirs over ours, given each side's
+// weight.
+func (policy ConflictPolicy) resolve(ours, theirs float64) bool {
+	switch policy {
+	case KeepTheirs:
+		return true
+	case KeepHigherWeight, KeepNewer:
+		return theirs > ours
+	case KeepLowerWeight:
+		return theirs < ours
+	default:
+		return false
+	}
+}
+
+// resolveNode reports whether policy picks their node over ours.
+func resolveNode[N any](policy ConflictPolicy, ours, theirs *nodeScore[N]) bool {
+	if policy != KeepNewer {
+		return policy.resolve(ours.weight, theirs.weight)
+	}
+	if ours, theirs := nodeVersion(ours.node), nodeVersion(theirs.node); ours != theirs {
+		return theirs > ours
+	}
+	if ours.weight != theirs.weight {
+		return theirs.weight > ours.weight
+	}
+	return theirs.zone > ours.zone
+}
+
+// nodeVersion returns the version of node, or 0 if it has none.
+func nodeVersion[N any](node N) uint64 {
+	if versioned, ok := any(node).(Versioned); ok {
+		return versioned.Version()
+	}
+	return 0
+}
+
+// Merge adds every node of other to h, matching nodes by identity so that a
+// node present in both appears once. Where both sides hold the same node or
+// zone weight with different settings, policy picks the winner, including
+// which node value is kept. The merge is applied as a single change, in
+// which a node whose value changes is recorded as replaced.
+func (h *Hash[N]) Merge(other *Hash[N], policy ConflictPolicy) {
+	h.merge(other, func(ours, theirs *nodeScore[N]) bool {
+		return resolveNode(policy, ours, theirs)
+	}, policy.resolve)
+}
+
+// MergeFunc is Merge with a custom conflict policy: where both sides hold a
+// node with the same identity, theirsWins reports whether other's node and
+// its weight replace h's. For Hashes to converge whichever order they merge
+// in, theirsWins must order nodes consistently, never preferring both a
+// over b and b over a. Conflicting zone weights keep the higher weight.
+func (h *Hash[N]) MergeFunc(other *Hash[N], theirsWins func(ours, theirs N) bool) {
+	h.merge(other, func(ours, theirs *nodeScore[N]) bool {
+		return theirsWins(ours.node, theirs.node)
+	}, KeepHigherWeight.resolve)
+}
+
+// merge is Merge, with nodeWins and zoneWins reporting whether their node or
+// zone weight wins a conflict.
+func (h *Hash[N]) merge(other *Hash[N], nodeWins func(ours, theirs *nodeScore[N]) bool, zoneWins func(ours, theirs float64) bool) {
+	var added, removed, reweighted []N
+	inserted := false
+	for _, theirs := range other.nodes {
+		i := h.find(theirs.id)
+		if i < 0 {
+			insert, _ := slices.BinarySearchFunc(h.nodes, theirs.id, func(ns nodeScore[N], id []byte) int {
+				return bytes.Compare(ns.id, id)
+			})
+			h.nodes = slices.Insert(h.nodes, insert, nodeScore[N]{
+				node:      theirs.node,
+				id:        theirs.id,
+				zone:      theirs.zone,
+				weight:    theirs.weight,
+				effective: -1,
+			})
+			h.adoptID(other, theirs.node, theirs.id)
+			added = append(added, theirs.node)
+			inserted = true
+			continue
+		}
+
+		ours := &h.nodes[i]
+		if nodeWins(ours, &theirs) {
+			// A new value replaces the node, as Apply records replacements.
+			if !reflect.DeepEqual(ours.node, theirs.node) {
+				removed = append(removed, ours.node)
+				added = append(added, theirs.node)
+			}
+			if ours.weight != theirs.weight || ours.zone != theirs.zone {
+				reweighted = append(reweighted, theirs.node)
+			}
+			ours.node, ours.weight, ours.zone = theirs.node, theirs.weight, theirs.zone
+			h.adoptID(other, theirs.node, theirs.id)
+		}
+	}
+
+	zonesChanged := false
+	for zone, theirs := range other.zoneWeights {
+		ours, ok := h.zoneWeights[zone]
+		if ok && (ours == theirs || !zoneWins(ours, theirs)) {
+			continue
+		}
+		if h.zoneWeights == nil {
+			h.zoneWeights = make(map[string]float64)
+		}
+		h.zoneWeights[zone] = theirs
+		zonesChanged = true
+	}
+
+	if len(added) == 0 && len(reweighted) == 0 && !zonesChanged {
+		return
+	}
+	if inserted {
+		h.nodes.pack()
+	}
+	h.reweigh()
+	h.commit("", added, removed, reweighted)
+}
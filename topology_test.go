@@ -0,0 +1,187 @@
+package rendezvous
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHashEqual(t *testing.T) {
+	a := New[hashableString]("a", "b", "c")
+	b := New[hashableString]("c", "b")
+	if a.Equal(b) {
+		t.Errorf("Hashes with different membership compared equal")
+	}
+
+	b.Add("a")
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Errorf("got unequal, expected the same membership to compare equal across histories")
+	}
+
+	b.SetWeight("a", 2)
+	if a.Equal(b) {
+		t.Errorf("Hashes with different weights compared equal")
+	}
+
+	if New[hashableString]("a").Equal(NewWithOptions([]hashableString{"a"}, WithHasher(Hash128))) {
+		t.Errorf("Hashes with different hashers compared equal")
+	}
+	if New[hashableString]("a").Equal(NewWithOptions([]hashableString{"a"}, WithKeyNormalizer(strings.ToLower))) {
+		t.Errorf("Hashes with and without key normalizers compared equal")
+	}
+	if New[hashableString]("a").Equal(NewWithOptions([]hashableString{"a"}, WithScoreFunc(func(key, node uint64, weight float64) float64 { return 0 }))) {
+		t.Errorf("Hashes with and without a ScoreFunc compared equal")
+	}
+	logScores := New[hashableString]("a")
+	logScores.logScores = true
+	if New[hashableString]("a").Equal(logScores) {
+		t.Errorf("Hashes weighting scores differently compared equal")
+	}
+
+	a.SetWeight("a", 2)
+	a.SetZoneWeight("", 1)
+	if a.Equal(b) {
+		t.Errorf("Hashes with different zone weights compared equal")
+	}
+}
+
+func TestHashMerge(t *testing.T) {
+	newHashes := func() (*Hash[zonedNode], *Hash[zonedNode]) {
+		ours := New(zonedNode{"a", "us", 1}, zonedNode{"b", "us", 3})
+		theirs := New(zonedNode{"b", "eu", 2}, zonedNode{"c", "eu", 1}, zonedNode{"c", "eu", 1})
+		theirs.SetZoneWeight("eu", 2)
+		return ours, theirs
+	}
+
+	testcases := []struct {
+		policy         ConflictPolicy
+		expectedWeight float64
+		expectedZone   string
+	}{
+		{KeepOurs, 3, "us"},
+		{KeepTheirs, 2, "eu"},
+		{KeepHigherWeight, 3, "us"},
+		{KeepLowerWeight, 2, "eu"},
+	}
+
+	for _, testcase := range testcases {
+		ours, theirs := newHashes()
+		epoch := ours.Epoch()
+		ours.Merge(theirs, testcase.policy)
+
+		nodes := ours.Nodes()
+		if len(nodes) != 3 || nodes[0].id != "a" || nodes[1].id != "b" || nodes[2].id != "c" {
+			t.Fatalf("policy=%d - got nodes %v, expected a, b, c once each", testcase.policy, nodes)
+		}
+		if weight, _ := ours.Weight(zonedNode{id: "b"}); weight != testcase.expectedWeight || nodes[1].zone != testcase.expectedZone {
+			t.Errorf("policy=%d - got b with weight %v in %q, expected %v in %q", testcase.policy, weight, nodes[1].zone, testcase.expectedWeight, testcase.expectedZone)
+		}
+		if ours.Epoch() != epoch+1 {
+			t.Errorf("policy=%d - got epoch %d, expected a single change to %d", testcase.policy, ours.Epoch(), epoch+1)
+		}
+		if ours.zoneWeights["eu"] != 2 {
+			t.Errorf("policy=%d - got zone weights %v, expected eu=2", testcase.policy, ours.zoneWeights)
+		}
+	}
+
+	ours, theirs := newHashes()
+	ours.Merge(theirs, KeepOurs)
+	epoch := ours.Epoch()
+	ours.Merge(theirs, KeepOurs)
+	if ours.Epoch() != epoch {
+		t.Errorf("merging an already merged topology advanced the epoch")
+	}
+
+	// A node whose value alone changes is replaced, as Apply would.
+	log := NewMemoryAuditLog(0)
+	moved := NewWithOptions([]versionedNode{{"a", "10.0.0.1", 1}}, WithAuditLog(log))
+	epoch = moved.Epoch()
+	moved.Merge(New(versionedNode{"a", "10.0.1.1", 1}), KeepTheirs)
+	history := log.History()
+	if last := history[len(history)-1]; moved.Epoch() != epoch+1 || !slices.Equal(last.Added, []string{"a"}) || !slices.Equal(last.Removed, []string{"a"}) {
+		t.Errorf("got epoch %d and change %+v, expected a replaced at epoch %d", moved.Epoch(), last, epoch+1)
+	}
+}
+
+type versionedNode struct {
+	id      string
+	addr    string
+	version uint64
+}
+
+func (n versionedNode) Bytes() []byte {
+	return []byte(n.id)
+}
+
+func (n versionedNode) Version() uint64 {
+	return n.version
+}
+
+func TestHashMergeKeepNewer(t *testing.T) {
+	// Two discovery sources disagree about where b lives; each Hash merges
+	// the other's view, in either order, and both must settle on the newer.
+	east := New(versionedNode{"a", "10.0.0.1", 1}, versionedNode{"b", "10.0.0.2", 5})
+	west := New(versionedNode{"b", "10.0.1.2", 7}, versionedNode{"c", "10.0.1.3", 1})
+	eastCopy, westCopy := east.clone(), west.clone()
+
+	east.Merge(westCopy, KeepNewer)
+	west.Merge(eastCopy, KeepNewer)
+	if !east.Equal(west) {
+		t.Errorf("got %v and %v, expected merged topologies to converge", east.Nodes(), west.Nodes())
+	}
+	for _, hash := range []*Hash[versionedNode]{east, west} {
+		if node, _ := hash.Find([]byte("b")); node.addr != "10.0.1.2" {
+			t.Errorf("got b at %q, expected the newer address %q", node.addr, "10.0.1.2")
+		}
+	}
+
+	// Merging the same views again is a no-op, rather than flapping.
+	epoch := east.Epoch()
+	east.Merge(westCopy, KeepNewer)
+	east.Merge(eastCopy, KeepNewer)
+	if node, _ := east.Find([]byte("b")); node.addr != "10.0.1.2" || east.Epoch() != epoch {
+		t.Errorf("got b at %q at epoch %d, expected %q at epoch %d", node.addr, east.Epoch(), "10.0.1.2", epoch)
+	}
+}
+
+func TestHashMergeNewerVersionRecorded(t *testing.T) {
+	// A newer version with the same weight and zone is still a change, which
+	// observers following the epoch or the audit log must see.
+	for name, merge := range map[string]func(ours, theirs *Hash[versionedNode]){
+		"KeepNewer": func(ours, theirs *Hash[versionedNode]) { ours.Merge(theirs, KeepNewer) },
+		"MergeFunc": func(ours, theirs *Hash[versionedNode]) {
+			ours.MergeFunc(theirs, func(ours, theirs versionedNode) bool { return theirs.version > ours.version })
+		},
+	} {
+		log := NewMemoryAuditLog(0)
+		ours := NewWithOptions([]versionedNode{{"b", "10.0.0.2", 5}}, WithAuditLog(log))
+		epoch, changes := ours.Epoch(), len(log.History())
+		merge(ours, New(versionedNode{"b", "10.0.1.2", 7}))
+
+		if node, _ := ours.Find([]byte("b")); node.version != 7 || ours.Epoch() != epoch+1 {
+			t.Errorf("%s - got b at version %d at epoch %d, expected version 7 at epoch %d", name, node.version, ours.Epoch(), epoch+1)
+		}
+		if history := log.History(); len(history) != changes+1 || !slices.Equal(history[len(history)-1].Added, []string{"b"}) {
+			t.Errorf("%s - got audit log %+v, expected b's replacement recorded", name, history)
+		}
+	}
+}
+
+func TestHashMergeFunc(t *testing.T) {
+	ours := New(versionedNode{"a", "10.0.0.1", 2})
+	ours.SetWeight(versionedNode{id: "a"}, 3)
+	theirs := New(versionedNode{"a", "10.0.1.1", 1}, versionedNode{"b", "10.0.1.2", 1})
+
+	ours.MergeFunc(theirs, func(ours, theirs versionedNode) bool {
+		return theirs.addr > ours.addr
+	})
+	if node, _ := ours.Find([]byte("a")); node.addr != "10.0.1.1" {
+		t.Errorf("got a at %q, expected %q", node.addr, "10.0.1.1")
+	}
+	if weight, _ := ours.Weight(versionedNode{id: "a"}); weight != 1 {
+		t.Errorf("got weight %v, expected the winning side's weight 1", weight)
+	}
+	if _, ok := ours.Find([]byte("b")); !ok {
+		t.Error("got b missing after the merge")
+	}
+}
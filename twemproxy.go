@@ -0,0 +1,306 @@
+package rendezvous
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TwemproxyPool is a server pool of a twemproxy (nutcracker) configuration.
+type TwemproxyPool struct {
+	Name string
+	// Hash is the name of the key hash function, such as "fnv1a_64".
+	Hash string
+	// HashTag, if set, is two characters delimiting the part of a key to
+	// hash, such as "{}".
+	HashTag string
+	// Distribution is "ketama", "modula" or "random".
+	Distribution string
+	Servers      []TwemproxyServer
+}
+
+// TwemproxyServer is a server of a twemproxy pool.
+type TwemproxyServer struct {
+	// Addr is the server's "host:port", or the path of its Unix socket.
+	Addr   string
+	Weight int
+	// Name is the name twemproxy hashes the server by: the name given in
+	// the configuration, or else its address, without the port if it is
+	// memcached's default of 11211.
+	Name string
+}
+
+// ParseTwemproxy parses the pools of a twemproxy YAML configuration. It
+// reads the subset of YAML such configurations use: a mapping of pool
+// names to mappings of settings, with servers as a list of
+// "host:port:weight [name]" entries. Settings other than hash, hash_tag,
+// distribution and servers are ignored. Pools default to the fnv1a_64
+// hash and ketama distribution, as in twemproxy.
+func ParseTwemproxy(data []byte) ([]TwemproxyPool, error) {
+	var pools []TwemproxyPool
+	var pool *TwemproxyPool
+	inServers := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = text[:i]
+		}
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed[0] == '#' || trimmed == "---" {
+			continue
+		}
+
+		if text[0] != ' ' && text[0] != '\t' {
+			name, ok := strings.CutSuffix(trimmed, ":")
+			if !ok {
+				return nil, fmt.Errorf("rendezvous: twemproxy config line %d: expected a pool name", line)
+			}
+			pools = append(pools, TwemproxyPool{Name: name, Hash: "fnv1a_64", Distribution: "ketama"})
+			pool, inServers = &pools[len(pools)-1], false
+			continue
+		}
+		if pool == nil {
+			return nil, fmt.Errorf("rendezvous: twemproxy config line %d: setting outside a pool", line)
+		}
+
+		if entry, ok := strings.CutPrefix(trimmed, "-"); ok {
+			if !inServers {
+				return nil, fmt.Errorf("rendezvous: twemproxy config line %d: list entry outside servers", line)
+			}
+			server, err := parseTwemproxyServer(unquoteYAML(strings.TrimSpace(entry)))
+			if err != nil {
+				return nil, fmt.Errorf("rendezvous: twemproxy config line %d: %w", line, err)
+			}
+			pool.Servers = append(pool.Servers, server)
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("rendezvous: twemproxy config line %d: expected a setting", line)
+		}
+		value = unquoteYAML(strings.TrimSpace(value))
+		inServers = false
+		switch strings.TrimSpace(key) {
+		case "hash":
+			pool.Hash = value
+		case "hash_tag":
+			pool.HashTag = value
+		case "distribution":
+			pool.Distribution = value
+		case "servers":
+			inServers = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("rendezvous: twemproxy config: %w", err)
+	}
+	return pools, nil
+}
+
+// unquoteYAML removes the quotes around a quoted YAML scalar.
+func unquoteYAML(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// parseTwemproxyServer parses a "host:port:weight [name]" or
+// "/path:weight [name]" server entry.
+func parseTwemproxyServer(entry string) (TwemproxyServer, error) {
+	spec, name, _ := strings.Cut(entry, " ")
+	i := strings.LastIndexByte(spec, ':')
+	if i < 0 {
+		return TwemproxyServer{}, fmt.Errorf("server %q has no weight", entry)
+	}
+	weight, err := strconv.Atoi(spec[i+1:])
+	if err != nil || weight <= 0 {
+		return TwemproxyServer{}, fmt.Errorf("server %q has invalid weight %q", entry, spec[i+1:])
+	}
+	server := TwemproxyServer{Addr: spec[:i], Weight: weight, Name: strings.TrimSpace(name)}
+	if server.Name == "" {
+		server.Name = server.Addr
+		if host, port, ok := strings.Cut(server.Addr, ":"); ok && port == "11211" && !strings.HasPrefix(server.Addr, "/") {
+			server.Name = host
+		}
+	}
+	return server, nil
+}
+
+// Nodes returns the pool's servers as ConfigNodes identified by server
+// name, with their weights and addresses, for building a Hash over a
+// twemproxy fleet.
+func (p TwemproxyPool) Nodes() []ConfigNode {
+	nodes := make([]ConfigNode, len(p.Servers))
+	for i, server := range p.Servers {
+		nodes[i] = NewConfigNode(server.Name, float64(server.Weight), "")
+		nodes[i].Address = server.Addr
+	}
+	return nodes
+}
+
+// twemproxyHashes are the key hash functions of twemproxy that Twemproxy
+// reproduces. twemproxy reads keys as C chars, which are signed on the
+// platforms it runs on, so the FNV and one-at-a-time hashes sign-extend
+// bytes above 0x7f.
+var twemproxyHashes = map[string]func([]byte) uint32{
+	"md5": func(key []byte) uint32 {
+		digest := md5.Sum(key)
+		return binary.LittleEndian.Uint32(digest[:])
+	},
+	"crc32": func(key []byte) uint32 {
+		return crc32.ChecksumIEEE(key) >> 16 & 0x7fff
+	},
+	"crc32a": crc32.ChecksumIEEE,
+	"fnv1_64": func(key []byte) uint32 {
+		hash := uint64(0xcbf29ce484222325)
+		for _, b := range key {
+			hash *= 0x100000001b3
+			hash ^= uint64(int8(b))
+		}
+		return uint32(hash)
+	},
+	"fnv1a_64": func(key []byte) uint32 {
+		// twemproxy computes fnv1a_64 in 32 bits, with the 64-bit offset
+		// basis and prime truncated.
+		hash := uint32(0x84222325)
+		for _, b := range key {
+			hash ^= uint32(int8(b))
+			hash *= 0x1b3
+		}
+		return hash
+	},
+	"fnv1_32": func(key []byte) uint32 {
+		hash := uint32(2166136261)
+		for _, b := range key {
+			hash *= 16777619
+			hash ^= uint32(int8(b))
+		}
+		return hash
+	},
+	"fnv1a_32": func(key []byte) uint32 {
+		hash := uint32(2166136261)
+		for _, b := range key {
+			hash ^= uint32(int8(b))
+			hash *= 16777619
+		}
+		return hash
+	},
+	"one_at_a_time": func(key []byte) uint32 {
+		var hash uint32
+		for _, b := range key {
+			hash += uint32(int8(b))
+			hash += hash << 10
+			hash ^= hash >> 6
+		}
+		hash += hash << 3
+		hash ^= hash >> 11
+		hash += hash << 15
+		return hash
+	},
+}
+
+// Twemproxy places keys on the servers of a twemproxy pool exactly as
+// twemproxy does, so that Go services can read and write a fleet sharded
+// by twemproxy without resharding it. Servers are assumed to be live:
+// twemproxy's auto_eject_hosts, which drops failing servers, is not
+// reproduced.
+type Twemproxy struct {
+	hash    func([]byte) uint32
+	hashTag string
+	// continuum is the ketama continuum, and servers the modula continuum:
+	// each server's address repeated by its weight.
+	continuum []KetamaPoint
+	servers   []string
+}
+
+// NewTwemproxy returns a Twemproxy placing keys as pool does. It returns
+// an error for the random distribution, which doesn't place keys
+// deterministically, and for the hsieh, murmur, crc16 and jenkins hashes,
+// which are not reproduced.
+func NewTwemproxy(pool TwemproxyPool) (*Twemproxy, error) {
+	hash, ok := twemproxyHashes[pool.Hash]
+	if !ok {
+		return nil, fmt.Errorf("rendezvous: unsupported twemproxy hash %q", pool.Hash)
+	}
+	if pool.HashTag != "" && len(pool.HashTag) != 2 {
+		return nil, fmt.Errorf("rendezvous: twemproxy hash_tag %q is not two characters", pool.HashTag)
+	}
+	t := &Twemproxy{hash: hash, hashTag: pool.HashTag}
+
+	switch pool.Distribution {
+	case "ketama":
+		total := 0
+		for _, server := range pool.Servers {
+			total += server.Weight
+		}
+		for _, server := range pool.Servers {
+			// twemproxy computes the number of points in single precision.
+			share := float32(server.Weight) / float32(total)
+			points := int(math.Floor(float64(float32(float64(share*160/4*float32(len(pool.Servers)))+0.0000000001)))) * 4
+			for k := range points / 4 {
+				digest := md5.Sum([]byte(server.Name + "-" + strconv.Itoa(k)))
+				for i := range 4 {
+					t.continuum = append(t.continuum, KetamaPoint{
+						Point:  binary.LittleEndian.Uint32(digest[i*4:]),
+						Server: server.Addr,
+					})
+				}
+			}
+		}
+		slices.SortFunc(t.continuum, func(a, b KetamaPoint) int {
+			return cmp.Or(cmp.Compare(a.Point, b.Point), cmp.Compare(a.Server, b.Server))
+		})
+	case "modula":
+		for _, server := range pool.Servers {
+			for range server.Weight {
+				t.servers = append(t.servers, server.Addr)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("rendezvous: unsupported twemproxy distribution %q", pool.Distribution)
+	}
+	return t, nil
+}
+
+// Get returns the address of the server twemproxy places key on, or "" if
+// the pool has no servers.
+func (t *Twemproxy) Get(key string) string {
+	hash := t.hash([]byte(t.hashedKey(key)))
+	if t.continuum == nil {
+		if len(t.servers) == 0 {
+			return ""
+		}
+		return t.servers[hash%uint32(len(t.servers))]
+	}
+	i := sort.Search(len(t.continuum), func(i int) bool { return t.continuum[i].Point >= hash })
+	return t.continuum[i%len(t.continuum)].Server
+}
+
+// hashedKey returns the part of key twemproxy hashes: the part between the
+// hash tag's delimiters, if key has a non-empty one, or else all of it.
+func (t *Twemproxy) hashedKey(key string) string {
+	if t.hashTag == "" {
+		return key
+	}
+	start := strings.IndexByte(key, t.hashTag[0])
+	if start < 0 {
+		return key
+	}
+	end := strings.IndexByte(key[start+1:], t.hashTag[1])
+	if end <= 0 {
+		return key
+	}
+	return key[start+1 : start+1+end]
+}
@@ -0,0 +1,118 @@
+package rendezvous
+
+import (
+	"reflect"
+	"testing"
+)
+
+const twemproxyConfig = `# memcached fleet
+alpha:
+  listen: 127.0.0.1:22121
+  hash: md5
+  hash_tag: "{}"
+  distribution: ketama
+  auto_eject_hosts: true
+  servers:
+   - 10.0.0.1:11212:1
+   - 10.0.0.2:11212:1
+   - 10.0.0.3:11212:1 cache-3
+
+beta:
+  distribution: modula
+  servers:
+   - 10.0.1.1:11211:2
+   - /var/run/memcached.sock:1
+`
+
+func TestParseTwemproxy(t *testing.T) {
+	pools, err := ParseTwemproxy([]byte(twemproxyConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []TwemproxyPool{
+		{Name: "alpha", Hash: "md5", HashTag: "{}", Distribution: "ketama", Servers: []TwemproxyServer{
+			{Addr: "10.0.0.1:11212", Weight: 1, Name: "10.0.0.1:11212"},
+			{Addr: "10.0.0.2:11212", Weight: 1, Name: "10.0.0.2:11212"},
+			{Addr: "10.0.0.3:11212", Weight: 1, Name: "cache-3"},
+		}},
+		{Name: "beta", Hash: "fnv1a_64", Distribution: "modula", Servers: []TwemproxyServer{
+			{Addr: "10.0.1.1:11211", Weight: 2, Name: "10.0.1.1"},
+			{Addr: "/var/run/memcached.sock", Weight: 1, Name: "/var/run/memcached.sock"},
+		}},
+	}
+	if !reflect.DeepEqual(pools, expected) {
+		t.Errorf("got: %+v, expected: %+v", pools, expected)
+	}
+	if nodes := pools[0].Nodes(); len(nodes) != 3 || nodes[2].ID != "cache-3" || nodes[2].Address != "10.0.0.3:11212" {
+		t.Errorf("got nodes %+v, expected cache-3 at 10.0.0.3:11212", nodes)
+	}
+
+	for _, config := range []string{"  hash: md5\n", "alpha:\n  - 10.0.0.1:11211:1\n", "alpha:\n  servers:\n   - 10.0.0.1:11211:x\n"} {
+		if _, err := ParseTwemproxy([]byte(config)); err == nil {
+			t.Errorf("config=%q - got no error", config)
+		}
+	}
+}
+
+func TestTwemproxyHashes(t *testing.T) {
+	for _, test := range []struct {
+		hash     string
+		expected uint32
+	}{
+		{"fnv1a_32", 0xa9f37ed7},
+		{"fnv1a_64", 0xfed9d577},
+		{"one_at_a_time", 0x238678dd},
+		{"crc32", 0x0c73},
+		{"crc32a", 0x8c736521},
+	} {
+		if got := twemproxyHashes[test.hash]([]byte("foo")); got != test.expected {
+			t.Errorf("hash=%s - got: %#x, expected: %#x", test.hash, got, test.expected)
+		}
+	}
+}
+
+func TestTwemproxy(t *testing.T) {
+	pools, _ := ParseTwemproxy([]byte(twemproxyConfig))
+
+	// With equal weights, twemproxy's md5 ketama continuum is libketama's.
+	alpha, err := NewTwemproxy(pools[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := newServers(server{"10.0.0.1", 11212}, server{"10.0.0.2", 11212}, server{"cache-3", 0})
+	continuum := hash.Ketama(func(s server) string {
+		if s.Port == 0 {
+			return s.Name
+		}
+		return serverAddr(s)
+	})
+	for _, key := range sampleKeys {
+		expected := KetamaGet(continuum, key)
+		if expected == "cache-3" {
+			expected = "10.0.0.3:11212"
+		}
+		if got := alpha.Get(key); got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+		if got, expected := alpha.Get("user:{"+key+"}:profile"), alpha.Get(key); key != "" && got != expected {
+			t.Errorf("key=%q - got: %v, expected the hash tag's server %v", key, got, expected)
+		}
+	}
+
+	beta, err := NewTwemproxy(pools[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range sampleKeys {
+		expected := []string{"10.0.1.1:11211", "10.0.1.1:11211", "/var/run/memcached.sock"}[twemproxyHashes["fnv1a_64"]([]byte(key))%3]
+		if got := beta.Get(key); got != expected {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+		}
+	}
+
+	for _, pool := range []TwemproxyPool{{Hash: "murmur", Distribution: "ketama"}, {Hash: "md5", Distribution: "random"}, {Hash: "md5", HashTag: "{", Distribution: "ketama"}} {
+		if _, err := NewTwemproxy(pool); err == nil {
+			t.Errorf("pool=%+v - got no error", pool)
+		}
+	}
+}
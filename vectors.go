@@ -0,0 +1,90 @@
+package rendezvous
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VectorSet is a set of test vectors recording how a Hash places keys, for
+// verifying that ports of the algorithm to other languages agree with this
+// package byte for byte. It encodes to JSON.
+type VectorSet struct {
+	// Hasher names the hasher: "CRC32C", "Hash128" or "SHA256".
+	Hasher string       `json:"hasher"`
+	Layout Layout       `json:"layout"`
+	Nodes  []VectorNode `json:"nodes"`
+	// Vectors holds a vector per key.
+	Vectors []Vector `json:"vectors"`
+}
+
+// VectorNode is a node of a VectorSet.
+type VectorNode struct {
+	ID     string  `json:"id"`
+	Weight float64 `json:"weight"`
+	// Effective is the node's weight once zone weights and canaries are
+	// taken into account, which is the weight its scores are computed with.
+	Effective float64 `json:"effective"`
+	Zone      string  `json:"zone,omitempty"`
+}
+
+// Vector is the placement of a key.
+type Vector struct {
+	Key string `json:"key"`
+	// Node is the ID of the node Get returns, or empty if there is none.
+	Node string `json:"node"`
+	// Ranking is the IDs of every node in the order GetN returns them.
+	Ranking []string `json:"ranking"`
+	// Raw is the raw hash of each node for the key, in the order of the
+	// set's Nodes, before any weighting: the part of a score a port must
+	// reproduce exactly, as weighted scores are floating point. Hashes are
+	// decimal strings, as JSON parsers that hold numbers in float64 would
+	// round 64-bit hashes.
+	Raw []string `json:"raw"`
+}
+
+// VectorKeys returns the canonical keys of test vectors: edge cases such as
+// the empty key, multi-byte characters and a long key, followed by "key-0"
+// to "key-99".
+func VectorKeys() []string {
+	keys := []string{"", "a", "hello", "ключ", "键", "🔑", "with space", "tab\tnewline\n", strings.Repeat("abc", 100)}
+	for i := range 100 {
+		keys = append(keys, fmt.Sprintf("key-%d", i))
+	}
+	return keys
+}
+
+// Vectors returns test vectors for keys placed by h. Keys and node
+// identities must be valid UTF-8 to survive the JSON encoding.
+func (h *Hash[N]) Vectors(keys []string) VectorSet {
+	set := VectorSet{Layout: h.layout, Nodes: make([]VectorNode, len(h.nodes))}
+	switch h.hasher {
+	case Hash128:
+		set.Hasher = "Hash128"
+	case SHA256:
+		set.Hasher = "SHA256"
+	default:
+		set.Hasher = "CRC32C"
+	}
+	for i, ns := range h.nodes {
+		set.Nodes[i] = VectorNode{ID: string(ns.id), Weight: ns.weight, Effective: ns.effective, Zone: ns.zone}
+	}
+
+	for _, key := range keys {
+		keyBytes := h.keyBytes(key)
+		vector := Vector{Key: key, Ranking: []string{}, Raw: make([]string, len(h.nodes))}
+		for _, node := range h.GetN(len(h.nodes), key) {
+			vector.Ranking = append(vector.Ranking, string(h.nodeID(node)))
+		}
+		if len(vector.Ranking) > 0 {
+			vector.Node = vector.Ranking[0]
+		}
+		h.scorer.begin(h.layout, keyBytes)
+		for i := range h.nodes {
+			raw, _ := h.scorer.next(h.nodes[i].id)
+			vector.Raw[i] = strconv.FormatUint(raw, 10)
+		}
+		set.Vectors = append(set.Vectors, vector)
+	}
+	return set
+}
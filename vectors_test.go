@@ -0,0 +1,49 @@
+package rendezvous
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestHashVectors(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	hash.SetWeight("b", 2)
+	keys := VectorKeys()
+	set := hash.Vectors(keys)
+
+	if set.Hasher != "CRC32C" || len(set.Nodes) != 3 || set.Nodes[1].Weight != 2 || len(set.Vectors) != len(keys) {
+		t.Fatalf("got %+v, expected a vector per key over a, b and c", set)
+	}
+	for _, vector := range set.Vectors {
+		node, _ := hash.Get(vector.Key)
+		ranking := hash.GetN(3, vector.Key)
+		if vector.Node != string(node) || !slices.Equal(vector.Ranking, []string{string(ranking[0]), string(ranking[1]), string(ranking[2])}) {
+			t.Errorf("key=%q - got: %s %v, expected: %s %v", vector.Key, vector.Node, vector.Ranking, node, ranking)
+		}
+	}
+
+	// Pinned so that a change of placement can't go unnoticed by ports.
+	hello := set.Vectors[slices.Index(keys, "hello")]
+	if hello.Node != "b" || hello.Raw[0] != "3446857959" {
+		t.Errorf("got %+v, expected the pinned vector for hello", hello)
+	}
+	if raw, _ := strconv.ParseUint(hello.Raw[2], 10, 64); raw != uint64(crc32.Checksum([]byte("helloc"), crc32Table)) {
+		t.Errorf("got raw %d, expected the CRC-32C of hello followed by c", raw)
+	}
+
+	encoded, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded VectorSet
+	if err := json.Unmarshal(encoded, &decoded); err != nil || len(decoded.Vectors) != len(keys) || decoded.Vectors[0].Key != "" {
+		t.Errorf("got %v, %v decoding the vectors", decoded, err)
+	}
+
+	if empty := New[hashableString]().Vectors([]string{"key"}); empty.Vectors[0].Node != "" || len(empty.Vectors[0].Ranking) != 0 {
+		t.Errorf("got %+v, expected no placement without nodes", empty)
+	}
+}
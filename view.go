@@ -0,0 +1,95 @@
+package rendezvous
+
+// View is a read-only subset of a Hash's nodes selected by a filter, such as
+// only nodes with SSDs. A View shares its parent's storage and follows its
+// membership changes, re-evaluating the filter only when the parent's epoch
+// changes. Lookups through a View rank the View's nodes by the scores they
+// have in the parent, so they place keys as a Hash holding only the View's
+// nodes would when weights are set per node. Effective weights derived from
+// zone weights or canaries are the parent's, computed over all its nodes,
+// so with those a View's placement can differ from such a Hash's.
+//
+// A View is not safe for concurrent use, nor for use concurrently with its
+// parent.
+type View[N any] struct {
+	parent  *Hash[N]
+	filter  func(N) bool
+	synced  bool
+	epoch   uint64
+	members []int
+}
+
+// View returns a View of the nodes in h for which filter returns true.
+func (h *Hash[N]) View(filter func(N) bool) *View[N] {
+	return &View[N]{parent: h, filter: filter}
+}
+
+// sync recomputes the View's members if the parent has changed since they
+// were last computed.
+func (v *View[N]) sync() {
+	if v.synced && v.epoch == v.parent.epoch {
+		return
+	}
+	v.members = v.members[:0]
+	for i, ns := range v.parent.nodes {
+		if v.filter(ns.node) {
+			v.members = append(v.members, i)
+		}
+	}
+	v.synced, v.epoch = true, v.parent.epoch
+}
+
+// Nodes returns the nodes in the View in canonical order.
+func (v *View[N]) Nodes() []N {
+	v.sync()
+	nodes := make([]N, len(v.members))
+	for i, member := range v.members {
+		nodes[i] = v.parent.nodes[member].node
+	}
+	return nodes
+}
+
+// Get returns the View node with the highest score for the given key.
+// If the View has no nodes, the zero value of type N is returned along with false.
+func (v *View[N]) Get(key string) (N, bool) {
+	v.sync()
+	if len(v.members) == 0 {
+		var zero N
+		return zero, false
+	}
+
+	h := v.parent
+	keyBytes := h.keyBytes(key)
+	maxIndex := v.members[0]
+	h.scorer.begin(h.layout, keyBytes)
+	maxScore := h.scoreWith(&h.scorer, &h.nodes[maxIndex])
+
+	for _, i := range v.members[1:] {
+		score := h.scoreWith(&h.scorer, &h.nodes[i])
+		if score > maxScore || (score == maxScore && h.compareTie(h.nodes[i].id, h.nodes[maxIndex].id) < 0) {
+			maxScore = score
+			maxIndex = i
+		}
+	}
+
+	return h.nodes[maxIndex].node, true
+}
+
+// GetN returns no more than n View nodes for the given key, ordered by
+// descending score.
+func (v *View[N]) GetN(n int, key string) []N {
+	v.sync()
+	if len(v.members) == 0 {
+		return nil
+	}
+
+	h := v.parent
+	h.rankOf(h.keyBytes(key), v.members)
+
+	n = min(n, len(h.order))
+	nodes := make([]N, n)
+	for i := range nodes {
+		nodes[i] = h.nodes[h.order[i]].node
+	}
+	return nodes
+}
@@ -0,0 +1,63 @@
+package rendezvous
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHashView(t *testing.T) {
+	hash := New[hashableString]("ssd-a", "hdd-b", "ssd-c", "hdd-d")
+	ssd := hash.View(func(node hashableString) bool {
+		return strings.HasPrefix(string(node), "ssd-")
+	})
+
+	assertMatches := func(nodes ...hashableString) {
+		t.Helper()
+		if got := ssd.Nodes(); !slices.Equal(got, nodes) {
+			t.Errorf("got nodes %v, expected %v", got, nodes)
+		}
+		expected := New(nodes...)
+		for _, key := range sampleKeys {
+			got, ok := ssd.Get(key)
+			node, expectedOk := expected.Get(key)
+			if got != node || ok != expectedOk {
+				t.Errorf("key=%q - got: (%v, %t), expected: (%v, %t)", key, got, ok, node, expectedOk)
+			}
+			if got, expected := ssd.GetN(3, key), expected.GetN(3, key); !reflect.DeepEqual(got, expected) {
+				t.Errorf("key=%q - got: %v, expected: %v", key, got, expected)
+			}
+		}
+	}
+
+	assertMatches("ssd-a", "ssd-c")
+
+	hash.Add("ssd-e", "hdd-f")
+	assertMatches("ssd-a", "ssd-c", "ssd-e")
+
+	hash.Remove("ssd-a")
+	hash.Remove("ssd-c")
+	hash.Remove("ssd-e")
+	assertMatches()
+}
+
+func TestHashViewZoneWeights(t *testing.T) {
+	nodes := []zonedNode{{"a", "east", 1}, {"b", "east", 1}, {"c", "west", 1}, {"d", "west", 2}}
+	hash := New(nodes...)
+	hash.SetZoneWeight("west", 3)
+	east := func(node zonedNode) bool { return node.id != "d" }
+	view := hash.View(east)
+
+	// With zone weights, a View ranks its nodes by their scores in the
+	// parent, whose effective weights count every node of each zone.
+	for _, key := range sampleKeys {
+		expected := slices.DeleteFunc(hash.GetN(len(nodes), key), func(node zonedNode) bool { return !east(node) })
+		if got := view.GetN(len(nodes), key); !reflect.DeepEqual(got, expected) {
+			t.Errorf("key=%q - got: %v, expected the parent's ranking %v", key, got, expected)
+		}
+		if got, _ := view.Get(key); got != expected[0] {
+			t.Errorf("key=%q - got: %v, expected: %v", key, got, expected[0])
+		}
+	}
+}
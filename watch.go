@@ -0,0 +1,128 @@
+package rendezvous
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"sync"
+	"time"
+)
+
+// Watcher keeps a Hash's membership in sync with a node configuration file,
+// so deployments can change membership without restarting. It polls the
+// file, and whenever its contents change, parses the nodes it lists and
+// applies the difference to the Hash as a single atomic change.
+//
+// Polling, rather than file system notifications, keeps the module free of
+// dependencies outside the standard library and works on every platform
+// and file system, including network mounts and the symlink swaps of
+// Kubernetes ConfigMaps, where notifications are unreliable. The cost is
+// latency: a change is applied up to one polling interval after it is
+// written, and each poll stats the file. A caller that needs changes
+// applied immediately can call Reload from its own notification loop.
+type Watcher[N any] struct {
+	// Locker, if set, is held while the Hash is read and modified, for
+	// Hashes shared with other goroutines.
+	Locker sync.Locker
+	// OnError, if set, is called with errors encountered by Run.
+	OnError func(error)
+
+	hash  *Hash[N]
+	path  string
+	parse func([]byte) ([]N, map[string]float64, error)
+
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
+	loaded  bool
+}
+
+// NewWatcher returns a Watcher that syncs hash with the file at path, whose
+// contents parse turns into the complete list of nodes. Zone weights are
+// left as they are; NewConfigWatcher follows those of a configuration file
+// too.
+func NewWatcher[N any](hash *Hash[N], path string, parse func([]byte) ([]N, error)) *Watcher[N] {
+	return &Watcher[N]{hash: hash, path: path, parse: func(data []byte) ([]N, map[string]float64, error) {
+		nodes, err := parse(data)
+		return nodes, nil, err
+	}}
+}
+
+// NewConfigWatcher returns a Watcher that syncs hash with the JSON
+// configuration file at path, as parsed by ParseConfig: both its nodes and
+// its zone weights. A zone dropped from the file keeps its last weight.
+func NewConfigWatcher(hash *Hash[ConfigNode], path string) *Watcher[ConfigNode] {
+	return &Watcher[ConfigNode]{hash: hash, path: path, parse: func(data []byte) ([]ConfigNode, map[string]float64, error) {
+		config, err := ParseConfig(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return config.Nodes, config.ZoneWeights, nil
+	}}
+}
+
+// Reload checks the file once and, if it changed since the last successful
+// reload, applies its nodes to the Hash. It reports whether the Hash was
+// modified. A file that can't be read or parsed leaves the Hash unchanged.
+func (w *Watcher[N]) Reload() (bool, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false, err
+	}
+	if w.loaded && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, err
+	}
+	sum := sha256.Sum256(data)
+	if w.loaded && bytes.Equal(sum[:], w.sum[:]) {
+		w.modTime, w.size = info.ModTime(), info.Size()
+		return false, nil
+	}
+	nodes, zoneWeights, err := w.parse(data)
+	if err != nil {
+		return false, err
+	}
+
+	if w.Locker != nil {
+		w.Locker.Lock()
+		defer w.Locker.Unlock()
+	}
+	epoch := w.hash.Epoch()
+	changes := w.hash.Diff(nodes)
+	changes.Actor = "watcher:" + w.path
+	if err := w.hash.Apply(changes); err != nil {
+		return false, err
+	}
+	current := w.hash.ZoneWeights()
+	for zone, weight := range zoneWeights {
+		if existing, ok := current[zone]; !ok || existing != weight {
+			w.hash.SetZoneWeight(zone, weight)
+		}
+	}
+	w.modTime, w.size, w.sum, w.loaded = info.ModTime(), info.Size(), sum, true
+	return w.hash.Epoch() != epoch, nil
+}
+
+// Run reloads the file immediately and then every interval until ctx is
+// done, reporting errors to OnError. It returns ctx's error. A poll that
+// finds the file unchanged costs a single stat, so an interval of a second
+// or so suits most deployments.
+func (w *Watcher[N]) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Reload(); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package rendezvous
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes")
+	write := func(contents string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parse := func(data []byte) ([]hashableString, error) {
+		if strings.Contains(string(data), "!") {
+			return nil, errors.New("invalid node")
+		}
+		var nodes []hashableString
+		for _, field := range strings.Fields(string(data)) {
+			nodes = append(nodes, hashableString(field))
+		}
+		return nodes, nil
+	}
+
+	log := NewMemoryAuditLog(0)
+	hash := NewWithOptions([]hashableString{"a", "z"}, WithAuditLog(log))
+	watcher := NewWatcher(hash, path, parse)
+	start := time.Unix(1000, 0)
+
+	write("a b c", start)
+	if changed, err := watcher.Reload(); err != nil || !changed {
+		t.Fatalf("got: (%t, %v), expected a change", changed, err)
+	}
+	if got, expected := hash.Nodes(), []hashableString{"a", "b", "c"}; !slices.Equal(got, expected) {
+		t.Errorf("got: %v, expected: %v", got, expected)
+	}
+	history := log.History()
+	if last := history[len(history)-1]; !slices.Equal(last.Added, []string{"b", "c"}) || !slices.Equal(last.Removed, []string{"z"}) {
+		t.Errorf("got change %+v, expected b and c added and z removed at once", last)
+	}
+
+	epoch := hash.Epoch()
+	write("a b c", start.Add(time.Second))
+	if changed, err := watcher.Reload(); err != nil || changed || hash.Epoch() != epoch {
+		t.Errorf("got: (%t, %v), expected no change for identical contents", changed, err)
+	}
+
+	write("a b !", start.Add(2*time.Second))
+	if _, err := watcher.Reload(); err == nil || hash.Epoch() != epoch {
+		t.Errorf("got error %v, expected an invalid file to be rejected", err)
+	}
+
+	write("b c d", start.Add(3*time.Second))
+	if changed, err := watcher.Reload(); err != nil || !changed {
+		t.Fatalf("got: (%t, %v), expected a change", changed, err)
+	}
+	if got, expected := hash.Nodes(), []hashableString{"b", "c", "d"}; !slices.Equal(got, expected) {
+		t.Errorf("got: %v, expected: %v", got, expected)
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.json")
+	write := func(contents string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash := New[ConfigNode]()
+	watcher := NewConfigWatcher(hash, path)
+	start := time.Unix(1000, 0)
+
+	first := `{"nodes": [{"id": "a", "zone": "us"}, {"id": "b", "zone": "eu"}], "zoneWeights": {"eu": 2}}`
+	write(first, start)
+	if changed, err := watcher.Reload(); err != nil || !changed {
+		t.Fatalf("got: (%t, %v), expected a change", changed, err)
+	}
+	expected, _ := ParseConfig([]byte(first))
+	if !hash.Equal(NewFromConfig(expected)) {
+		t.Errorf("got %s, expected the file's nodes and zone weights", hash.Dump())
+	}
+
+	// Editing only a zone weight is a change.
+	write(`{"nodes": [{"id": "a", "zone": "us"}, {"id": "b", "zone": "eu"}], "zoneWeights": {"eu": 3}}`, start.Add(time.Second))
+	if changed, err := watcher.Reload(); err != nil || !changed || hash.ZoneWeights()["eu"] != 3 {
+		t.Errorf("got: (%t, %v) with zone weights %v, expected eu=3", changed, err, hash.ZoneWeights())
+	}
+}
+
+func TestHashDiff(t *testing.T) {
+	hash := New(zonedNode{"a", "us", 1}, zonedNode{"b", "us", 1}, zonedNode{"c", "us", 1})
+	desired := []zonedNode{{"a", "us", 1}, {"b", "eu", 1}, {"d", "us", 2}}
+	if err := hash.Apply(hash.Diff(desired)); err != nil {
+		t.Fatal(err)
+	}
+	if !hash.Equal(New(desired...)) {
+		t.Errorf("got %v, expected the desired membership %v", hash.Nodes(), desired)
+	}
+	if changes := hash.Diff(desired); len(changes.Add) != 0 || len(changes.Remove) != 0 {
+		t.Errorf("got changes %+v, expected none once in sync", changes)
+	}
+}
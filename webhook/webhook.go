@@ -0,0 +1,209 @@
+// Package webhook notifies external systems, such as chat alerts,
+// inventories and cache warmers, of the membership changes of a
+// rendezvous.Hash, by POSTing a JSON Diff of each change to webhook URLs.
+package webhook
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+// Diff describes how a Hash's membership changed between two epochs.
+// Nodes are identified by their identity bytes, as strings.
+type Diff struct {
+	Epoch         uint64    `json:"epoch"`
+	PreviousEpoch uint64    `json:"previous_epoch"`
+	Time          time.Time `json:"time"`
+	Added         []Node    `json:"added,omitempty"`
+	Removed       []Node    `json:"removed,omitempty"`
+	// Reweighted lists nodes whose weight changed.
+	Reweighted []Reweight `json:"reweighted,omitempty"`
+	// Churn is the estimated fraction of keys whose owner changed: half the
+	// sum of the changes in each node's expected share of keys, which
+	// accounts for zone weights and canaries too.
+	Churn float64 `json:"churn"`
+}
+
+// Node is a node added or removed.
+type Node struct {
+	ID     string  `json:"id"`
+	Weight float64 `json:"weight"`
+}
+
+// Reweight is a node whose weight changed.
+type Reweight struct {
+	ID   string  `json:"id"`
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// nodeState is what the Notifier remembers of a node between checks.
+type nodeState struct {
+	weight, share float64
+}
+
+// Notifier POSTs a Diff to each of its URLs whenever the membership of a
+// Hash changes. It notices changes by checking the Hash's epoch, so several
+// changes between checks are reported as one Diff.
+type Notifier[N any] struct {
+	// Client sends the notifications. It defaults to http.DefaultClient.
+	Client *http.Client
+	// Header holds extra headers to send, such as Authorization.
+	Header http.Header
+	// Locker, if set, is held while the Hash is read.
+	Locker sync.Locker
+	// OnError, if set, is called with each error notifying url.
+	OnError func(url string, err error)
+
+	hash *rendezvous.Hash[N]
+	urls []string
+	now  func() time.Time
+
+	mu    sync.Mutex
+	epoch uint64
+	nodes map[string]nodeState
+}
+
+// NewNotifier returns a Notifier of the changes to hash after the call,
+// POSTing to urls. It reads hash, so hold any lock the Hash is shared under.
+func NewNotifier[N any](hash *rendezvous.Hash[N], urls ...string) *Notifier[N] {
+	n := &Notifier[N]{hash: hash, urls: urls, now: time.Now}
+	n.epoch, n.nodes = n.state()
+	return n
+}
+
+// state returns the Hash's epoch and the state of its nodes.
+func (n *Notifier[N]) state() (uint64, map[string]nodeState) {
+	// Only expected shares are used, which don't depend on sampling, so a
+	// single sample suffices.
+	shares := n.hash.SampleShares(1)
+	nodes := make(map[string]nodeState, len(shares))
+	for _, share := range shares {
+		weight, _ := n.hash.Weight(share.Node)
+		nodes[string(n.hash.Identity(share.Node))] = nodeState{weight: weight, share: share.Expected}
+	}
+	return n.hash.Epoch(), nodes
+}
+
+// Check compares the Hash with its state at the last Check and, if its
+// membership changed, POSTs the Diff to every URL. It returns the Diff, or
+// nil if nothing changed, and the errors notifying URLs. A failed
+// notification is not retried.
+func (n *Notifier[N]) Check(ctx context.Context) (*Diff, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.Locker != nil {
+		n.Locker.Lock()
+	}
+	epoch := n.hash.Epoch()
+	var nodes map[string]nodeState
+	if epoch != n.epoch {
+		epoch, nodes = n.state()
+	}
+	if n.Locker != nil {
+		n.Locker.Unlock()
+	}
+	if nodes == nil {
+		return nil, nil
+	}
+
+	diff := n.diff(epoch, nodes)
+	n.epoch, n.nodes = epoch, nodes
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Reweighted) == 0 && diff.Churn == 0 {
+		// The epoch advanced with no effect, such as by an empty changeset.
+		return nil, nil
+	}
+
+	body, err := json.Marshal(diff)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, url := range n.urls {
+		if err := n.post(ctx, url, body); err != nil {
+			if n.OnError != nil {
+				n.OnError(url, err)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return diff, errors.Join(errs...)
+}
+
+// diff returns the Diff from the last Check to nodes at epoch.
+func (n *Notifier[N]) diff(epoch uint64, nodes map[string]nodeState) *Diff {
+	diff := &Diff{Epoch: epoch, PreviousEpoch: n.epoch, Time: n.now()}
+	var moved float64
+	for id, state := range nodes {
+		previous, ok := n.nodes[id]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, Node{ID: id, Weight: state.weight})
+		case previous.weight != state.weight:
+			diff.Reweighted = append(diff.Reweighted, Reweight{ID: id, From: previous.weight, To: state.weight})
+		}
+		moved += max(state.share-previous.share, 0)
+	}
+	for id, previous := range n.nodes {
+		if _, ok := nodes[id]; !ok {
+			diff.Removed = append(diff.Removed, Node{ID: id, Weight: previous.weight})
+		}
+	}
+	diff.Churn = moved
+
+	slices.SortFunc(diff.Added, func(a, b Node) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(diff.Removed, func(a, b Node) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(diff.Reweighted, func(a, b Reweight) int { return cmp.Compare(a.ID, b.ID) })
+	return diff
+}
+
+// post POSTs body to url.
+func (n *Notifier[N]) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range n.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// Run calls Check every interval until ctx is done, and returns ctx's
+// error. Set OnError to learn of errors.
+func (n *Notifier[N]) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			n.Check(ctx)
+		}
+	}
+}
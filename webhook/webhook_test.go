@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/beam-cloud/rendezvous"
+)
+
+func TestNotifier(t *testing.T) {
+	var diffs []Diff
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("got %s with Authorization %q", r.Method, r.Header.Get("Authorization"))
+		}
+		var diff Diff
+		if err := json.NewDecoder(r.Body).Decode(&diff); err != nil {
+			t.Error(err)
+		}
+		diffs = append(diffs, diff)
+	}))
+	defer server.Close()
+
+	a, b, c := rendezvous.NewConfigNode("a", 1, ""), rendezvous.NewConfigNode("b", 1, ""), rendezvous.NewConfigNode("c", 2, "")
+	hash := rendezvous.New(a, b)
+	notifier := NewNotifier(hash, server.URL)
+	notifier.Header = http.Header{"Authorization": {"Bearer secret"}}
+
+	if diff, err := notifier.Check(context.Background()); diff != nil || err != nil || len(diffs) != 0 {
+		t.Fatalf("got diff %+v and error %v, expected no notification without a change", diff, err)
+	}
+
+	// a and b each held half the keys; c takes half, so half of them move.
+	hash.Add(c)
+	hash.SetWeight(a, 2)
+	hash.Remove(b)
+	diff, err := notifier.Check(context.Background())
+	if err != nil || len(diffs) != 1 {
+		t.Fatalf("got %d notifications and error %v, expected one", len(diffs), err)
+	}
+	got := diffs[0]
+	if got.Epoch != hash.Epoch() || got.PreviousEpoch != 1 || got.Epoch != diff.Epoch {
+		t.Errorf("got epochs %d and %d, expected %d and 1", got.Epoch, got.PreviousEpoch, hash.Epoch())
+	}
+	if len(got.Added) != 1 || got.Added[0] != (Node{ID: "c", Weight: 2}) ||
+		len(got.Removed) != 1 || got.Removed[0] != (Node{ID: "b", Weight: 1}) ||
+		len(got.Reweighted) != 1 || got.Reweighted[0] != (Reweight{ID: "a", From: 1, To: 2}) {
+		t.Errorf("got %+v, expected c added, b removed and a reweighted", got)
+	}
+	if math.Abs(got.Churn-0.5) > 1e-9 {
+		t.Errorf("got churn %v, expected: 0.5", got.Churn)
+	}
+
+	notifier.urls = append(notifier.urls, server.URL+"/missing")
+	var failed int
+	notifier.OnError = func(string, error) { failed++ }
+	hash.Remove(c)
+	if _, err := notifier.Check(context.Background()); err == nil || failed != 1 || len(diffs) != 2 {
+		t.Errorf("got error %v, %d failures and %d notifications, expected one failure", err, failed, len(diffs))
+	}
+}
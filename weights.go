@@ -0,0 +1,199 @@
+package rendezvous
+
+import (
+	"maps"
+	"math"
+
+	"github.com/beam-cloud/rendezvous/score"
+)
+
+// Weighted may be implemented by a node type to give nodes an initial
+// weight when they are added. Nodes that don't implement it start with a
+// weight of 1. SetWeight overrides the initial weight.
+type Weighted interface {
+	Weight() float64
+}
+
+// Zoned may be implemented by a node type to place nodes in a failure
+// domain, such as a region or availability zone, for zone-level weighting.
+type Zoned interface {
+	Zone() string
+}
+
+// Weight returns the weight of node, and false if node is not in the Hash.
+func (h *Hash[N]) Weight(node N) (float64, bool) {
+	i := h.find(h.nodeID(node))
+	if i < 0 {
+		return 0, false
+	}
+	return h.nodes[i].weight, true
+}
+
+// SetWeight sets the weight of every node whose identity matches node's,
+// and reports whether any node matched. A node's expected share of keys is
+// proportional to its weight. Nodes with a weight of zero or less are only
+// selected when no node has a positive weight. A NaN or infinite weight is
+// not set, and SetWeight reports false.
+func (h *Hash[N]) SetWeight(node N, weight float64) bool {
+	return h.Apply(Changeset[N]{Weights: []WeightChange[N]{{Node: node, Weight: weight}}}) == nil
+}
+
+// SetZoneWeight sets the weight of a zone, as reported by nodes implementing
+// Zoned. Once any zone weight is set, each zone's expected share of keys is
+// proportional to its zone weight regardless of how many nodes it holds, and
+// node weights only divide a zone's share among its nodes. Zones without an
+// explicit weight, including the empty zone of nodes that don't implement
+// Zoned, have a weight of 1.
+//
+// Because a zone's share is split among its nodes, adding or removing a node
+// also shifts a small number of keys between the zone's other nodes and the
+// rest of the Hash.
+func (h *Hash[N]) SetZoneWeight(zone string, weight float64) {
+	if h.zoneWeights == nil {
+		h.zoneWeights = make(map[string]float64)
+	}
+	h.zoneWeights[zone] = weight
+	h.reweigh()
+
+	var reweighted []N
+	for _, ns := range h.nodes {
+		if ns.zone == zone {
+			reweighted = append(reweighted, ns.node)
+		}
+	}
+	h.commit("", nil, nil, reweighted)
+}
+
+// ZoneWeights returns a copy of the zone weights set with SetZoneWeight.
+func (h *Hash[N]) ZoneWeights() map[string]float64 {
+	return maps.Clone(h.zoneWeights)
+}
+
+// zoneWeight returns the weight of zone.
+func (h *Hash[N]) zoneWeight(zone string) float64 {
+	if weight, ok := h.zoneWeights[zone]; ok {
+		return weight
+	}
+	return 1
+}
+
+// initialWeight returns the weight a node starts with when added.
+func initialWeight[N any](node N) float64 {
+	if weighted, ok := any(node).(Weighted); ok {
+		return weighted.Weight()
+	}
+	return 1
+}
+
+// nodeZone returns the zone of node, or the empty zone if it has none.
+func nodeZone[N any](node N) string {
+	if zoned, ok := any(node).(Zoned); ok {
+		return zoned.Zone()
+	}
+	return ""
+}
+
+// usableWeight returns weight, or 0 if it is negative, NaN or infinite, so
+// that a node or zone with an invalid weight is drained rather than winning
+// every key.
+func usableWeight(weight float64) float64 {
+	if !(weight >= 0) || math.IsInf(weight, 1) {
+		return 0
+	}
+	return weight
+}
+
+// reweigh recomputes every node's effective weight after a change to
+// membership, node weights, zone weights or canaries. If the scores of nodes
+// that were already present change as a result, the weight generation is
+// advanced so that incremental consumers such as Table know to recompute
+// from scratch.
+func (h *Hash[N]) reweigh() {
+	var zoneTotals map[string]float64
+	if len(h.zoneWeights) > 0 {
+		zoneTotals = make(map[string]float64)
+		for _, ns := range h.nodes {
+			if _, canary := h.canaries[string(ns.id)]; !canary {
+				zoneTotals[ns.zone] += usableWeight(ns.weight)
+			}
+		}
+	}
+
+	// Canaries own a fixed fraction of keys, so their effective weights are
+	// derived from the total effective weight of the other nodes: canaries
+	// with fractions summing to F alongside other nodes of total weight T
+	// own fraction/(1-F) of T each.
+	var total, canaryTotal float64
+	effective := make([]float64, len(h.nodes))
+	for i := range h.nodes {
+		ns := &h.nodes[i]
+		if fraction, canary := h.canaries[string(ns.id)]; canary {
+			canaryTotal += fraction
+			continue
+		}
+		effective[i] = usableWeight(ns.weight)
+		if zoneTotals != nil && effective[i] > 0 {
+			effective[i] = usableWeight(h.zoneWeight(ns.zone)) * effective[i] / zoneTotals[ns.zone]
+		}
+		total += effective[i]
+	}
+	if len(h.canaries) > 0 {
+		for i := range h.nodes {
+			if fraction, canary := h.canaries[string(h.nodes[i].id)]; canary {
+				effective[i] = fraction * total / (1 - canaryTotal)
+			}
+		}
+	}
+
+	// A ScoreFunc takes weights into account itself, so a Hash with one is
+	// never treated as uniform.
+	uniform := h.scoreFunc == nil
+	changed := false
+	for i := range h.nodes {
+		ns := &h.nodes[i]
+		if ns.effective >= 0 && ns.effective != effective[i] {
+			changed = true
+		}
+		ns.effective = effective[i]
+		if h.scoreFunc != nil {
+			ns.digest, _ = h.scorer.digest.sum(ns.id, nil)
+		}
+		if effective[i] != effective[0] {
+			uniform = false
+		}
+	}
+
+	if uniform != h.uniform || (!uniform && changed) {
+		h.weightGen++
+	}
+	h.uniform = uniform
+}
+
+// score returns the score of ns for key. When every node has the same
+// effective weight, the raw hash is the score, so unweighted placement is
+// unaffected by weighting. Otherwise scores follow the logarithmic method,
+// under which a node wins a key with probability proportional to its weight.
+func (h *Hash[N]) score(ns *nodeScore[N], key []byte) float64 {
+	h.scorer.begin(h.layout, key)
+	return h.scoreWith(&h.scorer, ns)
+}
+
+// scoreWith is score for the key last passed to s.begin, using scorer s.
+// Loops scoring many nodes for a key call begin once, then scoreWith for
+// each node.
+func (h *Hash[N]) scoreWith(s *scorer, ns *nodeScore[N]) float64 {
+	if !h.uniform && h.scoreFunc != nil {
+		return h.customScore(s, ns)
+	}
+	raw, _ := s.next(ns.id)
+	bits := s.digest.bits()
+	if bits > 53 {
+		// Keep raw exactly representable as a float64.
+		raw >>= bits - 53
+		bits = 53
+	}
+	if h.uniform && !h.logScores {
+		return float64(raw)
+	}
+	return score.Weighted(raw, bits, ns.effective)
+}
@@ -0,0 +1,149 @@
+package rendezvous
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// zonedNode implements Weighted and Zoned for testing purposes.
+type zonedNode struct {
+	id     string
+	zone   string
+	weight float64
+}
+
+func (n zonedNode) Bytes() []byte {
+	return []byte(n.id)
+}
+
+func (n zonedNode) Zone() string {
+	return n.zone
+}
+
+func (n zonedNode) Weight() float64 {
+	return n.weight
+}
+
+func TestHashSetWeight(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c", "d")
+	before := make(map[string]hashableString)
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before[key], _ = hash.Get(key)
+	}
+
+	if !hash.SetWeight("c", 3) {
+		t.Fatalf("SetWeight did not find node c")
+	}
+	if hash.SetWeight("missing", 3) {
+		t.Errorf("SetWeight found a missing node")
+	}
+	if weight, ok := hash.Weight("c"); !ok || weight != 3 {
+		t.Errorf("got: (%v, %t), expected: (3, true)", weight, ok)
+	}
+
+	// Raising a node's weight must only move keys onto it.
+	moved := 0
+	for key, old := range before {
+		current, _ := hash.Get(key)
+		if current != old {
+			moved++
+			if current != "c" {
+				t.Errorf("key=%q moved from %v to %v, expected only moves to c", key, old, current)
+			}
+		}
+	}
+	if moved == 0 {
+		t.Errorf("raising the weight of c moved no keys")
+	}
+}
+
+func TestHashInvalidWeights(t *testing.T) {
+	hash := New[hashableString]("a", "b", "c")
+	epoch := hash.Epoch()
+	for _, weight := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if hash.SetWeight("a", weight) {
+			t.Errorf("weight=%v - got: true, expected SetWeight to refuse it", weight)
+		}
+		if err := hash.Apply(Changeset[hashableString]{Weights: []WeightChange[hashableString]{{Node: "a", Weight: weight}}}); err == nil {
+			t.Errorf("weight=%v - got: nil, expected an error", weight)
+		}
+	}
+	if weight, _ := hash.Weight("a"); weight != 1 || hash.Epoch() != epoch {
+		t.Errorf("got weight %v at epoch %d, expected 1 at %d", weight, hash.Epoch(), epoch)
+	}
+
+	// Nodes that bring their own invalid weight are drained.
+	weighted := New(zonedNode{"a", "", math.NaN()}, zonedNode{"b", "", math.Inf(1)}, zonedNode{"c", "", 1})
+	for _, key := range sampleKeys {
+		if got, _ := weighted.Get(key); got.id != "c" {
+			t.Errorf("key=%q - got: %v, expected: c", key, got.id)
+		}
+	}
+}
+
+func TestHashZoneWeights(t *testing.T) {
+	nodes := []zonedNode{
+		{"us-east-1a/node-1", "us", 1},
+		{"eu-west-1b/node-2", "eu", 1},
+		{"eu-west-1c/node-3", "eu", 1},
+		{"eu-west-1c/node-44", "eu", 2},
+	}
+	hash := New(nodes...)
+	hash.SetZoneWeight("us", 3)
+
+	shares := map[string]float64{}
+	for _, share := range hash.SampleShares(20000) {
+		shares[share.Node.zone] += share.Fraction
+	}
+	if math.Abs(shares["us"]-0.75) > 0.03 || math.Abs(shares["eu"]-0.25) > 0.03 {
+		t.Errorf("got zone shares %v, expected us=0.75, eu=0.25", shares)
+	}
+
+	expected := map[string]float64{
+		"us-east-1a/node-1":  0.75,
+		"eu-west-1b/node-2":  0.0625,
+		"eu-west-1c/node-3":  0.0625,
+		"eu-west-1c/node-44": 0.125,
+	}
+	for _, share := range hash.SampleShares(1) {
+		if math.Abs(share.Expected-expected[share.Node.id]) > 1e-9 {
+			t.Errorf("node=%v - got expected share %v, expected %v", share.Node.id, share.Expected, expected[share.Node.id])
+		}
+	}
+
+	hash.SetZoneWeight("eu", 0)
+	if err := hash.CheckConsistency(); err != nil {
+		t.Errorf("got: %v, expected a drained zone to pass", err)
+	}
+	hash.SetZoneWeight("eu", -1)
+	if err := hash.CheckConsistency(); err == nil {
+		t.Errorf("got: nil, expected a negative zone weight error")
+	}
+}
+
+func TestTableRebuildsOnReweigh(t *testing.T) {
+	// Adding a node of another weight changes how every node is scored, so
+	// the new node can't be compared against the owners' stored scores.
+	table := NewTable(64, zonedNode{"a", "x", 1}, zonedNode{"b", "y", 1})
+	before := make([]zonedNode, table.Partitions())
+	for p := range before {
+		before[p], _ = table.Owner(p)
+	}
+	moved := make(map[int]Move[zonedNode])
+	for _, move := range table.Add(zonedNode{"c", "x", 2}) {
+		moved[move.Partition] = move
+	}
+
+	expected := NewTable(64, zonedNode{"a", "x", 1}, zonedNode{"b", "y", 1}, zonedNode{"c", "x", 2})
+	for p := range table.Partitions() {
+		got, _ := table.Owner(p)
+		if owner, _ := expected.Owner(p); got != owner {
+			t.Errorf("partition=%d - got: %v, expected: %v", p, got, owner)
+		}
+		if move, ok := moved[p]; ok != (got != before[p]) || ok && (move.From != before[p] || move.To != got) {
+			t.Errorf("partition=%d - got move %+v, expected a move only from %v to %v", p, move, before[p], got)
+		}
+	}
+}